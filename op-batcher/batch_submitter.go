@@ -10,15 +10,14 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-batcher/db"
 	"github.com/ethereum-optimism/optimism/op-batcher/sequencer"
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	proposer "github.com/ethereum-optimism/optimism/op-proposer"
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
 	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
-	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	"github.com/urfave/cli"
 )
 
@@ -102,16 +101,14 @@ func NewBatchSubmitter(
 
 	// Parse wallet private key that will be used to submit L2 txs to the batch
 	// inbox address.
-	wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
-	if err != nil {
-		return nil, err
+	keyCfg := &signer.KeyConfig{
+		KeystorePath:   cfg.KeystorePath,
+		PasswordFile:   cfg.PasswordFile,
+		PrivateKeyFile: cfg.PrivateKeyFile,
+		Mnemonic:       cfg.Mnemonic,
+		HDPath:         cfg.SequencerHDPath,
 	}
-
-	sequencerPrivKey, err := wallet.PrivateKey(accounts.Account{
-		URL: accounts.URL{
-			Path: cfg.SequencerHDPath,
-		},
-	})
+	sequencerPrivKey, err := keyCfg.LoadKey()
 	if err != nil {
 		return nil, err
 	}
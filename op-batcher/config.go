@@ -43,15 +43,6 @@ type Config struct {
 	// transaction.
 	ResubmissionTimeout time.Duration
 
-	// Mnemonic is the HD seed used to derive the wallet private keys for both
-	// the sequence and proposer. Must be used in conjunction with
-	// SequencerHDPath and ProposerHDPath.
-	Mnemonic string
-
-	// SequencerHDPath is the derivation path used to obtain the private key for
-	// batched submission of sequencer transactions.
-	SequencerHDPath string
-
 	// SequencerHistoryDBFilename is the filename of the database used to track
 	// the latest L2 sequencer batches that were published.
 	SequencerHistoryDBFilename string
@@ -65,6 +56,26 @@ type Config struct {
 
 	/* Optional Params */
 
+	// KeystorePath is the path of the encrypted keystore with the private key
+	// to sign transactions with. Must be used in conjunction with
+	// PasswordFile.
+	KeystorePath string
+
+	// PasswordFile is the path of the password to decrypt the keystore with.
+	PasswordFile string
+
+	// PrivateKeyFile is the path of the unencrypted hex-encoded private key to
+	// sign transactions with.
+	PrivateKeyFile string
+
+	// Mnemonic is the HD seed used to derive the wallet private key, for
+	// development only. Must be used in conjunction with SequencerHDPath.
+	Mnemonic string
+
+	// SequencerHDPath is the derivation path used to obtain the private key for
+	// batched submission of sequencer transactions.
+	SequencerHDPath string
+
 	// LogLevel is the lowest log level that will be output.
 	LogLevel string
 
@@ -86,13 +97,16 @@ func NewConfig(ctx *cli.Context) Config {
 		NumConfirmations:           ctx.GlobalUint64(flags.NumConfirmationsFlag.Name),
 		SafeAbortNonceTooLowCount:  ctx.GlobalUint64(flags.SafeAbortNonceTooLowCountFlag.Name),
 		ResubmissionTimeout:        ctx.GlobalDuration(flags.ResubmissionTimeoutFlag.Name),
-		Mnemonic:                   ctx.GlobalString(flags.MnemonicFlag.Name),
-		SequencerHDPath:            ctx.GlobalString(flags.SequencerHDPathFlag.Name),
 		SequencerHistoryDBFilename: ctx.GlobalString(flags.SequencerHistoryDBFilenameFlag.Name),
 		SequencerGenesisHash:       ctx.GlobalString(flags.SequencerGenesisHashFlag.Name),
 		SequencerBatchInboxAddress: ctx.GlobalString(flags.SequencerBatchInboxAddressFlag.Name),
 		/* Optional Flags */
-		LogLevel:    ctx.GlobalString(flags.LogLevelFlag.Name),
-		LogTerminal: ctx.GlobalBool(flags.LogTerminalFlag.Name),
+		KeystorePath:    ctx.GlobalString(flags.KeystoreFlag.Name),
+		PasswordFile:    ctx.GlobalString(flags.PasswordFileFlag.Name),
+		PrivateKeyFile:  ctx.GlobalString(flags.PrivateKeyFileFlag.Name),
+		Mnemonic:        ctx.GlobalString(flags.MnemonicFlag.Name),
+		SequencerHDPath: ctx.GlobalString(flags.SequencerHDPathFlag.Name),
+		LogLevel:        ctx.GlobalString(flags.LogLevelFlag.Name),
+		LogTerminal:     ctx.GlobalBool(flags.LogTerminalFlag.Name),
 	}
 }
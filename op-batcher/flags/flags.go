@@ -72,20 +72,6 @@ var (
 		Required: true,
 		EnvVar:   prefixEnvVar("RESUBMISSION_TIMEOUT"),
 	}
-	MnemonicFlag = cli.StringFlag{
-		Name: "mnemonic",
-		Usage: "The mnemonic used to derive the wallets for either the " +
-			"sequencer or the l2output",
-		Required: true,
-		EnvVar:   prefixEnvVar("MNEMONIC"),
-	}
-	SequencerHDPathFlag = cli.StringFlag{
-		Name: "sequencer-hd-path",
-		Usage: "The HD path used to derive the sequencer wallet from the " +
-			"mnemonic. The mnemonic flag must also be set.",
-		Required: true,
-		EnvVar:   prefixEnvVar("SEQUENCER_HD_PATH"),
-	}
 	SequencerHistoryDBFilenameFlag = cli.StringFlag{
 		Name: "sequencer-history-db-filename",
 		Usage: "File name used to identify the latest L2 batches submitted " +
@@ -108,6 +94,36 @@ var (
 
 	/* Optional Flags */
 
+	KeystoreFlag = cli.StringFlag{
+		Name: "keystore",
+		Usage: "File path of the encrypted keystore with the sequencer " +
+			"private key. The password-file flag must also be set.",
+		EnvVar: prefixEnvVar("KEYSTORE"),
+	}
+	PasswordFileFlag = cli.StringFlag{
+		Name:   "password-file",
+		Usage:  "File path of the password to decrypt the keystore with",
+		EnvVar: prefixEnvVar("PASSWORD_FILE"),
+	}
+	PrivateKeyFileFlag = cli.StringFlag{
+		Name: "private-key-file",
+		Usage: "File path of the unencrypted hex-encoded sequencer private " +
+			"key. Prefer an encrypted keystore.",
+		EnvVar: prefixEnvVar("PRIVATE_KEY_FILE"),
+	}
+	MnemonicFlag = cli.StringFlag{
+		Name: "mnemonic",
+		Usage: "The mnemonic used to derive the wallets for either the " +
+			"sequencer or the l2output. For development only, prefer an " +
+			"encrypted keystore.",
+		EnvVar: prefixEnvVar("MNEMONIC"),
+	}
+	SequencerHDPathFlag = cli.StringFlag{
+		Name: "sequencer-hd-path",
+		Usage: "The HD path used to derive the sequencer wallet from the " +
+			"mnemonic. The mnemonic flag must also be set.",
+		EnvVar: prefixEnvVar("SEQUENCER_HD_PATH"),
+	}
 	LogLevelFlag = cli.StringFlag{
 		Name:   "log-level",
 		Usage:  "The lowest log level that will be output",
@@ -132,14 +148,17 @@ var requiredFlags = []cli.Flag{
 	NumConfirmationsFlag,
 	SafeAbortNonceTooLowCountFlag,
 	ResubmissionTimeoutFlag,
-	MnemonicFlag,
-	SequencerHDPathFlag,
 	SequencerHistoryDBFilenameFlag,
 	SequencerGenesisHashFlag,
 	SequencerBatchInboxAddressFlag,
 }
 
 var optionalFlags = []cli.Flag{
+	KeystoreFlag,
+	PasswordFileFlag,
+	PrivateKeyFileFlag,
+	MnemonicFlag,
+	SequencerHDPathFlag,
 	LogLevelFlag,
 	LogTerminalFlag,
 }
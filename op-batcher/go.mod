@@ -6,7 +6,6 @@ require (
 	github.com/ethereum-optimism/optimism/op-node v0.0.0
 	github.com/ethereum-optimism/optimism/op-proposer v0.0.0
 	github.com/ethereum/go-ethereum v1.10.17
-	github.com/miguelmota/go-ethereum-hdwallet v0.1.1
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli v1.22.5
 )
@@ -97,6 +96,7 @@ require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
 	github.com/miekg/dns v1.1.43 // indirect
+	github.com/miguelmota/go-ethereum-hdwallet v0.1.1 // indirect
 	github.com/mikioh/tcpinfo v0.0.0-20190314235526-30a79bb1804b // indirect
 	github.com/mikioh/tcpopt v0.0.0-20190314235656-172688c1accc // indirect
 	github.com/minio/blake2b-simd v0.0.0-20160723061019-3f5f724cb5b1 // indirect
@@ -1,7 +1,9 @@
 package op_e2e
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -31,6 +33,8 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 )
@@ -92,6 +96,11 @@ type SystemConfig struct {
 	// A nil map disables P2P completely.
 	// Any node name not in the topology will not have p2p enabled.
 	P2PTopology map[string][]string
+
+	// Pre-shared keys of private p2p networks, per node name. Nodes without a key are on the public network.
+	// Mocknet connections do not go through the libp2p transports, so private networks are emulated:
+	// nodes are only linked and connected if they are on the same network.
+	P2PPrivateNetworks map[string]pnet.PSK
 }
 
 type System struct {
@@ -437,6 +446,7 @@ func (cfg SystemConfig) start() (*System, error) {
 				HostP2P:   h,
 				LocalNode: nil,
 				UDPv5:     nil,
+				PSK:       cfg.P2PPrivateNetworks[name],
 			}
 			p2pNodes[name] = p
 			return p, nil
@@ -452,6 +462,9 @@ func (cfg SystemConfig) start() (*System, error) {
 				if err != nil {
 					return nil, fmt.Errorf("failed to setup mocknet peer %s (peer of %s)", v, k)
 				}
+				if !bytes.Equal(peerA.PSK, peerB.PSK) {
+					continue // peers on different networks cannot reach each other
+				}
 				if _, err := sys.Mocknet.LinkPeers(peerA.HostP2P.ID(), peerB.HostP2P.ID()); err != nil {
 					return nil, fmt.Errorf("failed to setup mocknet link between %s and %s", k, v)
 				}
@@ -478,18 +491,15 @@ func (cfg SystemConfig) start() (*System, error) {
 			didErrAfterStart = true
 			return nil, err
 		}
-		err = node.Start(context.Background())
-		if err != nil {
-			didErrAfterStart = true
-			return nil, err
-		}
 		sys.rollupNodes[name] = node
 	}
 
 	if cfg.P2PTopology != nil {
-		// We only set up the connections after starting the actual nodes,
+		// We only set up the connections after creating the actual nodes,
 		// so GossipSub and other p2p protocols can be started before the connections go live.
 		// This way protocol negotiation happens correctly.
+		// The nodes are only started once the gossip mesh is formed, so the peers of the sequencer
+		// do not miss the blocks it builds right away, to catch up with the L2 genesis time.
 		for k, vs := range cfg.P2PTopology {
 			peerA := p2pNodes[k]
 			for _, v := range vs {
@@ -497,14 +507,29 @@ func (cfg SystemConfig) start() (*System, error) {
 				if unconnected {
 					v = v[1:]
 				}
-				if !unconnected {
-					peerB := p2pNodes[v]
+				peerB := p2pNodes[v]
+				if !unconnected && bytes.Equal(peerA.PSK, peerB.PSK) {
 					if _, err := sys.Mocknet.ConnectPeers(peerA.HostP2P.ID(), peerB.HostP2P.ID()); err != nil {
 						return nil, fmt.Errorf("failed to setup mocknet connection between %s and %s", k, v)
 					}
+					if err := waitForBlocksTopicPeer(sys.rollupNodes[k], peerB.HostP2P.ID(), 5*time.Second); err != nil {
+						return nil, fmt.Errorf("%s did not join the blocks topic of %s: %w", v, k, err)
+					}
+					if err := waitForBlocksTopicPeer(sys.rollupNodes[v], peerA.HostP2P.ID(), 5*time.Second); err != nil {
+						return nil, fmt.Errorf("%s did not join the blocks topic of %s: %w", k, v, err)
+					}
 				}
 			}
 		}
+		// the mesh is grafted on the first gossip heartbeat after the peers joined the topic
+		time.Sleep(2 * p2p.BuildGlobalGossipParams(&sys.cfg.RollupConfig).HeartbeatInterval)
+	}
+
+	for _, node := range sys.rollupNodes {
+		if err := node.Start(context.Background()); err != nil {
+			didErrAfterStart = true
+			return nil, err
+		}
 	}
 
 	rollupEndpoint := fmt.Sprintf(
@@ -574,3 +599,21 @@ func (cfg SystemConfig) start() (*System, error) {
 
 	return sys, nil
 }
+
+// waitForBlocksTopicPeer waits until the rollup node knows the given peer is subscribed to the blocks topic.
+func waitForBlocksTopicPeer(node *rollupNode.OpNode, id peer.ID, timeout time.Duration) error {
+	timeoutCh := time.After(timeout)
+	for {
+		for _, p := range node.P2P().GossipOut().BlocksTopicPeers() {
+			if p == id {
+				return nil
+			}
+		}
+
+		select {
+		case <-timeoutCh:
+			return errors.New("timeout")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package op_e2e
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"flag"
 	"fmt"
 	"math/big"
@@ -18,6 +20,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/predeploy"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
@@ -27,12 +30,15 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	"github.com/stretchr/testify/require"
 )
 
@@ -118,9 +124,12 @@ func defaultSystemConfig(t *testing.T) SystemConfig {
 			"sequencer": testlog.Logger(t, log.LvlError).New("role", "sequencer"),
 		},
 		RollupConfig: rollup.Config{
+			// The sequencer does not wait for L1 blocks: the L2 block with the timestamp of the next L1 block
+			// is sealed before that L1 block is seen, and extends the previous epoch. The sequencing window
+			// includes the L1 block after, so the batch of that L2 block can still land in it.
 			BlockTime:         1,
 			MaxSequencerDrift: 10,
-			SeqWindowSize:     2,
+			SeqWindowSize:     3,
 			L1ChainID:         big.NewInt(900),
 			L2ChainID:         big.NewInt(901),
 			// TODO pick defaults
@@ -195,9 +204,9 @@ func TestL2OutputSubmitter(t *testing.T) {
 			defer cancel()
 			l2Output, err := rollupClient.OutputAtBlock(ctx, l2ooBlockNumber)
 			require.Nil(t, err)
-			require.Len(t, l2Output, 2)
+			require.Equal(t, l2ooBlockNumber.Uint64(), l2Output.BlockRef.Number)
 
-			require.Equal(t, l2Output[1][:], committedL2Output.OutputRoot[:])
+			require.Equal(t, l2Output.OutputRoot[:], committedL2Output.OutputRoot[:])
 			break
 		}
 
@@ -554,6 +563,58 @@ func TestSystemMockP2P(t *testing.T) {
 	require.Contains(t, received, receiptVerif.BlockHash)
 }
 
+// TestSystemMockP2PPrivateNetwork tests that nodes on the same private network receive blocks over p2p,
+// and that nodes on a different network do not.
+func TestSystemMockP2PPrivateNetwork(t *testing.T) {
+	if !verboseGethNodes {
+		log.Root().SetHandler(log.DiscardHandler())
+	}
+
+	for _, sameNetwork := range []bool{true, false} {
+		t.Run(fmt.Sprintf("same network %v", sameNetwork), func(t *testing.T) {
+			cfg := defaultSystemConfig(t)
+			// slow down L1 blocks, so L2 blocks can only arrive on time through p2p
+			cfg.L1BlockTime = 10
+			cfg.P2PTopology = map[string][]string{
+				"verifier": []string{"sequencer"},
+			}
+			verifierPSK := pnet.PSK(bytes.Repeat([]byte{0x01}, 32))
+			if !sameNetwork {
+				verifierPSK = bytes.Repeat([]byte{0x02}, 32)
+			}
+			cfg.P2PPrivateNetworks = map[string]pnet.PSK{
+				"sequencer": bytes.Repeat([]byte{0x01}, 32),
+				"verifier":  verifierPSK,
+			}
+
+			received := make(chan common.Hash, 100)
+			verifTracer := new(FnTracer)
+			verifTracer.OnUnsafeL2PayloadFn = func(ctx context.Context, from peer.ID, payload *l2.ExecutionPayload) {
+				received <- payload.BlockHash
+			}
+			cfg.Nodes["verifier"].Tracer = verifTracer
+
+			sys, err := cfg.start()
+			require.Nil(t, err, "Error starting up system")
+			defer sys.Close()
+
+			if !sameNetwork {
+				seqID := sys.rollupNodes["sequencer"].P2P().Host().ID()
+				verifID := sys.rollupNodes["verifier"].P2P().Host().ID()
+				_, err := sys.Mocknet.ConnectPeers(verifID, seqID)
+				require.Error(t, err, "connected to a node on another private network")
+			}
+
+			select {
+			case <-received:
+				require.True(t, sameNetwork, "received block from a node on another private network")
+			case <-time.After(5 * time.Duration(cfg.RollupConfig.BlockTime) * time.Second):
+				require.False(t, sameNetwork, "expected block from a node on the same private network")
+			}
+		})
+	}
+}
+
 func TestL1InfoContract(t *testing.T) {
 	if !verboseGethNodes {
 		log.Root().SetHandler(log.DiscardHandler())
@@ -838,3 +899,137 @@ func TestWithdrawals(t *testing.T) {
 	withdrawAmount = withdrawAmount.Sub(withdrawAmount, fees)
 	require.Equal(t, withdrawAmount, diff)
 }
+
+// TestForcedInclusion tests that the sequencer includes the transactions queued over its admin RPC right after the deposits.
+func TestForcedInclusion(t *testing.T) {
+	if !verboseGethNodes {
+		log.Root().SetHandler(log.DiscardHandler())
+	}
+
+	cfg := defaultSystemConfig(t)
+	jwtSecret := make([]byte, 32)
+	_, err := rand.Read(jwtSecret)
+	require.NoError(t, err)
+	cfg.Nodes["sequencer"].RPC.AdminJWTSecret = jwtSecret
+	cfg.Nodes["sequencer"].RPC.AdminListenPort = 9094
+	cfg.Nodes["sequencer"].SequencerInclusion = driver.InclusionConfig{MaxTxsPerBlock: 2, MaxGasPerBlock: 1_000_000}
+
+	sys, err := cfg.start()
+	require.Nil(t, err, "Error starting up system")
+	defer sys.Close()
+
+	l2Seq := sys.Clients["sequencer"]
+	l2Verif := sys.Clients["verifier"]
+
+	adminClient, err := rpc.DialContext(context.Background(), fmt.Sprintf("http://%s:%d", cfg.Nodes["sequencer"].RPC.ListenAddr, cfg.Nodes["sequencer"].RPC.AdminListenPort))
+	require.Nil(t, err)
+	defer adminClient.Close()
+	// tokens expire within seconds, a new token is used for every request
+	authCall := func(result interface{}, method string, args ...interface{}) error {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iat": time.Now().Unix()}).SignedString(jwtSecret)
+		require.NoError(t, err)
+		adminClient.SetHeader("Authorization", "Bearer "+token)
+		return adminClient.Call(result, method, args...)
+	}
+
+	// The premined account of the L1 clique signer is not used on L2 otherwise
+	privKey, err := sys.wallet.PrivateKey(accounts.Account{URL: accounts.URL{Path: cliqueSignerHDPath}})
+	require.Nil(t, err)
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(privKey, types.LatestSignerForChainID(cfg.L2ChainID), &types.DynamicFeeTx{
+			ChainID:   cfg.L2ChainID,
+			Nonce:     nonce,
+			To:        &common.Address{0xff, 0xff},
+			Value:     big.NewInt(1_000_000_000),
+			GasTipCap: big.NewInt(10),
+			GasFeeCap: big.NewInt(200),
+			Gas:       21000,
+		})
+	}
+	// The transactions are forced right away: the sequencer may reorg its first unsafe blocks at startup,
+	// the transactions of the reorged blocks are forced again.
+	// The engine drops the second transaction, because of the nonce gap
+	txs := []*types.Transaction{newTx(0), newTx(5), newTx(1)}
+	for _, tx := range txs {
+		data, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		var hash common.Hash
+		require.NoError(t, authCall(&hash, "admin_queueTransaction", hexutil.Bytes(data)))
+		require.Equal(t, tx.Hash(), hash)
+	}
+
+	var receipts []*types.Receipt
+	for _, tx := range []*types.Transaction{txs[0], txs[2]} {
+		// the verifier derives the blocks with the forced transactions from the batches
+		receipt, err := waitForTransaction(tx.Hash(), l2Verif, 10*time.Duration(cfg.L1BlockTime)*time.Second)
+		require.Nil(t, err, "Waiting for forced L2 tx on verifier")
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		receipts = append(receipts, receipt)
+
+		seqBlock, err := l2Seq.BlockByNumber(context.Background(), receipt.BlockNumber)
+		require.Nil(t, err)
+		require.Equal(t, seqBlock.Hash(), receipt.BlockHash, "Verifier and sequencer blocks not the same after including a forced tx")
+
+		// the status points to the canonical block
+		var status driver.InclusionStatus
+		require.NoError(t, authCall(&status, "admin_transactionStatus", tx.Hash()))
+		require.Equal(t, driver.InclusionIncluded, status.State)
+		require.Equal(t, receipt.BlockHash, status.Block.Hash)
+	}
+
+	// the first forced transaction is right after the deposits. If its block was reorged out,
+	// the engine may have taken the next one from its transaction pool, where the reorg put it back.
+	block, err := l2Seq.BlockByHash(context.Background(), receipts[0].BlockHash)
+	require.Nil(t, err)
+	for i, blockTx := range block.Transactions()[:receipts[0].TransactionIndex] {
+		require.Equal(t, uint8(types.DepositTxType), blockTx.Type(), "tx %d before forced tx is a deposit", i)
+	}
+	require.GreaterOrEqual(t, receipts[1].BlockNumber.Uint64(), receipts[0].BlockNumber.Uint64())
+
+	var status driver.InclusionStatus
+	require.NoError(t, authCall(&status, "admin_transactionStatus", txs[1].Hash()))
+	require.Equal(t, driver.InclusionDropped, status.State)
+	// the dropped transaction is forced again if its block is reorged out, the status points to a canonical block
+	droppedBlock, err := l2Seq.BlockByNumber(context.Background(), new(big.Int).SetUint64(status.Block.Number))
+	require.Nil(t, err)
+	require.Equal(t, droppedBlock.Hash(), status.Block.Hash)
+}
+
+// TestReferenceChecker tests that the verifier compares its safe L2 chain with the L2 node of the sequencer.
+func TestReferenceChecker(t *testing.T) {
+	if !verboseGethNodes {
+		log.Root().SetHandler(log.DiscardHandler())
+	}
+
+	cfg := defaultSystemConfig(t)
+	cfg.Nodes["verifier"].L2ReferenceAddrs = []string{cfg.Nodes["sequencer"].L2NodeAddr}
+	cfg.Nodes["verifier"].RPC = node.RPCConfig{
+		ListenAddr: "127.0.0.1",
+		ListenPort: 9095,
+	}
+
+	sys, err := cfg.start()
+	require.Nil(t, err, "Error starting up system")
+	defer sys.Close()
+
+	rollupRPCClient, err := rpc.DialContext(context.Background(), fmt.Sprintf("http://%s:%d", cfg.Nodes["verifier"].RPC.ListenAddr, cfg.Nodes["verifier"].RPC.ListenPort))
+	require.Nil(t, err)
+	defer rollupRPCClient.Close()
+
+	// The sequencer may reorg its first unsafe blocks at startup, then the checker may see a divergence
+	// until the sequencer derived the same safe blocks.
+	timeout := time.After(20 * time.Duration(cfg.L1BlockTime) * time.Second)
+	for {
+		var status *driver.CheckerStatus
+		require.NoError(t, rollupRPCClient.Call(&status, "optimism_checkerStatus"))
+		require.False(t, status.Halted)
+		if status.Checked.Number >= 5 && status.Divergence == nil {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for the safe L2 chain to be checked, latest status: %+v", status)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
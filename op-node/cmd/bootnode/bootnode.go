@@ -0,0 +1,162 @@
+package bootnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli"
+
+	opnode "github.com/ethereum-optimism/optimism/op-node"
+	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// tableStatsInterval is how often the size of the discovery table is logged.
+const tableStatsInterval = time.Minute
+
+// Flags of the bootnode. The bootnode shares these with the rollup node, but does not need any of the others.
+var Flags = []cli.Flag{
+	flags.RollupConfig,
+	flags.P2PPrivPath,
+	flags.P2PPrivRaw,
+	flags.ListenIP,
+	flags.ListenUDPPort,
+	flags.AdvertiseIP,
+	flags.AdvertiseUDPPort,
+	flags.Bootnodes,
+	flags.DiscoveryPath,
+	flags.RPCListenAddr,
+	flags.RPCListenPort,
+	flags.LogLevelFlag,
+	flags.LogFormatFlag,
+	flags.LogColorFlag,
+}
+
+// NewApp creates the bootnode CLI app.
+// The bootnode runs as standalone app, since the rollup node app requires flags that the bootnode does not use.
+func NewApp(version string) *cli.App {
+	app := cli.NewApp()
+	app.Flags = Flags
+	app.Version = version
+	app.Name = "bootnode"
+	app.Usage = "Optimism Rollup Node discv5 bootnode"
+	app.Description = "The bootnode only runs discv5, for rollup nodes to bootstrap the discovery of peers with."
+	app.Action = Main
+	return app
+}
+
+func Main(ctx *cli.Context) error {
+	logCfg, err := opnode.NewLogConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the log config: %w", err)
+	}
+	logger := logCfg.NewLogger()
+
+	rollupCfg, err := opnode.NewRollupConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the rollup config: %w", err)
+	}
+	conf, err := p2p.NewDiscoveryConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the discovery config: %w", err)
+	}
+	defer conf.DiscoveryDB.Close()
+
+	localNode, udpV5, err := conf.Discovery(logger.New("p2p", "discv5"), rollupCfg, p2p.HostPorts{})
+	if err != nil {
+		return fmt.Errorf("failed to start discv5: %w", err)
+	}
+	defer udpV5.Close()
+
+	srv := rpc.NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName(p2p.NamespaceRPC, NewAPI(localNode, udpV5)); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", ctx.GlobalString(flags.RPCListenAddr.Name), ctx.GlobalInt(flags.RPCListenPort.Name)))
+	if err != nil {
+		return fmt.Errorf("failed to open RPC listener: %w", err)
+	}
+	httpSrv := &http.Server{Handler: srv}
+	go func() {
+		if err := httpSrv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("rpc server failed", "err", err)
+		}
+	}()
+	defer func() {
+		_ = httpSrv.Shutdown(context.Background())
+	}()
+
+	logger.Info("Bootnode started", "enr", localNode.Node(), "rpc", listener.Addr())
+
+	statsCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go logTableStats(statsCtx, logger, rollupCfg, udpV5)
+
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, []os.Signal{
+		os.Interrupt,
+		os.Kill,
+		syscall.SIGTERM,
+		syscall.SIGQUIT,
+	}...)
+	<-interruptChannel
+
+	return nil
+}
+
+// logTableStats periodically logs the size of the discovery table,
+// and how many of the nodes in it are rollup nodes of the configured chain.
+func logTableStats(ctx context.Context, logger log.Logger, rollupCfg *rollup.Config, udpV5 *discover.UDPv5) {
+	ticker := time.NewTicker(tableStatsInterval)
+	defer ticker.Stop()
+	filter := p2p.FilterEnodes(logger, rollupCfg)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nodes := udpV5.AllNodes()
+			rollupNodes := 0
+			for _, n := range nodes {
+				if filter(n) {
+					rollupNodes++
+				}
+			}
+			logger.Info("Discovery table", "nodes", len(nodes), "rollup_nodes", rollupNodes)
+		}
+	}
+}
+
+// API serves the discovery table of the bootnode,
+// compatible with the discovery methods of the p2p API of the rollup node.
+type API struct {
+	localNode *enode.LocalNode
+	udpV5     *discover.UDPv5
+}
+
+func NewAPI(localNode *enode.LocalNode, udpV5 *discover.UDPv5) *API {
+	return &API{localNode: localNode, udpV5: udpV5}
+}
+
+// DiscoveryTable returns all nodes in the discovery table.
+func (api *API) DiscoveryTable(_ context.Context) ([]*enode.Node, error) {
+	return api.udpV5.AllNodes(), nil
+}
+
+// LocalNode returns the node record of the bootnode itself.
+func (api *API) LocalNode(_ context.Context) (*enode.Node, error) {
+	return api.localNode.Node(), nil
+}
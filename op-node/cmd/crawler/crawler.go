@@ -0,0 +1,196 @@
+package crawler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli"
+
+	opnode "github.com/ethereum-optimism/optimism/op-node"
+	"github.com/ethereum-optimism/optimism/op-node/flags"
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
+)
+
+var (
+	DurationFlag = cli.DurationFlag{
+		Name:  "crawl.duration",
+		Usage: "How long to walk the discovery DHT for, before identifying the nodes that were found",
+		Value: time.Minute,
+	}
+	WorkersFlag = cli.IntFlag{
+		Name:  "crawl.workers",
+		Usage: "Number of nodes to identify in parallel",
+		Value: 16,
+	}
+	TimeoutFlag = cli.DurationFlag{
+		Name:  "crawl.timeout",
+		Usage: "Timeout to dial and identify a single node",
+		Value: 10 * time.Second,
+	}
+	FormatFlag = cli.StringFlag{
+		Name:  "crawl.format",
+		Usage: "Format of the report. Options: 'json', 'csv'",
+		Value: "json",
+	}
+	OutputFlag = cli.StringFlag{
+		Name:      "crawl.output",
+		Usage:     "File to write the report to. The report is written to stdout if empty",
+		TakesFile: true,
+	}
+)
+
+// Flags of the crawler. The discovery flags are shared with the rollup node, the others are specific to the crawler.
+var Flags = []cli.Flag{
+	flags.RollupConfig,
+	flags.ListenIP,
+	flags.ListenUDPPort,
+	flags.Bootnodes,
+	DurationFlag,
+	WorkersFlag,
+	TimeoutFlag,
+	FormatFlag,
+	OutputFlag,
+	flags.LogLevelFlag,
+	flags.LogFormatFlag,
+	flags.LogColorFlag,
+}
+
+// NewApp creates the crawler CLI app.
+// The crawler runs as standalone app, since the rollup node app requires flags that the crawler does not use.
+func NewApp(version string) *cli.App {
+	app := cli.NewApp()
+	app.Flags = Flags
+	app.Version = version
+	app.Name = "crawl"
+	app.Usage = "Optimism Rollup Node p2p network crawler"
+	app.Description = "The crawler walks discv5 from the bootnodes to find the rollup nodes of the chain, " +
+		"identifies each of them with libp2p, and writes a report of the nodes."
+	app.Action = Main
+	return app
+}
+
+func Main(ctx *cli.Context) error {
+	logCfg, err := opnode.NewLogConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the log config: %w", err)
+	}
+	// The report may be written to stdout, so we log to stderr
+	logger := logCfg.NewLoggerTo(os.Stderr)
+
+	rollupCfg, err := opnode.NewRollupConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the rollup config: %w", err)
+	}
+	format := ctx.GlobalString(FormatFlag.Name)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown report format %q", format)
+	}
+	workers := ctx.GlobalInt(WorkersFlag.Name)
+	if workers < 1 {
+		return fmt.Errorf("expected at least 1 worker, got %d", workers)
+	}
+
+	conf, err := p2p.NewCrawlConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create the crawl config: %w", err)
+	}
+	defer conf.DiscoveryDB.Close()
+
+	_, udpV5, err := conf.Discovery(logger.New("p2p", "discv5"), rollupCfg, p2p.HostPorts{})
+	if err != nil {
+		return fmt.Errorf("failed to start discv5: %w", err)
+	}
+	defer udpV5.Close()
+
+	h, err := conf.CrawlHost()
+	if err != nil {
+		return fmt.Errorf("failed to start libp2p host: %w", err)
+	}
+	defer h.Close()
+
+	// The crawl can be interrupted, the nodes found so far are still identified and reported.
+	walkCtx, cancel := context.WithTimeout(context.Background(), ctx.GlobalDuration(DurationFlag.Name))
+	defer cancel()
+	interruptChannel := make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interruptChannel)
+	go func() {
+		select {
+		case <-interruptChannel:
+			cancel()
+		case <-walkCtx.Done():
+		}
+	}()
+
+	crawler := p2p.NewCrawler(logger, rollupCfg, udpV5, h)
+	logger.Info("Crawling discovery DHT", "duration", ctx.GlobalDuration(DurationFlag.Name))
+	nodes := crawler.Walk(walkCtx)
+	logger.Info("Identifying nodes", "nodes", len(nodes))
+	results := crawler.Identify(context.Background(), nodes, workers, ctx.GlobalDuration(TimeoutFlag.Name))
+
+	reachable := 0
+	for _, res := range results {
+		if res.Reachable {
+			reachable++
+		}
+	}
+	logger.Info("Crawl complete", "nodes", len(results), "reachable", reachable)
+
+	out := io.Writer(os.Stdout)
+	if path := ctx.GlobalString(OutputFlag.Name); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open report file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if format == "csv" {
+		return WriteCSV(out, results)
+	}
+	return WriteJSON(out, results)
+}
+
+// WriteJSON writes the crawl results as JSON array.
+func WriteJSON(w io.Writer, results []p2p.CrawlResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// WriteCSV writes the crawl results as CSV, with a header row.
+// Lists of addresses and protocols are space-separated.
+func WriteCSV(w io.Writer, results []p2p.CrawlResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"node_id", "peer_id", "seq", "addrs", "reachable", "agent_version", "protocol_version", "protocols", "error", "enr"}); err != nil {
+		return err
+	}
+	for _, res := range results {
+		row := []string{
+			res.NodeID,
+			res.PeerID,
+			strconv.FormatUint(res.Seq, 10),
+			strings.Join(res.Addrs, " "),
+			strconv.FormatBool(res.Reachable),
+			res.AgentVersion,
+			res.ProtocolVersion,
+			strings.Join(res.Protocols, " "),
+			res.Error,
+			res.ENR,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -7,6 +7,8 @@ import (
 	"syscall"
 
 	opnode "github.com/ethereum-optimism/optimism/op-node"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/bootnode"
+	"github.com/ethereum-optimism/optimism/op-node/cmd/crawler"
 
 	"github.com/ethereum-optimism/optimism/op-node/version"
 
@@ -47,12 +49,24 @@ func main() {
 		),
 	)
 
+	// The bootnode and crawler run standalone, without the rollup node and its required flags.
+	// These are dispatched to before the rollup node app runs, since it checks its required flags before any subcommand.
+	if len(os.Args) > 1 {
+		if newApp, ok := standaloneApps[os.Args[1]]; ok {
+			if err := newApp(VersionWithMeta).Run(os.Args[1:]); err != nil {
+				log.Crit("Application failed", "message", err)
+			}
+			return
+		}
+	}
+
 	app := cli.NewApp()
 	app.Flags = flags.Flags
 	app.Version = VersionWithMeta
 	app.Name = "opnode"
 	app.Usage = "Optimism Rollup Node"
-	app.Description = "The deposit only rollup node drives the L2 execution engine based on L1 deposits."
+	app.Description = "The deposit only rollup node drives the L2 execution engine based on L1 deposits.\n" +
+		"   Run 'bootnode' or 'crawl' as first argument to run the standalone discv5 bootnode or p2p crawler instead."
 
 	app.Action = RollupNodeMain
 	err := app.Run(os.Args)
@@ -61,6 +75,12 @@ func main() {
 	}
 }
 
+// standaloneApps are the subcommands that run as their own app, with their own flags.
+var standaloneApps = map[string]func(version string) *cli.App{
+	"bootnode": bootnode.NewApp,
+	"crawl":    crawler.NewApp,
+}
+
 func RollupNodeMain(ctx *cli.Context) error {
 	log.Info("Initializing Rollup Node")
 	cfg, err := opnode.NewConfig(ctx)
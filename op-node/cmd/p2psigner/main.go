@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/p2p"
+)
+
+// p2psigner is a reference remote signer for sequencer p2p block signing.
+// It signs with a local key, and is intended as a local stand-in for a proper signing service.
+
+var (
+	privKey     = flag.String("priv-key", "", "hex encoded private key to sign with")
+	listenAddr  = flag.String("addr", "127.0.0.1:8560", "listen address of the signer")
+	bearerToken = flag.String("token", "", "bearer token that clients must authenticate with")
+	tlsCert     = flag.String("tls.cert", "", "path to the PEM server certificate, enables TLS")
+	tlsKey      = flag.String("tls.key", "", "path to the PEM server key")
+	tlsClientCA = flag.String("tls.client-ca", "", "path to the PEM CA that client certificates must be signed by, enables mTLS")
+)
+
+func main() {
+	flag.Parse()
+
+	log.Root().SetHandler(
+		log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stdout, log.TerminalFormat(true))),
+	)
+
+	if *privKey == "" {
+		log.Crit("missing required -priv-key flag")
+	}
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(*privKey, "0x"))
+	if err != nil {
+		log.Crit("failed to parse private key", "err", err)
+	}
+	handler, err := p2p.NewSignerHandler(p2p.NewSignerAPI(priv), *bearerToken)
+	if err != nil {
+		log.Crit("failed to create signer handler", "err", err)
+	}
+	srv := &http.Server{Addr: *listenAddr, Handler: handler}
+
+	if *tlsClientCA != "" {
+		caPEM, err := os.ReadFile(*tlsClientCA)
+		if err != nil {
+			log.Crit("failed to read client CA", "err", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			log.Crit("failed to parse client CA")
+		}
+		srv.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS12}
+	}
+
+	log.Info("starting signer", "addr", *listenAddr, "address", crypto.PubkeyToAddress(priv.PublicKey))
+	if *tlsCert != "" {
+		err = srv.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil {
+		log.Crit("signer failed", "err", err)
+	}
+}
@@ -0,0 +1,27 @@
+package eth
+
+import "fmt"
+
+// BlockLabel is a named block reference, resolved by the execution engine to the block it currently points to.
+type BlockLabel string
+
+const (
+	// Unsafe is the head of the L2 chain, including blocks that are not derived from L1 yet.
+	Unsafe BlockLabel = "latest"
+	// Safe is the head of the L2 chain that is fully derived from L1 data.
+	Safe BlockLabel = "safe"
+	// Finalized is the head of the L2 chain that is derived from finalized L1 data.
+	Finalized BlockLabel = "finalized"
+)
+
+func (label BlockLabel) Arg() interface{} { return string(label) }
+
+// Check returns an error if the label is not one of the known block labels.
+func (label BlockLabel) Check() error {
+	switch label {
+	case Unsafe, Safe, Finalized:
+		return nil
+	default:
+		return fmt.Errorf("unknown block label: %q", string(label))
+	}
+}
@@ -56,12 +56,103 @@ var (
 		Usage:  "Trust the L1 RPC, sync faster at risk of malicious/buggy RPC providing bad or inconsistent L1 data",
 		EnvVar: prefixEnvVar("L1_TRUST_RPC"),
 	}
+	L2ReferenceAddrs = cli.StringSliceFlag{
+		Name:   "l2.reference",
+		Usage:  "Addresses of trusted L2 User JSON-RPC endpoints to compare the safe L2 chain with (eth namespace required)",
+		EnvVar: prefixEnvVar("L2_REFERENCE_RPC"),
+	}
+	L2ReferenceHalt = cli.BoolFlag{
+		Name:   "l2.reference.halt",
+		Usage:  "Halt the rollup node when the safe L2 chain persistently diverges from a L2 reference, instead of following it",
+		EnvVar: prefixEnvVar("L2_REFERENCE_HALT"),
+	}
+
+	RPCCORSDomains = cli.StringFlag{
+		Name:   "rpc.corsdomain",
+		Usage:  "Comma separated list of domains from which to accept cross origin HTTP requests and WebSocket connections",
+		Value:  "*",
+		EnvVar: prefixEnvVar("RPC_CORS_DOMAIN"),
+	}
+	RPCVHosts = cli.StringFlag{
+		Name:   "rpc.vhosts",
+		Usage:  "Comma separated list of virtual hostnames from which to accept HTTP and WebSocket requests",
+		Value:  "*",
+		EnvVar: prefixEnvVar("RPC_VHOSTS"),
+	}
+	RPCHTTPModules = cli.StringFlag{
+		Name:   "rpc.api",
+		Usage:  "Comma separated list of API namespaces to serve over HTTP, all public APIs if empty",
+		EnvVar: prefixEnvVar("RPC_API"),
+	}
+	RPCWSEnabled = cli.BoolFlag{
+		Name:   "rpc.ws",
+		Usage:  "Enable the WebSocket RPC server",
+		EnvVar: prefixEnvVar("RPC_WS"),
+	}
+	RPCWSListenPort = cli.IntFlag{
+		Name:   "rpc.ws.port",
+		Usage:  "WebSocket RPC listening port, shares the HTTP RPC port if 0 or equal",
+		EnvVar: prefixEnvVar("RPC_WS_PORT"),
+	}
+	RPCWSModules = cli.StringFlag{
+		Name:   "rpc.ws.api",
+		Usage:  "Comma separated list of API namespaces to serve over WebSocket, all public APIs if empty",
+		EnvVar: prefixEnvVar("RPC_WS_API"),
+	}
+	RPCIPCPath = cli.StringFlag{
+		Name:   "rpc.ipc.path",
+		Usage:  "Path of the IPC socket to serve RPC on, IPC is disabled if empty",
+		EnvVar: prefixEnvVar("RPC_IPC_PATH"),
+	}
+	RPCIPCModules = cli.StringFlag{
+		Name:   "rpc.ipc.api",
+		Usage:  "Comma separated list of API namespaces to serve over IPC, all public APIs if empty",
+		EnvVar: prefixEnvVar("RPC_IPC_API"),
+	}
+	RPCAdminJWTSecret = cli.StringFlag{
+		Name:   "rpc.admin.jwt-secret",
+		Usage:  "Path to a file with the hex encoded 32 byte JWT secret, to authenticate admin RPC requests with. The admin API is disabled if not set",
+		EnvVar: prefixEnvVar("RPC_ADMIN_JWT_SECRET"),
+	}
+	RPCAdminListenPort = cli.IntFlag{
+		Name:   "rpc.admin.port",
+		Usage:  "Admin RPC listening port, on the RPC listening address",
+		Value:  9546,
+		EnvVar: prefixEnvVar("RPC_ADMIN_PORT"),
+	}
 
 	SequencingEnabledFlag = cli.BoolFlag{
 		Name:   "sequencing.enabled",
 		Usage:  "enable sequencing",
 		EnvVar: prefixEnvVar("SEQUENCING_ENABLED"),
 	}
+	SequencingThrottleNoTxPoolFlag = cli.Uint64Flag{
+		Name:   "sequencing.throttle.notxpool",
+		Usage:  "Number of unsafe L2 blocks ahead of the safe head at which the sequencer stops including tx pool transactions, 0 to disable",
+		EnvVar: prefixEnvVar("SEQUENCING_THROTTLE_NOTXPOOL"),
+	}
+	SequencingThrottleStopFlag = cli.Uint64Flag{
+		Name:   "sequencing.throttle.stop",
+		Usage:  "Number of unsafe L2 blocks ahead of the safe head at which the sequencer stops producing blocks, 0 to disable",
+		EnvVar: prefixEnvVar("SEQUENCING_THROTTLE_STOP"),
+	}
+	SequencingThrottleL1LagFlag = cli.Uint64Flag{
+		Name:   "sequencing.throttle.l1lag",
+		Usage:  "Number of L1 blocks the L1 origin of the safe head may fall behind the sequencing window, before the sequencer stops including tx pool transactions, 0 to disable",
+		EnvVar: prefixEnvVar("SEQUENCING_THROTTLE_L1LAG"),
+	}
+	SequencingInclusionMaxTxsFlag = cli.Uint64Flag{
+		Name:   "sequencing.inclusion.max-txs",
+		Usage:  "Maximum number of transactions queued over the admin RPC that the sequencer includes per block, 0 to disable",
+		Value:  16,
+		EnvVar: prefixEnvVar("SEQUENCING_INCLUSION_MAX_TXS"),
+	}
+	SequencingInclusionMaxGasFlag = cli.Uint64Flag{
+		Name:   "sequencing.inclusion.max-gas",
+		Usage:  "Maximum sum of the gas limits of the transactions queued over the admin RPC that the sequencer includes per block",
+		Value:  5_000_000,
+		EnvVar: prefixEnvVar("SEQUENCING_INCLUSION_MAX_GAS"),
+	}
 
 	LogLevelFlag = cli.StringFlag{
 		Name:   "log.level",
@@ -81,6 +172,24 @@ var (
 		EnvVar: prefixEnvVar("LOG_COLOR"),
 	}
 
+	MetricsEnabledFlag = cli.BoolFlag{
+		Name:   "metrics.enabled",
+		Usage:  "Enable the metrics server",
+		EnvVar: prefixEnvVar("METRICS_ENABLED"),
+	}
+	MetricsAddrFlag = cli.StringFlag{
+		Name:   "metrics.addr",
+		Usage:  "Metrics listening address",
+		Value:  "0.0.0.0",
+		EnvVar: prefixEnvVar("METRICS_ADDR"),
+	}
+	MetricsPortFlag = cli.IntFlag{
+		Name:   "metrics.port",
+		Usage:  "Metrics listening port",
+		Value:  7300,
+		EnvVar: prefixEnvVar("METRICS_PORT"),
+	}
+
 	SnapshotLog = cli.StringFlag{
 		Name:   "snapshotlog.file",
 		Usage:  "Path to the snapshot log file",
@@ -99,10 +208,30 @@ var requiredFlags = []cli.Flag{
 
 var optionalFlags = append([]cli.Flag{
 	L1TrustRPC,
+	L2ReferenceAddrs,
+	L2ReferenceHalt,
+	RPCCORSDomains,
+	RPCVHosts,
+	RPCHTTPModules,
+	RPCWSEnabled,
+	RPCWSListenPort,
+	RPCWSModules,
+	RPCIPCPath,
+	RPCIPCModules,
+	RPCAdminJWTSecret,
+	RPCAdminListenPort,
 	SequencingEnabledFlag,
+	SequencingThrottleNoTxPoolFlag,
+	SequencingThrottleStopFlag,
+	SequencingThrottleL1LagFlag,
+	SequencingInclusionMaxTxsFlag,
+	SequencingInclusionMaxGasFlag,
 	LogLevelFlag,
 	LogFormatFlag,
 	LogColorFlag,
+	MetricsEnabledFlag,
+	MetricsAddrFlag,
+	MetricsPortFlag,
 	SnapshotLog,
 }, p2pFlags...)
 
@@ -41,6 +41,23 @@ var (
 		Value:    "",
 		EnvVar:   p2pEnv("PRIV_RAW"),
 	}
+	PSKPath = cli.StringFlag{
+		Name: "p2p.psk.path",
+		Usage: "Read the pre-shared key of a private network from this file, in the libp2p swarm key format. " +
+			"Nodes of a private network only connect to nodes with the same key. Discovery is disabled on private networks.",
+		Required:  false,
+		Value:     "",
+		EnvVar:    p2pEnv("PSK_PATH"),
+		TakesFile: true,
+	}
+	PSKRaw = cli.StringFlag{
+		Name:     "p2p.psk",
+		Usage:    "The hex-encoded 32-byte pre-shared key of a private network. Alternative to p2p.psk.path.",
+		Required: false,
+		Hidden:   true,
+		Value:    "",
+		EnvVar:   p2pEnv("PSK"),
+	}
 	ListenIP = cli.StringFlag{
 		Name:     "p2p.listen.ip",
 		Usage:    "IP to bind LibP2P and Discv5 to",
@@ -85,6 +102,46 @@ var (
 		Value:    0,
 		EnvVar:   p2pEnv("ADVERTISE_TCP"),
 	}
+	QUIC = cli.BoolFlag{
+		Name:     "p2p.quic",
+		Usage:    "Enable the QUIC transport for LibP2P, in addition to TCP.",
+		Required: false,
+		EnvVar:   p2pEnv("QUIC"),
+	}
+	ListenQUICPort = cli.UintFlag{
+		Name:     "p2p.listen.quic",
+		Usage:    "UDP port to bind the LibP2P QUIC transport to, if enabled. Any available system port if set to 0.",
+		Required: false,
+		Value:    0,
+		EnvVar:   p2pEnv("LISTEN_QUIC_PORT"),
+	}
+	AdvertiseQUICPort = cli.UintFlag{
+		Name:     "p2p.advertise.quic",
+		Usage:    "The QUIC port to advertise in Discv5, put into the ENR of the node. Set to the QUIC port that is listened on if 0.",
+		Required: false,
+		Value:    0,
+		EnvVar:   p2pEnv("ADVERTISE_QUIC"),
+	}
+	WebSocket = cli.BoolFlag{
+		Name:     "p2p.ws",
+		Usage:    "Enable the WebSocket transport for LibP2P, in addition to TCP.",
+		Required: false,
+		EnvVar:   p2pEnv("WS"),
+	}
+	ListenWSPort = cli.UintFlag{
+		Name:     "p2p.listen.ws",
+		Usage:    "TCP port to bind the LibP2P WebSocket transport to, if enabled. Any available system port if set to 0.",
+		Required: false,
+		Value:    0,
+		EnvVar:   p2pEnv("LISTEN_WS_PORT"),
+	}
+	AdvertiseWSPort = cli.UintFlag{
+		Name:     "p2p.advertise.ws",
+		Usage:    "The WebSocket port to advertise in Discv5, put into the ENR of the node. Set to the WebSocket port that is listened on if 0.",
+		Required: false,
+		Value:    0,
+		EnvVar:   p2pEnv("ADVERTISE_WS"),
+	}
 	Bootnodes = cli.StringFlag{
 		Name:     "p2p.bootnodes",
 		Usage:    "Comma-separated base64-format ENR list. Bootnodes to start discovering other node records from.",
@@ -99,6 +156,15 @@ var (
 		Value:    "",
 		EnvVar:   p2pEnv("BOOTNODES"),
 	}
+	PeerGroups = cli.StringFlag{
+		Name: "p2p.peer-groups",
+		Usage: "Path to a JSON file with the peer groups: 'trusted', 'sequencer' and/or 'replica'. " +
+			"Each group lists multiaddr-format peers, the number of connection slots reserved for them, and if they are always redialed.",
+		Required:  false,
+		Value:     "",
+		EnvVar:    p2pEnv("PEER_GROUPS"),
+		TakesFile: true,
+	}
 	HostMux = cli.StringFlag{
 		Name:     "p2p.mux",
 		Usage:    "Comma-separated list of multiplexing protocols in order of preference. At least 1 required. Options: 'yamux','mplex'.",
@@ -136,6 +202,62 @@ var (
 		Value:    30 * time.Second,
 		EnvVar:   p2pEnv("PEERS_GRACE"),
 	}
+	ResourcesMemory = cli.Uint64Flag{
+		Name:     "p2p.resources.memory",
+		Usage:    "Maximum memory in MiB that the p2p host may reserve for connections and streams.",
+		Required: false,
+		Value:    512,
+		EnvVar:   p2pEnv("RESOURCES_MEMORY"),
+	}
+	ResourcesConns = cli.IntFlag{
+		Name:     "p2p.resources.conns",
+		Usage:    "Maximum number of inbound and of outbound connections of the p2p host.",
+		Required: false,
+		Value:    256,
+		EnvVar:   p2pEnv("RESOURCES_CONNS"),
+	}
+	ResourcesStreams = cli.IntFlag{
+		Name:     "p2p.resources.streams",
+		Usage:    "Maximum number of inbound and of outbound streams of the p2p host.",
+		Required: false,
+		Value:    4096,
+		EnvVar:   p2pEnv("RESOURCES_STREAMS"),
+	}
+	ResourcesPeerMemory = cli.Uint64Flag{
+		Name:     "p2p.resources.peer.memory",
+		Usage:    "Maximum memory in MiB that a single peer may use.",
+		Required: false,
+		Value:    32,
+		EnvVar:   p2pEnv("RESOURCES_PEER_MEMORY"),
+	}
+	ResourcesPeerConns = cli.IntFlag{
+		Name:     "p2p.resources.peer.conns",
+		Usage:    "Maximum number of connections with a single peer.",
+		Required: false,
+		Value:    8,
+		EnvVar:   p2pEnv("RESOURCES_PEER_CONNS"),
+	}
+	ResourcesPeerStreams = cli.IntFlag{
+		Name:     "p2p.resources.peer.streams",
+		Usage:    "Maximum number of streams with a single peer.",
+		Required: false,
+		Value:    256,
+		EnvVar:   p2pEnv("RESOURCES_PEER_STREAMS"),
+	}
+	ResourcesProtocolStreams = cli.IntFlag{
+		Name:     "p2p.resources.protocol.streams",
+		Usage:    "Maximum number of streams of a single protocol.",
+		Required: false,
+		Value:    2048,
+		EnvVar:   p2pEnv("RESOURCES_PROTOCOL_STREAMS"),
+	}
+	ResourcesProtocolPeerStreams = cli.IntFlag{
+		Name:     "p2p.resources.protocol.peer.streams",
+		Usage:    "Maximum number of streams of a single protocol with a single peer.",
+		Required: false,
+		Value:    64,
+		EnvVar:   p2pEnv("RESOURCES_PROTOCOL_PEER_STREAMS"),
+	}
 	NAT = cli.BoolFlag{
 		Name:     "p2p.nat",
 		Usage:    "Enable NAT traversal with PMP/UPNP devices to learn external IP.",
@@ -192,6 +314,14 @@ var (
 		Value:     "opnode_discovery_db",
 		EnvVar:    p2pEnv("DISCOVERY_PATH"),
 	}
+	EvidencePath = cli.StringFlag{
+		Name:      "p2p.evidence.path",
+		Usage:     "Directory to write the evidence of sequencer equivocations to, as JSON files. Set to 'memory' to never persist the evidence.",
+		Required:  false,
+		TakesFile: true,
+		Value:     "opnode_evidence",
+		EnvVar:    p2pEnv("EVIDENCE_PATH"),
+	}
 	SequencerP2PKeyFlag = cli.StringFlag{
 		Name:      "p2p.sequencer.key",
 		Usage:     "File path of hex-encoded private key for signing off on p2p application messages as sequencer.",
@@ -200,6 +330,74 @@ var (
 		Value:     "",
 		EnvVar:    p2pEnv("SEQUENCER_KEY"),
 	}
+	SequencerP2PKeystoreFlag = cli.StringFlag{
+		Name:      "p2p.sequencer.keystore",
+		Usage:     "File path of an encrypted keystore with the private key for signing off on p2p application messages as sequencer.",
+		Required:  false,
+		TakesFile: true,
+		Value:     "",
+		EnvVar:    p2pEnv("SEQUENCER_KEYSTORE"),
+	}
+	SequencerP2PKeystorePasswordFlag = cli.StringFlag{
+		Name:      "p2p.sequencer.keystore.password",
+		Usage:     "File path of the password to decrypt the p2p sequencer keystore with.",
+		Required:  false,
+		TakesFile: true,
+		Value:     "",
+		EnvVar:    p2pEnv("SEQUENCER_KEYSTORE_PASSWORD"),
+	}
+	SequencerP2PSignerEndpointFlag = cli.StringFlag{
+		Name:     "p2p.sequencer.signer.endpoint",
+		Usage:    "HTTP(S) endpoint of a remote signer for signing off on p2p application messages as sequencer. Cannot be combined with a local sequencer key.",
+		Required: false,
+		Value:    "",
+		EnvVar:   p2pEnv("SEQUENCER_SIGNER_ENDPOINT"),
+	}
+	SequencerP2PSignerTokenFlag = cli.StringFlag{
+		Name:     "p2p.sequencer.signer.token",
+		Usage:    "Bearer token to authenticate with the remote signer.",
+		Required: false,
+		Value:    "",
+		EnvVar:   p2pEnv("SEQUENCER_SIGNER_TOKEN"),
+	}
+	SequencerP2PSignerTLSCAFlag = cli.StringFlag{
+		Name:      "p2p.sequencer.signer.tls.ca",
+		Usage:     "File path of the PEM-encoded CA certificate to verify the remote signer with.",
+		Required:  false,
+		TakesFile: true,
+		Value:     "",
+		EnvVar:    p2pEnv("SEQUENCER_SIGNER_TLS_CA"),
+	}
+	SequencerP2PSignerTLSCertFlag = cli.StringFlag{
+		Name:      "p2p.sequencer.signer.tls.cert",
+		Usage:     "File path of the PEM-encoded client certificate to authenticate with the remote signer (mTLS).",
+		Required:  false,
+		TakesFile: true,
+		Value:     "",
+		EnvVar:    p2pEnv("SEQUENCER_SIGNER_TLS_CERT"),
+	}
+	SequencerP2PSignerTLSKeyFlag = cli.StringFlag{
+		Name:      "p2p.sequencer.signer.tls.key",
+		Usage:     "File path of the PEM-encoded client key to authenticate with the remote signer (mTLS).",
+		Required:  false,
+		TakesFile: true,
+		Value:     "",
+		EnvVar:    p2pEnv("SEQUENCER_SIGNER_TLS_KEY"),
+	}
+	SequencerP2PSignerTimeoutFlag = cli.DurationFlag{
+		Name:     "p2p.sequencer.signer.timeout",
+		Usage:    "Timeout of a single signing request to the remote signer.",
+		Required: false,
+		Value:    time.Second * 2,
+		EnvVar:   p2pEnv("SEQUENCER_SIGNER_TIMEOUT"),
+	}
+	SequencerP2PSignerAttemptsFlag = cli.IntFlag{
+		Name:     "p2p.sequencer.signer.attempts",
+		Usage:    "Maximum number of attempts of a signing request to the remote signer, before giving up.",
+		Required: false,
+		Value:    3,
+		EnvVar:   p2pEnv("SEQUENCER_SIGNER_ATTEMPTS"),
+	}
 )
 
 // None of these flags are strictly required.
@@ -209,19 +407,36 @@ var p2pFlags = []cli.Flag{
 	NoDiscovery,
 	P2PPrivPath,
 	P2PPrivRaw,
+	PSKPath,
+	PSKRaw,
 	ListenIP,
 	ListenTCPPort,
 	ListenUDPPort,
 	AdvertiseIP,
 	AdvertiseTCPPort,
 	AdvertiseUDPPort,
+	QUIC,
+	ListenQUICPort,
+	AdvertiseQUICPort,
+	WebSocket,
+	ListenWSPort,
+	AdvertiseWSPort,
 	Bootnodes,
 	StaticPeers,
+	PeerGroups,
 	HostMux,
 	HostSecurity,
 	PeersLo,
 	PeersHi,
 	PeersGrace,
+	ResourcesMemory,
+	ResourcesConns,
+	ResourcesStreams,
+	ResourcesPeerMemory,
+	ResourcesPeerConns,
+	ResourcesPeerStreams,
+	ResourcesProtocolStreams,
+	ResourcesProtocolPeerStreams,
 	NAT,
 	UserAgent,
 	TimeoutNegotiation,
@@ -229,5 +444,15 @@ var p2pFlags = []cli.Flag{
 	TimeoutDial,
 	PeerstorePath,
 	DiscoveryPath,
+	EvidencePath,
 	SequencerP2PKeyFlag,
+	SequencerP2PKeystoreFlag,
+	SequencerP2PKeystorePasswordFlag,
+	SequencerP2PSignerEndpointFlag,
+	SequencerP2PSignerTokenFlag,
+	SequencerP2PSignerTLSCAFlag,
+	SequencerP2PSignerTLSCertFlag,
+	SequencerP2PSignerTLSKeyFlag,
+	SequencerP2PSignerTimeoutFlag,
+	SequencerP2PSignerAttemptsFlag,
 }
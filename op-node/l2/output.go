@@ -0,0 +1,83 @@
+package l2
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/predeploy"
+)
+
+// OutputV0 is the only supported L2 output root version.
+var OutputV0 = Bytes32{}
+
+// OutputWithProof is an L2 output root, together with all the data that is committed to by the output root.
+type OutputWithProof struct {
+	Version    Bytes32 `json:"version"`
+	OutputRoot Bytes32 `json:"outputRoot"`
+	// BlockRef is the L2 block the output was computed at, including the L1 origin it was derived from
+	BlockRef eth.L2BlockRef `json:"blockRef"`
+	// Header is the header of the L2 block, the output root commits to its hash and state root
+	Header *types.Header `json:"header"`
+	// MessagePasserProof proves the storage hash of the withdrawals message-passer contract against the state root
+	MessagePasserProof *AccountResult `json:"messagePasserProof"`
+}
+
+// Verify checks that the output root is computed from the header and message-passer account proof.
+// This does not verify that the header is part of the canonical L2 chain, see VerifyOutput for that.
+func (o *OutputWithProof) Verify() error {
+	if o.Version != OutputV0 {
+		return fmt.Errorf("unsupported output root version: %s", o.Version)
+	}
+	if o.Header == nil {
+		return fmt.Errorf("missing L2 header")
+	}
+	if o.MessagePasserProof == nil {
+		return fmt.Errorf("missing message-passer account proof")
+	}
+	blockHash := o.Header.Hash()
+	if o.BlockRef.Hash != blockHash || o.BlockRef.Number != o.Header.Number.Uint64() {
+		return fmt.Errorf("block ref %s does not match header %s:%d", o.BlockRef, blockHash, o.Header.Number)
+	}
+	if o.BlockRef.ParentHash != o.Header.ParentHash || o.BlockRef.Time != o.Header.Time {
+		return fmt.Errorf("block ref %s parent hash or time does not match header", o.BlockRef)
+	}
+	if o.MessagePasserProof.Address != predeploy.WithdrawalContractAddress {
+		return fmt.Errorf("account proof is for %s, not the message-passer %s", o.MessagePasserProof.Address, predeploy.WithdrawalContractAddress)
+	}
+	if err := o.MessagePasserProof.Verify(o.Header.Root); err != nil {
+		return fmt.Errorf("invalid message-passer account proof: %w", err)
+	}
+	if root := ComputeL2OutputRoot(o.Version, blockHash, o.Header.Root, o.MessagePasserProof.StorageHash); root != o.OutputRoot {
+		return fmt.Errorf("output root %s does not match computed output root %s", o.OutputRoot, root)
+	}
+	return nil
+}
+
+// L2HeaderSource is an independent source of L2 headers, e.g. an ethclient.Client connected to a L2 node.
+type L2HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// VerifyOutput verifies the output root and its proof, and checks that the L2 block it commits to
+// is canonical according to an independent L2 source.
+func VerifyOutput(ctx context.Context, src L2HeaderSource, output *OutputWithProof) error {
+	if err := output.Verify(); err != nil {
+		return err
+	}
+	header, err := src.HeaderByNumber(ctx, new(big.Int).SetUint64(output.BlockRef.Number))
+	if err != nil {
+		return fmt.Errorf("failed to retrieve L2 header %s from independent source: %w", output.BlockRef, err)
+	}
+	if header == nil {
+		return fmt.Errorf("independent source has no block at height %d, expected %s: %w", output.BlockRef.Number, output.BlockRef, ethereum.NotFound)
+	}
+	if header.Hash() != output.BlockRef.Hash {
+		return fmt.Errorf("independent source has canonical block %s at height %d, expected %s", header.Hash(), output.BlockRef.Number, output.BlockRef)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package l2
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/predeploy"
+)
+
+type fakeHeaderSource map[uint64]*types.Header
+
+func (f fakeHeaderSource) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return f[number.Uint64()], nil
+}
+
+// testOutput builds a state with a message-passer account, and a valid output with proof for it.
+func testOutput(t *testing.T) *OutputWithProof {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	st, err := state.New(common.Hash{}, db, nil)
+	require.NoError(t, err)
+	addr := predeploy.WithdrawalContractAddress
+	st.SetBalance(addr, big.NewInt(42))
+	st.SetNonce(addr, 1)
+	st.SetState(addr, common.Hash{0x01}, common.Hash{0x02})
+	st.SetBalance(common.Address{0xff}, big.NewInt(1))
+	root, err := st.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, db.TrieDB().Commit(root, false, nil))
+
+	st, err = state.New(root, db, nil)
+	require.NoError(t, err)
+	proof, err := st.GetProof(addr)
+	require.NoError(t, err)
+	accountProof := make([]hexutil.Bytes, len(proof))
+	for i, p := range proof {
+		accountProof[i] = p
+	}
+	account := &AccountResult{
+		AccountProof: accountProof,
+		Address:      addr,
+		Balance:      (*hexutil.Big)(st.GetBalance(addr)),
+		CodeHash:     st.GetCodeHash(addr),
+		Nonce:        hexutil.Uint64(st.GetNonce(addr)),
+		StorageHash:  st.StorageTrie(addr).Hash(),
+	}
+
+	header := &types.Header{
+		ParentHash: common.Hash{0xaa},
+		Root:       root,
+		Number:     big.NewInt(123),
+		Time:       1000,
+		Difficulty: common.Big0,
+	}
+	return &OutputWithProof{
+		Version:    OutputV0,
+		OutputRoot: ComputeL2OutputRoot(OutputV0, header.Hash(), root, account.StorageHash),
+		BlockRef: eth.L2BlockRef{
+			Hash:       header.Hash(),
+			Number:     123,
+			ParentHash: header.ParentHash,
+			Time:       header.Time,
+			L1Origin:   eth.BlockID{Hash: common.Hash{0xbb}, Number: 10},
+		},
+		Header:             header,
+		MessagePasserProof: account,
+	}
+}
+
+func TestOutputWithProofVerify(t *testing.T) {
+	require.NoError(t, testOutput(t).Verify())
+
+	cases := map[string]func(o *OutputWithProof){
+		"unknown version":    func(o *OutputWithProof) { o.Version = Bytes32{0x01} },
+		"wrong output root":  func(o *OutputWithProof) { o.OutputRoot[0] ^= 1 },
+		"wrong block ref":    func(o *OutputWithProof) { o.BlockRef.Number += 1 },
+		"wrong storage hash": func(o *OutputWithProof) { o.MessagePasserProof.StorageHash[0] ^= 1 },
+		"wrong account":      func(o *OutputWithProof) { o.MessagePasserProof.Address = common.Address{0xff} },
+		"wrong state root":   func(o *OutputWithProof) { o.Header.Root[0] ^= 1 },
+		"missing proof":      func(o *OutputWithProof) { o.MessagePasserProof = nil },
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := testOutput(t)
+			tamper(o)
+			require.Error(t, o.Verify())
+		})
+	}
+}
+
+func TestVerifyOutput(t *testing.T) {
+	o := testOutput(t)
+	require.NoError(t, VerifyOutput(context.Background(), fakeHeaderSource{123: o.Header}, o))
+
+	other := types.CopyHeader(o.Header)
+	other.Extra = []byte("reorg")
+	require.Error(t, VerifyOutput(context.Background(), fakeHeaderSource{123: other}, o))
+
+	err := VerifyOutput(context.Background(), fakeHeaderSource{}, o)
+	require.ErrorIs(t, err, ethereum.NotFound, "block is missing in the independent source")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -16,21 +17,102 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	lru "github.com/hashicorp/golang-lru"
 )
 
+type SourceConfig struct {
+	// Number of execution payloads to cache, by block hash
+	PayloadsCacheSize int
+	// Number of L2 block references to cache, by block hash
+	BlockRefsCacheSize int
+	// Number of canonical block-number to block-hash mappings to cache.
+	// These are invalidated whenever the forkchoice of the engine changes.
+	CanonicalCacheSize int
+}
+
+func (c *SourceConfig) Check() error {
+	if c.PayloadsCacheSize <= 0 {
+		return fmt.Errorf("invalid payloads cache size: %d", c.PayloadsCacheSize)
+	}
+	if c.BlockRefsCacheSize <= 0 {
+		return fmt.Errorf("invalid block refs cache size: %d", c.BlockRefsCacheSize)
+	}
+	if c.CanonicalCacheSize <= 0 {
+		return fmt.Errorf("invalid canonical cache size: %d", c.CanonicalCacheSize)
+	}
+	return nil
+}
+
+func DefaultConfig(config *rollup.Config) *SourceConfig {
+	// A sequencing window worth of L2 blocks, assuming 12 second L1 blocks.
+	// The sync-start walks back up to this many L2 blocks to find the safe head.
+	span := int(config.SeqWindowSize) * 12 / int(config.BlockTime)
+	if span < 100 {
+		span = 100
+	}
+	return &SourceConfig{
+		// Payloads are big, only cache the ones that are immediately processed after the safe head.
+		PayloadsCacheSize: span,
+
+		// Block refs are small, cache a few windows to walk back through reorgs without hitting the RPC again.
+		BlockRefsCacheSize: span * 4,
+		CanonicalCacheSize: span * 4,
+	}
+}
+
 type Source struct {
 	rpc     *rpc.Client       // raw RPC client. Used for the consensus namespace
 	client  *ethclient.Client // go-ethereum's wrapper around the rpc client for the eth namespace
 	genesis *rollup.Genesis
 	log     log.Logger
+
+	// cache execution payloads by block hash
+	// common.Hash -> *ExecutionPayload
+	payloadsCache *lru.Cache
+
+	// cache L2 block references by block hash
+	// common.Hash -> eth.L2BlockRef
+	blockRefsCache *lru.Cache
+
+	// cache canonical block hashes by block number, invalidated on forkchoice changes (reorgs)
+	// uint64 -> common.Hash
+	canonicalCache *lru.Cache
+
+	// forkchoice is the latest forkchoice state that the engine accepted, to resolve the safe and finalized labels with.
+	// The engine does not resolve these labels itself.
+	forkchoiceLock sync.Mutex
+	forkchoice     ForkchoiceState
+}
+
+// rpcBlock is the minimal block-header data needed to resolve a block label to a block hash.
+type rpcBlock struct {
+	Hash common.Hash `json:"hash"`
 }
 
-func NewSource(l2Node *rpc.Client, genesis *rollup.Genesis, log log.Logger) (*Source, error) {
+func NewSource(l2Node *rpc.Client, genesis *rollup.Genesis, log log.Logger, config *SourceConfig) (*Source, error) {
+	if err := config.Check(); err != nil {
+		return nil, fmt.Errorf("bad config, cannot create L2 source: %w", err)
+	}
+	payloadsCache, err := lru.New(config.PayloadsCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payloads cache: %w", err)
+	}
+	blockRefsCache, err := lru.New(config.BlockRefsCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block refs cache: %w", err)
+	}
+	canonicalCache, err := lru.New(config.CanonicalCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create canonical cache: %w", err)
+	}
 	return &Source{
-		rpc:     l2Node,
-		client:  ethclient.NewClient(l2Node),
-		genesis: genesis,
-		log:     log,
+		rpc:            l2Node,
+		client:         ethclient.NewClient(l2Node),
+		genesis:        genesis,
+		log:            log,
+		payloadsCache:  payloadsCache,
+		blockRefsCache: blockRefsCache,
+		canonicalCache: canonicalCache,
 	}, nil
 }
 
@@ -38,29 +120,51 @@ func (s *Source) Close() {
 	s.rpc.Close()
 }
 
-func (s *Source) PayloadByHash(ctx context.Context, hash common.Hash) (*ExecutionPayload, error) {
+// cacheBlock converts the block to a payload and block reference, and caches both.
+func (s *Source) cacheBlock(block *types.Block) (*ExecutionPayload, eth.L2BlockRef, error) {
 	// TODO: we really do not need to parse every single tx and block detail, keeping transactions encoded is faster.
-	block, err := s.client.BlockByHash(ctx, hash)
+	payload, err := BlockAsPayload(block)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve L2 block by hash: %v", err)
+		return nil, eth.L2BlockRef{}, fmt.Errorf("failed to read L2 block as payload: %w", err)
 	}
-	payload, err := BlockAsPayload(block)
+	ref, err := PayloadToBlockRef(payload, s.genesis)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read L2 block as payload: %w", err)
+		return nil, eth.L2BlockRef{}, err
 	}
-	return payload, nil
+	s.payloadsCache.Add(payload.BlockHash, payload)
+	s.blockRefsCache.Add(ref.Hash, ref)
+	return payload, ref, nil
+}
+
+func (s *Source) PayloadByHash(ctx context.Context, hash common.Hash) (*ExecutionPayload, error) {
+	if payload, ok := s.payloadsCache.Get(hash); ok {
+		return payload.(*ExecutionPayload), nil
+	}
+	block, err := s.client.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve L2 block by hash: %w", err)
+	}
+	payload, _, err := s.cacheBlock(block)
+	return payload, err
 }
 
 func (s *Source) PayloadByNumber(ctx context.Context, number *big.Int) (*ExecutionPayload, error) {
-	// TODO: we really do not need to parse every single tx and block detail, keeping transactions encoded is faster.
+	if number != nil {
+		if hash, ok := s.canonicalCache.Get(number.Uint64()); ok {
+			if payload, ok := s.payloadsCache.Get(hash); ok {
+				return payload.(*ExecutionPayload), nil
+			}
+		}
+	}
 	block, err := s.client.BlockByNumber(ctx, number)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve L2 block by number: %v", err)
+		return nil, fmt.Errorf("failed to retrieve L2 block by number: %w", err)
 	}
-	payload, err := BlockAsPayload(block)
+	payload, ref, err := s.cacheBlock(block)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read L2 block as payload: %w", err)
+		return nil, err
 	}
+	s.canonicalCache.Add(ref.Number, ref.Hash)
 	return payload, nil
 }
 
@@ -97,6 +201,12 @@ func (s *Source) ForkchoiceUpdate(ctx context.Context, fc *ForkchoiceState, attr
 	case ExecutionInvalid:
 		return nil, fmt.Errorf("cannot update forkchoice, block is invalid: %v", err)
 	case ExecutionValid:
+		// The canonical chain may have changed, block numbers cannot be trusted to map to the same blocks anymore.
+		// Blocks by hash are immutable, and remain cached.
+		s.canonicalCache.Purge()
+		s.forkchoiceLock.Lock()
+		s.forkchoice = *fc
+		s.forkchoiceLock.Unlock()
 		return &result, nil
 	default:
 		return nil, fmt.Errorf("unknown forkchoice status on %s: %q, ", fc.SafeBlockHash, string(result.PayloadStatus.Status))
@@ -162,22 +272,73 @@ func (s *Source) GetPayload(ctx context.Context, payloadId PayloadID) (*Executio
 
 // L2BlockRefByNumber returns the canonical block and parent ids.
 func (s *Source) L2BlockRefByNumber(ctx context.Context, l2Num *big.Int) (eth.L2BlockRef, error) {
+	if l2Num != nil {
+		if hash, ok := s.canonicalCache.Get(l2Num.Uint64()); ok {
+			if ref, ok := s.blockRefsCache.Get(hash); ok {
+				return ref.(eth.L2BlockRef), nil
+			}
+		}
+	}
 	block, err := s.client.BlockByNumber(ctx, l2Num)
 	if err != nil {
 		// w%: wrap the error, we still need to detect if a canonical block is not found, a.k.a. end of chain.
 		return eth.L2BlockRef{}, fmt.Errorf("failed to determine block-hash of height %v, could not get header: %w", l2Num, err)
 	}
-	return blockToBlockRef(block, s.genesis)
+	_, ref, err := s.cacheBlock(block)
+	if err != nil {
+		return eth.L2BlockRef{}, err
+	}
+	s.canonicalCache.Add(ref.Number, ref.Hash)
+	return ref, nil
 }
 
 // L2BlockRefByHash returns the block & parent ids based on the supplied hash. The returned BlockRef may not be in the canonical chain
 func (s *Source) L2BlockRefByHash(ctx context.Context, l2Hash common.Hash) (eth.L2BlockRef, error) {
+	if ref, ok := s.blockRefsCache.Get(l2Hash); ok {
+		return ref.(eth.L2BlockRef), nil
+	}
 	block, err := s.client.BlockByHash(ctx, l2Hash)
 	if err != nil {
 		// w%: wrap the error, we still need to detect if a canonical block is not found, a.k.a. end of chain.
 		return eth.L2BlockRef{}, fmt.Errorf("failed to determine block-hash of height %v, could not get header: %w", l2Hash, err)
 	}
-	return blockToBlockRef(block, s.genesis)
+	_, ref, err := s.cacheBlock(block)
+	return ref, err
+}
+
+// L2BlockRefByLabel returns the block reference that the label currently points to.
+// The unsafe label is resolved by the engine, and thus never served from the cache.
+// The engine does not know the safe and finalized labels, these are resolved with the latest forkchoice state
+// the engine accepted. The finalized label resolves to the genesis block while nothing is finalized.
+func (s *Source) L2BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L2BlockRef, error) {
+	if err := label.Check(); err != nil {
+		return eth.L2BlockRef{}, err
+	}
+	if label != eth.Unsafe {
+		s.forkchoiceLock.Lock()
+		fc := s.forkchoice
+		s.forkchoiceLock.Unlock()
+		hash := fc.SafeBlockHash
+		if label == eth.Finalized {
+			hash = fc.FinalizedBlockHash
+			if hash == (common.Hash{}) {
+				hash = s.genesis.L2.Hash
+			}
+		}
+		if hash == (common.Hash{}) {
+			return eth.L2BlockRef{}, fmt.Errorf("cannot resolve %s L2 block, the engine forkchoice was not updated yet: %w", label, ethereum.NotFound)
+		}
+		return s.L2BlockRefByHash(ctx, hash)
+	}
+	var block *rpcBlock
+	if err := s.rpc.CallContext(ctx, &block, "eth_getBlockByNumber", label.Arg(), false); err != nil {
+		return eth.L2BlockRef{}, fmt.Errorf("failed to retrieve %s L2 block: %w", label, err)
+	}
+	if block == nil {
+		return eth.L2BlockRef{}, ethereum.NotFound
+	}
+	// The label lookup only retrieves the header, the full block is retrieved by hash, hitting the cache if possible.
+	return s.L2BlockRefByHash(ctx, block.Hash)
 }
 
 // blockToBlockRef extracts the essential L2BlockRef information from a block,
@@ -296,6 +457,23 @@ func (s *ReadOnlySource) L2BlockRefByHash(ctx context.Context, l2Hash common.Has
 	return blockToBlockRef(block, s.genesis)
 }
 
+// L2BlockRefByLabel returns the block reference that the label currently points to.
+// A read-only source does not see the forkchoice updates of the rollup node, so all labels are resolved by the engine.
+// Engines that do not track the safe and finalized labels return an error for these.
+func (s *ReadOnlySource) L2BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L2BlockRef, error) {
+	if err := label.Check(); err != nil {
+		return eth.L2BlockRef{}, err
+	}
+	var block *rpcBlock
+	if err := s.rpc.CallContext(ctx, &block, "eth_getBlockByNumber", label.Arg(), false); err != nil {
+		return eth.L2BlockRef{}, fmt.Errorf("failed to retrieve %s L2 block: %w", label, err)
+	}
+	if block == nil {
+		return eth.L2BlockRef{}, ethereum.NotFound
+	}
+	return s.L2BlockRefByHash(ctx, block.Hash)
+}
+
 func (s *ReadOnlySource) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
 	return s.client.BlockByNumber(ctx, number)
 }
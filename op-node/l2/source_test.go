@@ -0,0 +1,203 @@
+package l2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// fakeEthAPI serves an L2 chain over the eth namespace, and counts the requests it serves.
+// Like the engine, it only resolves the latest, earliest and pending block labels.
+type fakeEthAPI struct {
+	blocks   []*types.Block
+	byHash   map[common.Hash]*types.Block
+	requests int
+}
+
+func (api *fakeEthAPI) marshal(block *types.Block) (json.RawMessage, error) {
+	head, err := json.Marshal(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(head, &out); err != nil {
+		return nil, err
+	}
+	out["transactions"] = block.Transactions()
+	out["uncles"] = []common.Hash{}
+	return json.Marshal(out)
+}
+
+func (api *fakeEthAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (json.RawMessage, error) {
+	api.requests++
+	block, ok := api.byHash[hash]
+	if !ok {
+		return nil, nil
+	}
+	return api.marshal(block)
+}
+
+func (api *fakeEthAPI) GetBlockByNumber(ctx context.Context, id string, fullTx bool) (json.RawMessage, error) {
+	api.requests++
+	var num uint64
+	switch id {
+	case "latest", "pending":
+		num = uint64(len(api.blocks) - 1)
+	case "earliest":
+		num = 0
+	default:
+		n, err := hexutil.DecodeUint64(id)
+		if err != nil {
+			return nil, fmt.Errorf("bad block number: %w", err)
+		}
+		num = n
+	}
+	if num >= uint64(len(api.blocks)) {
+		return nil, nil
+	}
+	return api.marshal(api.blocks[num])
+}
+
+// fakeEngineAPI accepts any forkchoice update
+type fakeEngineAPI struct{}
+
+func (fakeEngineAPI) ForkchoiceUpdatedV1(ctx context.Context, fc *ForkchoiceState, attr *PayloadAttributes) (*ForkchoiceUpdatedResult, error) {
+	return &ForkchoiceUpdatedResult{PayloadStatus: PayloadStatusV1{Status: ExecutionValid}}, nil
+}
+
+func newFakeL2Chain(t *testing.T, length int) (*fakeEthAPI, *rollup.Genesis) {
+	genesisBlock := types.NewBlock(&types.Header{Number: big.NewInt(0), BaseFee: big.NewInt(7)}, nil, nil, nil, trie.NewStackTrie(nil))
+	genesis := &rollup.Genesis{
+		L1: eth.BlockID{Hash: common.Hash{0xaa}, Number: 100},
+		L2: eth.BlockID{Hash: genesisBlock.Hash(), Number: 0},
+	}
+	api := &fakeEthAPI{
+		blocks: []*types.Block{genesisBlock},
+		byHash: map[common.Hash]*types.Block{genesisBlock.Hash(): genesisBlock},
+	}
+	for i := 1; i < length; i++ {
+		info := derive.L1BlockInfo{
+			Number:         genesis.L1.Number,
+			Time:           1000,
+			BaseFee:        big.NewInt(7),
+			BlockHash:      genesis.L1.Hash,
+			SequenceNumber: uint64(i),
+		}
+		data, err := info.MarshalBinary()
+		require.NoError(t, err)
+		tx := types.NewTx(&types.DepositTx{
+			From:  derive.L1InfoDepositerAddress,
+			To:    &derive.L1InfoPredeployAddr,
+			Value: big.NewInt(0),
+			Gas:   99_999_999,
+			Data:  data,
+		})
+		parent := api.blocks[i-1]
+		block := types.NewBlock(&types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(int64(i)),
+			Time:       parent.Time() + 2,
+			BaseFee:    big.NewInt(7),
+		}, []*types.Transaction{tx}, nil, nil, trie.NewStackTrie(nil))
+		api.blocks = append(api.blocks, block)
+		api.byHash[block.Hash()] = block
+	}
+	return api, genesis
+}
+
+func newTestSource(t *testing.T, api *fakeEthAPI, genesis *rollup.Genesis) *Source {
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", api))
+	require.NoError(t, srv.RegisterName("engine", fakeEngineAPI{}))
+	t.Cleanup(srv.Stop)
+	cfg := &SourceConfig{PayloadsCacheSize: 10, BlockRefsCacheSize: 100, CanonicalCacheSize: 100}
+	src, err := NewSource(rpc.DialInProc(srv), genesis, testlog.Logger(t, log.LvlError), cfg)
+	require.NoError(t, err)
+	return src
+}
+
+func TestSourceWalkBackCached(t *testing.T) {
+	api, genesis := newFakeL2Chain(t, 50)
+	src := newTestSource(t, api, genesis)
+	ctx := context.Background()
+
+	walkBack := func() {
+		ref, err := src.L2BlockRefByNumber(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(49), ref.Number)
+		for ref.Number > genesis.L2.Number {
+			ref, err = src.L2BlockRefByHash(ctx, ref.ParentHash)
+			require.NoError(t, err)
+			require.Equal(t, genesis.L1, ref.L1Origin)
+		}
+		require.Equal(t, genesis.L2.Hash, ref.Hash)
+	}
+	walkBack()
+	require.Equal(t, 50, api.requests, "one request per block")
+	walkBack()
+	require.Equal(t, 51, api.requests, "only the head is requested again")
+
+	ref, err := src.L2BlockRefByNumber(ctx, big.NewInt(10))
+	require.NoError(t, err)
+	require.Equal(t, api.blocks[10].Hash(), ref.Hash)
+	require.Equal(t, uint64(10), ref.SequenceNumber)
+	_, err = src.L2BlockRefByNumber(ctx, big.NewInt(10))
+	require.NoError(t, err)
+	require.Equal(t, 52, api.requests, "canonical lookup by number is cached")
+
+	payload, err := src.PayloadByHash(ctx, api.blocks[49].Hash())
+	require.NoError(t, err)
+	require.Equal(t, api.blocks[49].Hash(), payload.BlockHash)
+	require.Equal(t, 52, api.requests, "recent payloads are cached")
+}
+
+func TestSourceBlockRefByLabel(t *testing.T) {
+	api, genesis := newFakeL2Chain(t, 20)
+	src := newTestSource(t, api, genesis)
+	ctx := context.Background()
+
+	// the safe block is unknown until the forkchoice is updated, nothing is finalized yet
+	_, err := src.L2BlockRefByLabel(ctx, eth.Safe)
+	require.ErrorIs(t, err, ethereum.NotFound)
+	ref, err := src.L2BlockRefByLabel(ctx, eth.Finalized)
+	require.NoError(t, err)
+	require.Equal(t, genesis.L2.Hash, ref.Hash)
+
+	_, err = src.ForkchoiceUpdate(ctx, &ForkchoiceState{
+		HeadBlockHash:      api.blocks[19].Hash(),
+		SafeBlockHash:      api.blocks[10].Hash(),
+		FinalizedBlockHash: api.blocks[5].Hash(),
+	}, nil)
+	require.NoError(t, err)
+
+	for label, num := range map[eth.BlockLabel]uint64{eth.Unsafe: 19, eth.Safe: 10, eth.Finalized: 5} {
+		ref, err := src.L2BlockRefByLabel(ctx, label)
+		require.NoError(t, err)
+		require.Equal(t, api.blocks[num].Hash(), ref.Hash, "label %s", label)
+	}
+	_, err = src.L2BlockRefByLabel(ctx, "pending")
+	require.Error(t, err)
+}
+
+func TestSourceConfigCheck(t *testing.T) {
+	cfg := SourceConfig{PayloadsCacheSize: 10, BlockRefsCacheSize: 10, CanonicalCacheSize: 0}
+	require.Error(t, cfg.Check())
+	_, err := NewSource(nil, &rollup.Genesis{}, testlog.Logger(t, log.LvlError), &cfg)
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const Namespace = "op_node"
+
+// Metrics of the rollup node, registered in a registry of its own,
+// so the metrics of libraries do not show up unless explicitly registered.
+type Metrics struct {
+	Equivocations prometheus.Counter
+	SequencerLag  prometheus.Gauge
+
+	ReferenceChecks      prometheus.Counter
+	ReferenceDivergences prometheus.Counter
+	ReferenceDiverged    prometheus.Gauge
+
+	registry *prometheus.Registry
+}
+
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	registry.MustRegister(collectors.NewGoCollector())
+	factory := promauto.With(registry)
+	return &Metrics{
+		Equivocations: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "p2p",
+			Name:      "equivocations_total",
+			Help:      "Count of detected sequencer equivocations: different validly signed blocks at the same height on the same parent",
+		}),
+		SequencerLag: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "sequencer",
+			Name:      "lag_seconds",
+			Help:      "How far the sequencer is behind the L2 timestamp of the latest block it started to build, negative if early",
+		}),
+		ReferenceChecks: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "checker",
+			Name:      "checks_total",
+			Help:      "Count of safe L2 blocks compared with the reference nodes",
+		}),
+		ReferenceDivergences: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "checker",
+			Name:      "divergences_total",
+			Help:      "Count of safe L2 blocks that did not match the block of a reference node at the same height, in several consecutive checks",
+		}),
+		ReferenceDiverged: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "checker",
+			Name:      "diverged",
+			Help:      "1 if the latest checked safe L2 block diverged from a reference node, 0 otherwise",
+		}),
+		registry: registry,
+	}
+}
+
+// RecordEquivocation counts a sequencer equivocation.
+func (m *Metrics) RecordEquivocation() {
+	m.Equivocations.Inc()
+}
+
+// RecordSequencerLag records how far the sequencer is behind the L2 timestamp of the block it starts to build.
+func (m *Metrics) RecordSequencerLag(lag time.Duration) {
+	m.SequencerLag.Set(lag.Seconds())
+}
+
+// RecordReferenceCheck records the comparison of a safe L2 block with the reference nodes.
+func (m *Metrics) RecordReferenceCheck(diverged bool) {
+	m.ReferenceChecks.Inc()
+	if diverged {
+		m.ReferenceDivergences.Inc()
+		m.ReferenceDiverged.Set(1)
+	} else {
+		m.ReferenceDiverged.Set(0)
+	}
+}
+
+// Serve serves the metrics over HTTP until the context is done.
+func (m *Metrics) Serve(ctx context.Context, hostname string, port int) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(hostname, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler: promhttp.InstrumentMetricHandler(m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})),
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
+)
+
+// NamespaceAdmin is the RPC namespace of the admin API. It is only served to authenticated clients.
+const NamespaceAdmin = "admin"
+
+type adminDriverClient interface {
+	QueueTransaction(tx *types.Transaction) error
+	InclusionStatus(hash common.Hash) *driver.InclusionStatus
+}
+
+// adminAPI serves the operator of the rollup node.
+type adminAPI struct {
+	dr  adminDriverClient
+	log log.Logger
+}
+
+func newAdminAPI(dr adminDriverClient, log log.Logger) *adminAPI {
+	return &adminAPI{dr: dr, log: log}
+}
+
+// QueueTransaction queues a signed L2 transaction, that the sequencer includes in its next blocks,
+// right after the deposits. Transactions are included in the order they are queued.
+func (a *adminAPI) QueueTransaction(ctx context.Context, data hexutil.Bytes) (common.Hash, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if err := a.dr.QueueTransaction(&tx); err != nil {
+		return common.Hash{}, err
+	}
+	a.log.Info("Queued transaction for inclusion", "hash", tx.Hash())
+	return tx.Hash(), nil
+}
+
+// TransactionStatus returns the inclusion status of a queued transaction.
+func (a *adminAPI) TransactionStatus(ctx context.Context, hash common.Hash) (*driver.InclusionStatus, error) {
+	status := a.dr.InclusionStatus(hash)
+	if status == nil {
+		return nil, ethereum.NotFound
+	}
+	return status, nil
+}
@@ -3,6 +3,7 @@ package node
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -14,10 +15,12 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/predeploy"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -36,24 +39,124 @@ type l2EthClient interface {
 	L2BlockRefByHash(ctx context.Context, l2Hash common.Hash) (eth.L2BlockRef, error)
 }
 
+type driverClient interface {
+	SyncStatus(ctx context.Context) (*driver.SyncStatus, error)
+	SubscribeHeadChanges(ch chan<- driver.HeadChange) event.Subscription
+	CheckerStatus() *driver.CheckerStatus
+}
+
 type nodeAPI struct {
 	config *rollup.Config
 	client l2EthClient
+	dr     driverClient // may be nil if no engine is driven by this node
 	log    log.Logger
 }
 
-func newNodeAPI(config *rollup.Config, l2Client l2EthClient, log log.Logger) *nodeAPI {
+func newNodeAPI(config *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger) *nodeAPI {
 	return &nodeAPI{
 		config: config,
 		client: l2Client,
+		dr:     dr,
 		log:    log,
 	}
 }
 
-func (n *nodeAPI) OutputAtBlock(ctx context.Context, number rpc.BlockNumber) ([]l2.Bytes32, error) {
-	// TODO: rpc.BlockNumber doesn't support the "safe" tag. Need a new type
+// BlockTag identifies a L2 block in the rollup-node RPC:
+// either a block number, or one of the "latest", "safe" and "finalized" labels.
+// Labels are resolved against the heads tracked by the rollup driver, not the L2 execution engine.
+// Without a driver, only the "latest" label is supported, resolved to the latest block of the engine.
+type BlockTag struct {
+	Label  eth.BlockLabel
+	Number uint64
+}
+
+func (t BlockTag) MarshalText() ([]byte, error) {
+	if t.Label != "" {
+		return []byte(t.Label), nil
+	}
+	return []byte(hexutil.EncodeUint64(t.Number)), nil
+}
+
+func (t *BlockTag) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if label := eth.BlockLabel(v); label.Check() == nil {
+		*t = BlockTag{Label: label}
+		return nil
+	}
+	num, err := hexutil.DecodeUint64(v)
+	if err != nil {
+		return fmt.Errorf("block tag is not a known label or hex block number: %q", v)
+	}
+	*t = BlockTag{Number: num}
+	return nil
+}
+
+func (t BlockTag) String() string {
+	text, _ := t.MarshalText()
+	return string(text)
+}
+
+type OutputResponse struct {
+	Version    l2.Bytes32 `json:"version"`
+	OutputRoot l2.Bytes32 `json:"outputRoot"`
+	// BlockRef is the L2 block the output was computed at, including the L1 origin it was derived from
+	BlockRef eth.L2BlockRef `json:"blockRef"`
+}
+
+// resolveTag returns the eth_getBlockByNumber argument of the L2 block, and the block hash it is expected to have.
+// The expected hash is zero if any block at the returned argument is acceptable.
+func (n *nodeAPI) resolveTag(ctx context.Context, tag BlockTag) (string, common.Hash, error) {
+	if tag.Label == "" {
+		return hexutil.EncodeUint64(tag.Number), common.Hash{}, nil
+	}
+	if n.dr == nil {
+		if tag.Label == eth.Unsafe {
+			return string(eth.Unsafe), common.Hash{}, nil
+		}
+		return "", common.Hash{}, fmt.Errorf("cannot resolve %q block label, rollup node is not driving any engine", tag.Label)
+	}
+	status, err := n.dr.SyncStatus(ctx)
+	if err != nil {
+		return "", common.Hash{}, fmt.Errorf("failed to retrieve sync status: %w", err)
+	}
+	var id eth.BlockID
+	switch tag.Label {
+	case eth.Unsafe:
+		id = status.UnsafeL2.ID()
+	case eth.Safe:
+		id = status.SafeL2.ID()
+	case eth.Finalized:
+		id = status.FinalizedL2
+	default:
+		return "", common.Hash{}, tag.Label.Check()
+	}
+	return hexutil.EncodeUint64(id.Number), id.Hash, nil
+}
+
+func (n *nodeAPI) OutputAtBlock(ctx context.Context, tag BlockTag) (*OutputResponse, error) {
+	output, err := n.OutputWithProof(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &OutputResponse{
+		Version:    output.Version,
+		OutputRoot: output.OutputRoot,
+		BlockRef:   output.BlockRef,
+	}, nil
+}
+
+// OutputWithProof returns the output root at the given block, with the L2 header and message-passer account proof
+// that the output root is computed from, such that callers can verify the output root with l2.VerifyOutput.
+func (n *nodeAPI) OutputWithProof(ctx context.Context, tag BlockTag) (*l2.OutputWithProof, error) {
+	blockArg, expectedHash, err := n.resolveTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
 
-	head, err := n.client.GetBlockHeader(ctx, toBlockNumArg(number))
+	head, err := n.client.GetBlockHeader(ctx, blockArg)
 	if err != nil {
 		n.log.Error("failed to get block", "err", err)
 		return nil, err
@@ -61,8 +164,12 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number rpc.BlockNumber) ([]
 	if head == nil {
 		return nil, ethereum.NotFound
 	}
+	if expectedHash != (common.Hash{}) && head.Hash() != expectedHash {
+		return nil, fmt.Errorf("%s block %s does not match engine block %s, L2 chain may be reorging", tag, expectedHash, head.Hash())
+	}
 
-	proof, err := n.client.GetProof(ctx, predeploy.WithdrawalContractAddress, toBlockNumArg(number))
+	// query the proof by block number, not by label, since the engine label may have moved on since the header query
+	proof, err := n.client.GetProof(ctx, predeploy.WithdrawalContractAddress, hexutil.EncodeBig(head.Number))
 	if err != nil {
 		n.log.Error("failed to get contract proof", "err", err)
 		return nil, err
@@ -72,28 +179,115 @@ func (n *nodeAPI) OutputAtBlock(ctx context.Context, number rpc.BlockNumber) ([]
 	}
 	// make sure that the proof (including storage hash) that we retrieved is correct by verifying it against the state-root
 	if err := proof.Verify(head.Root); err != nil {
-		n.log.Error("invalid withdrawal root detected in block", "stateRoot", head.Root, "blocknum", number, "msg", err)
+		n.log.Error("invalid withdrawal root detected in block", "stateRoot", head.Root, "blocknum", head.Number, "msg", err)
 		return nil, fmt.Errorf("invalid withdrawal root hash")
 	}
 
-	var l2OutputRootVersion l2.Bytes32 // it's zero for now
-	l2OutputRoot := l2.ComputeL2OutputRoot(l2OutputRootVersion, head.Hash(), head.Root, proof.StorageHash)
+	ref, err := n.client.L2BlockRefByHash(ctx, head.Hash())
+	if err != nil {
+		n.log.Error("failed to get L2 block ref", "err", err)
+		return nil, err
+	}
 
-	return []l2.Bytes32{l2OutputRootVersion, l2OutputRoot}, nil
+	return &l2.OutputWithProof{
+		Version:            l2.OutputV0,
+		OutputRoot:         l2.ComputeL2OutputRoot(l2.OutputV0, head.Hash(), head.Root, proof.StorageHash),
+		BlockRef:           ref,
+		Header:             head,
+		MessagePasserProof: proof,
+	}, nil
 }
 
 func (n *nodeAPI) Version(ctx context.Context) (string, error) {
 	return version.Version + "-" + version.Meta, nil
 }
 
-func toBlockNumArg(number rpc.BlockNumber) string {
-	if number == rpc.LatestBlockNumber {
-		return "latest"
+// CheckerStatus returns the result of the latest comparisons of the safe L2 chain with the trusted L2 references.
+func (n *nodeAPI) CheckerStatus(ctx context.Context) (*driver.CheckerStatus, error) {
+	if n.dr == nil {
+		return nil, errors.New("cannot check the safe L2 chain, rollup node is not driving any engine")
 	}
-	if number == rpc.PendingBlockNumber {
-		return "pending"
+	status := n.dr.CheckerStatus()
+	if status == nil {
+		return nil, errors.New("rollup node does not check the safe L2 chain against any L2 references")
 	}
-	return hexutil.EncodeUint64(uint64(number.Int64()))
+	return status, nil
+}
+
+// ReorgEvent is the notification of the reorg subscription, with the L2 heads before and after the reorg.
+type ReorgEvent struct {
+	OldUnsafeL2 eth.L2BlockRef `json:"oldUnsafeL2"`
+	NewUnsafeL2 eth.L2BlockRef `json:"newUnsafeL2"`
+	OldSafeL2   eth.L2BlockRef `json:"oldSafeL2"`
+	NewSafeL2   eth.L2BlockRef `json:"newSafeL2"`
+}
+
+// UnsafeHead subscribes to changes of the unsafe L2 head, notifying the new eth.L2BlockRef.
+func (n *nodeAPI) UnsafeHead(ctx context.Context) (*rpc.Subscription, error) {
+	return n.subscribeHeadChanges(ctx, func(change driver.HeadChange) (interface{}, bool) {
+		return change.New.UnsafeL2, change.New.UnsafeL2 != change.Old.UnsafeL2
+	})
+}
+
+// SafeHead subscribes to changes of the safe L2 head, notifying the new eth.L2BlockRef.
+func (n *nodeAPI) SafeHead(ctx context.Context) (*rpc.Subscription, error) {
+	return n.subscribeHeadChanges(ctx, func(change driver.HeadChange) (interface{}, bool) {
+		return change.New.SafeL2, change.New.SafeL2 != change.Old.SafeL2
+	})
+}
+
+// FinalizedHead subscribes to changes of the finalized L2 block, notifying the new eth.BlockID.
+func (n *nodeAPI) FinalizedHead(ctx context.Context) (*rpc.Subscription, error) {
+	return n.subscribeHeadChanges(ctx, func(change driver.HeadChange) (interface{}, bool) {
+		return change.New.FinalizedL2, change.New.FinalizedL2 != change.Old.FinalizedL2
+	})
+}
+
+// Reorg subscribes to L2 reorgs, notifying a ReorgEvent.
+func (n *nodeAPI) Reorg(ctx context.Context) (*rpc.Subscription, error) {
+	return n.subscribeHeadChanges(ctx, func(change driver.HeadChange) (interface{}, bool) {
+		return ReorgEvent{
+			OldUnsafeL2: change.Old.UnsafeL2,
+			NewUnsafeL2: change.New.UnsafeL2,
+			OldSafeL2:   change.Old.SafeL2,
+			NewSafeL2:   change.New.SafeL2,
+		}, change.Reorg
+	})
+}
+
+// subscribeHeadChanges creates a RPC subscription that notifies the result of fn for every driver head change,
+// if fn returns true.
+func (n *nodeAPI) subscribeHeadChanges(ctx context.Context, fn func(change driver.HeadChange) (interface{}, bool)) (*rpc.Subscription, error) {
+	if n.dr == nil {
+		return nil, errors.New("cannot subscribe to head changes, rollup node is not driving any engine")
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	// buffer changes, the driver drops head changes while the buffer is full
+	changes := make(chan driver.HeadChange, 10)
+	sub := n.dr.SubscribeHeadChanges(changes)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case change := <-changes:
+				if v, ok := fn(change); ok {
+					if err := notifier.Notify(rpcSub.ID, v); err != nil {
+						n.log.Warn("failed to notify head change", "id", rpcSub.ID, "err", err)
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
 }
 
 type BatchBundleRequest struct {
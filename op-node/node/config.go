@@ -6,6 +6,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 )
 
 type Config struct {
@@ -14,6 +15,12 @@ type Config struct {
 	L2EngineAddrs []string // Addresses of L2 Engine JSON-RPC endpoints to use (engine and eth namespace required)
 	L2NodeAddr    string   // Address of L2 User JSON-RPC endpoint to use (eth namespace required)
 
+	// L2ReferenceAddrs lists the addresses of trusted L2 User JSON-RPC endpoints to compare the safe L2 chain with.
+	// The safe L2 chain is not compared if empty.
+	L2ReferenceAddrs []string
+	// L2ReferenceHalt halts the rollup node when the safe L2 chain diverges from a reference, instead of following it
+	L2ReferenceHalt bool
+
 	// L1TrustRPC: if we trust the L1 RPC we do not have to validate L1 response contents like headers
 	// against block hashes, or cached transaction sender addresses.
 	// Thus we can sync faster at the risk of the source RPC being wrong.
@@ -24,6 +31,12 @@ type Config struct {
 	// Sequencer flag, enables sequencing
 	Sequencer bool
 
+	// SequencerThrottle configures when the sequencer throttles block production, because the batcher falls behind
+	SequencerThrottle driver.ThrottleConfig
+
+	// SequencerInclusion limits the transactions that the sequencer is forced to include per block, via the admin RPC
+	SequencerInclusion driver.InclusionConfig
+
 	// P2PSigner will be used for signing off on published content
 	// if the node is sequencing and if the p2p stack is enabled
 	P2PSigner p2p.SignerSetup
@@ -32,6 +45,8 @@ type Config struct {
 
 	P2P p2p.SetupP2P
 
+	Metrics MetricsConfig
+
 	// Optional
 	Tracer Tracer
 }
@@ -39,6 +54,74 @@ type Config struct {
 type RPCConfig struct {
 	ListenAddr string
 	ListenPort int
+
+	// CORS lists the allowed cross-origin domains of the HTTP server, and the allowed origins of WebSocket connections.
+	CORS []string
+	// VHosts lists the virtual hostnames the HTTP and WebSocket servers accept requests from.
+	VHosts []string
+
+	// HTTPModules lists the API namespaces to serve over HTTP. All public APIs are served if empty.
+	HTTPModules []string
+
+	// WSEnabled enables the WebSocket server.
+	WSEnabled bool
+	// WSListenPort is the WebSocket listening port, on the same listening address as HTTP.
+	// If zero or equal to ListenPort, WebSocket connections are served on the HTTP port.
+	WSListenPort int
+	// WSModules lists the API namespaces to serve over WebSocket. All public APIs are served if empty.
+	WSModules []string
+
+	// IPCPath is the path of the Unix IPC socket to serve on. IPC is disabled if empty.
+	IPCPath string
+	// IPCModules lists the API namespaces to serve over IPC. All public APIs are served if empty.
+	IPCModules []string
+
+	// AdminJWTSecret is the secret to authenticate requests to the admin API with. The admin API is disabled if nil.
+	AdminJWTSecret []byte
+	// AdminListenPort is the HTTP listening port of the admin API, on the same listening address as HTTP.
+	// The admin API is only served on this port, and requests must be authenticated with a JWT token.
+	AdminListenPort int
+}
+
+// Check verifies that the given RPC configuration makes sense
+func (cfg *RPCConfig) Check() error {
+	if cfg.ListenPort < 0 || cfg.ListenPort > 0xffff {
+		return fmt.Errorf("invalid RPC port: %d", cfg.ListenPort)
+	}
+	if cfg.WSListenPort < 0 || cfg.WSListenPort > 0xffff {
+		return fmt.Errorf("invalid RPC WebSocket port: %d", cfg.WSListenPort)
+	}
+	if cfg.AdminJWTSecret != nil {
+		if len(cfg.AdminJWTSecret) != 32 {
+			return fmt.Errorf("invalid admin RPC JWT secret length: %d, expected 32 bytes", len(cfg.AdminJWTSecret))
+		}
+		if cfg.AdminListenPort < 0 || cfg.AdminListenPort > 0xffff {
+			return fmt.Errorf("invalid admin RPC port: %d", cfg.AdminListenPort)
+		}
+	}
+	return nil
+}
+
+// wsSharesHTTPPort returns true if WebSocket connections are served on the same listener as HTTP requests.
+func (cfg *RPCConfig) wsSharesHTTPPort() bool {
+	return cfg.WSListenPort == 0 || cfg.WSListenPort == cfg.ListenPort
+}
+
+type MetricsConfig struct {
+	Enabled    bool
+	ListenAddr string
+	ListenPort int
+}
+
+// Check verifies that the given metrics configuration makes sense
+func (cfg *MetricsConfig) Check() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.ListenPort < 0 || cfg.ListenPort > 0xffff {
+		return fmt.Errorf("invalid metrics port: %d", cfg.ListenPort)
+	}
+	return nil
 }
 
 // Check verifies that the given configuration makes sense
@@ -46,6 +129,18 @@ func (cfg *Config) Check() error {
 	if err := cfg.Rollup.Check(); err != nil {
 		return fmt.Errorf("rollup config error: %v", err)
 	}
+	if err := cfg.RPC.Check(); err != nil {
+		return fmt.Errorf("rpc config error: %v", err)
+	}
+	if err := cfg.Metrics.Check(); err != nil {
+		return fmt.Errorf("metrics config error: %v", err)
+	}
+	if err := cfg.SequencerThrottle.Check(); err != nil {
+		return fmt.Errorf("sequencer throttle config error: %v", err)
+	}
+	if cfg.L2ReferenceHalt && len(cfg.L2ReferenceAddrs) == 0 {
+		return fmt.Errorf("cannot halt on divergence from the L2 references, no L2 references are configured")
+	}
 	if cfg.P2P != nil {
 		if err := cfg.P2P.Check(); err != nil {
 			return fmt.Errorf("p2p config error: %v", err)
@@ -2,6 +2,7 @@ package node
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -41,7 +42,12 @@ func (cfg *LogConfig) Check() error {
 
 // NewLogger creates a logger based on the supplied configuration
 func (cfg *LogConfig) NewLogger() log.Logger {
-	handler := log.StreamHandler(os.Stdout, format(cfg.Format, cfg.Color))
+	return cfg.NewLoggerTo(os.Stdout)
+}
+
+// NewLoggerTo creates a logger that writes to w, for tools that write their own output to stdout.
+func (cfg *LogConfig) NewLoggerTo(w io.Writer) log.Logger {
+	handler := log.StreamHandler(w, format(cfg.Format, cfg.Color))
 	handler = log.SyncHandler(handler)
 	log.LvlFilterHandler(level(cfg.Level), handler)
 	logger := log.New()
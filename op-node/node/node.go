@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/l1"
 	"github.com/ethereum-optimism/optimism/op-node/l2"
+	"github.com/ethereum-optimism/optimism/op-node/metrics"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 
 	"github.com/ethereum/go-ethereum"
@@ -36,6 +38,7 @@ type OpNode struct {
 	server     *rpcServer            // RPC server hosting the rollup-node API
 	p2pNode    *p2p.NodeP2P          // P2P node functionality
 	p2pSigner  p2p.Signer            // p2p gogssip application messages will be signed with this signer
+	metrics    *metrics.Metrics      // metrics of the node, only served if enabled
 	tracer     Tracer                // tracer to get events for testing/debugging
 
 	// some resources cannot be stopped directly, like the p2p gossipsub router (not our design),
@@ -47,6 +50,9 @@ type OpNode struct {
 // The OpNode handles incoming gossip
 var _ p2p.GossipIn = (*OpNode)(nil)
 
+// The OpNode shares its sync status with peers
+var _ p2p.StatusSource = (*OpNode)(nil)
+
 func dialRPCClientWithBackoff(ctx context.Context, log log.Logger, addr string) (*rpc.Client, error) {
 	bOff := backoff.Exponential()
 	var ret *rpc.Client
@@ -75,6 +81,7 @@ func New(ctx context.Context, cfg *Config, log log.Logger, snapshotLog log.Logge
 	n := &OpNode{
 		log:        log,
 		appVersion: appVersion,
+		metrics:    metrics.NewMetrics(),
 	}
 	// not a context leak, gossipsub is closed with a context.
 	n.resourcesCtx, n.resourcesClose = context.WithCancel(context.Background())
@@ -110,6 +117,7 @@ func (n *OpNode) init(ctx context.Context, cfg *Config, snapshotLog log.Logger)
 	if err := n.initRPCServer(ctx, cfg); err != nil {
 		return err
 	}
+	n.initMetricsServer(cfg)
 	return nil
 }
 
@@ -166,20 +174,52 @@ func (n *OpNode) AttachEngine(ctx context.Context, cfg *Config, addr string, sna
 
 	// TODO: we may need to authenticate the connection with L2
 	// backend.SetHeader()
-	client, err := l2.NewSource(l2Node, &cfg.Rollup.Genesis, engLog)
+	client, err := l2.NewSource(l2Node, &cfg.Rollup.Genesis, engLog, l2.DefaultConfig(&cfg.Rollup))
+	if err != nil {
+		l2Node.Close()
+		return err
+	}
+
+	checker, err := n.newChecker(ctx, cfg, l2Node, engLog)
 	if err != nil {
 		l2Node.Close()
 		return err
 	}
 
 	snap := snapshotLog.New("engine_addr", addr)
-	engine := driver.NewDriver(cfg.Rollup, client, n.l1Source, n, engLog, snap, cfg.Sequencer)
+	engine := driver.NewDriver(cfg.Rollup, client, n.l1Source, n, n.metrics, engLog, snap, cfg.Sequencer, cfg.SequencerThrottle, cfg.SequencerInclusion, checker)
 
 	n.l2Nodes = append(n.l2Nodes, l2Node)
 	n.l2Engines = append(n.l2Engines, engine)
 	return nil
 }
 
+// newChecker creates a checker that compares the safe L2 chain of the engine with the configured L2 references.
+// It returns nil if there are no references. The reference clients are closed with the L2 nodes.
+func (n *OpNode) newChecker(ctx context.Context, cfg *Config, l2Node *rpc.Client, log log.Logger) (*driver.Checker, error) {
+	if len(cfg.L2ReferenceAddrs) == 0 {
+		return nil, nil
+	}
+	local, err := l2.NewReadOnlySource(l2Node, &cfg.Rollup.Genesis, log)
+	if err != nil {
+		return nil, err
+	}
+	var refs []driver.Reference
+	for i, addr := range cfg.L2ReferenceAddrs {
+		refNode, err := dialRPCClientWithBackoff(ctx, log, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial L2 reference %d (%s): %w", i, addr, err)
+		}
+		n.l2Nodes = append(n.l2Nodes, refNode)
+		client, err := l2.NewReadOnlySource(refNode, &cfg.Rollup.Genesis, log)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, driver.Reference{Name: addr, Client: client})
+	}
+	return driver.NewChecker(local, refs, cfg.L2ReferenceHalt, n.metrics, log.New("checker", "references")), nil
+}
+
 func (n *OpNode) initL2(ctx context.Context, cfg *Config, snapshotLog log.Logger) error {
 	for i, addr := range cfg.L2EngineAddrs {
 		if err := n.AttachEngine(ctx, cfg, addr, snapshotLog); err != nil {
@@ -200,13 +240,22 @@ func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 	if err != nil {
 		return err
 	}
-	n.server, err = newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, client, n.log, n.appVersion)
+	// The safe and finalized block labels are resolved with the driver of the first engine.
+	var dr driverClient
+	if len(n.l2Engines) > 0 {
+		dr = n.l2Engines[0]
+	}
+	n.server, err = newRPCServer(ctx, &cfg.RPC, &cfg.Rollup, client, dr, n.log, n.appVersion)
 	if err != nil {
 		return err
 	}
 	if n.p2pNode != nil {
 		n.server.EnableP2P(p2p.NewP2PAPIBackend(n.p2pNode, n.log))
 	}
+	// Transactions can only be queued for inclusion by the sequencer, and are included by the first engine.
+	if cfg.Sequencer && len(n.l2Engines) > 0 {
+		n.server.EnableAdmin(newAdminAPI(n.l2Engines[0], n.log.New("rpc", "admin")))
+	}
 	n.log.Info("Starting JSON-RPC server")
 	if err := n.server.Start(); err != nil {
 		return fmt.Errorf("unable to start RPC server: %w", err)
@@ -214,15 +263,28 @@ func (n *OpNode) initRPCServer(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
+func (n *OpNode) initMetricsServer(cfg *Config) {
+	if !cfg.Metrics.Enabled {
+		return
+	}
+	n.log.Info("Starting metrics server", "addr", cfg.Metrics.ListenAddr, "port", cfg.Metrics.ListenPort)
+	go func() {
+		if err := n.metrics.Serve(n.resourcesCtx, cfg.Metrics.ListenAddr, cfg.Metrics.ListenPort); err != nil {
+			n.log.Error("metrics server failed", "err", err)
+		}
+	}()
+}
+
 func (n *OpNode) initP2P(ctx context.Context, cfg *Config) error {
 	if cfg.P2P != nil {
-		p2pNode, err := p2p.NewNodeP2P(n.resourcesCtx, &cfg.Rollup, n.log, cfg.P2P, n)
+		p2pNode, err := p2p.NewNodeP2P(n.resourcesCtx, &cfg.Rollup, n.log, cfg.P2P, n, n, n.metrics)
 		if err != nil {
 			return err
 		}
 		n.p2pNode = p2pNode
 		if n.p2pNode.Dv5Udp() != nil {
 			go n.p2pNode.DiscoveryProcess(n.resourcesCtx, n.log, &cfg.Rollup, cfg.P2P.TargetPeers())
+			go n.p2pNode.ENRUpdateProcess(n.resourcesCtx, n.log, cfg.P2P.Advertised())
 		}
 	}
 	return nil
@@ -315,6 +377,26 @@ func (n *OpNode) OnUnsafeL2Payload(ctx context.Context, from peer.ID, payload *l
 	return nil
 }
 
+// L2Heads returns the L2 heads of the first engine, to share with peers.
+func (n *OpNode) L2Heads(ctx context.Context) (unsafe eth.BlockID, safe eth.BlockID, finalized eth.BlockID, err error) {
+	n.l2Lock.Lock()
+	if len(n.l2Engines) == 0 {
+		n.l2Lock.Unlock()
+		return eth.BlockID{}, eth.BlockID{}, eth.BlockID{}, errors.New("no L2 engine attached")
+	}
+	eng := n.l2Engines[0]
+	n.l2Lock.Unlock()
+	status, err := eng.SyncStatus(ctx)
+	if err != nil {
+		return eth.BlockID{}, eth.BlockID{}, eth.BlockID{}, err
+	}
+	return status.UnsafeL2.ID(), status.SafeL2.ID(), status.FinalizedL2, nil
+}
+
+func (n *OpNode) AppVersion() string {
+	return n.appVersion
+}
+
 func (n *OpNode) P2P() p2p.Node {
 	return n.p2pNode
 }
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 
-	"github.com/ethereum-optimism/optimism/op-node/l2"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -20,21 +22,30 @@ import (
 // TODO(inphi): add metrics
 
 type rpcServer struct {
-	endpoint   string
+	cfg        *RPCConfig
 	apis       []rpc.API
-	httpServer *http.Server
 	appVersion string
-	listenAddr net.Addr
 	log        log.Logger
-	l2.Source
+
+	// each transport has its own rpc server, since each transport serves its own selection of APIs
+	servers []*rpc.Server
+
+	httpServer *http.Server
+	listenAddr net.Addr
+
+	wsServer     *http.Server // nil if WebSocket is disabled or shares the HTTP server
+	wsListenAddr net.Addr
+
+	adminServer     *http.Server // nil if the admin API is disabled
+	adminListenAddr net.Addr
+
+	ipcListener net.Listener
 }
 
-func newRPCServer(ctx context.Context, rpcCfg *RPCConfig, rollupCfg *rollup.Config, l2Client l2EthClient, log log.Logger, appVersion string) (*rpcServer, error) {
-	api := newNodeAPI(rollupCfg, l2Client, log.New("rpc", "node"))
-	// TODO: extend RPC config with options for WS, IPC and HTTP RPC connections
-	endpoint := fmt.Sprintf("%s:%d", rpcCfg.ListenAddr, rpcCfg.ListenPort)
+func newRPCServer(ctx context.Context, rpcCfg *RPCConfig, rollupCfg *rollup.Config, l2Client l2EthClient, dr driverClient, log log.Logger, appVersion string) (*rpcServer, error) {
+	api := newNodeAPI(rollupCfg, l2Client, dr, log.New("rpc", "node"))
 	r := &rpcServer{
-		endpoint: endpoint,
+		cfg: rpcCfg,
 		apis: []rpc.API{{
 			Namespace:     "optimism",
 			Service:       api,
@@ -57,9 +68,37 @@ func (s *rpcServer) EnableP2P(backend *p2p.APIBackend) {
 	})
 }
 
-func (s *rpcServer) Start() error {
+// EnableAdmin serves the admin API, if an admin JWT secret is configured to authenticate requests with.
+func (s *rpcServer) EnableAdmin(api *adminAPI) {
+	s.apis = append(s.apis, rpc.API{
+		Namespace:     NamespaceAdmin,
+		Service:       api,
+		Public:        false,
+		Authenticated: true,
+	})
+}
+
+// newServer creates a rpc server that serves the APIs of the given modules, or all public APIs if no modules are specified.
+// Authenticated APIs are only served by authenticated servers, and unauthenticated APIs only by unauthenticated servers.
+// Authenticated servers serve all authenticated APIs, including the non-public ones.
+func (s *rpcServer) newServer(modules []string, authenticated bool) (*rpc.Server, error) {
+	var apis []rpc.API
+	for _, api := range s.apis {
+		if api.Authenticated == authenticated {
+			apis = append(apis, api)
+		}
+	}
 	srv := rpc.NewServer()
-	if err := node.RegisterApis(s.apis, nil, srv, true); err != nil {
+	if err := node.RegisterApis(apis, modules, srv, authenticated); err != nil {
+		return nil, err
+	}
+	s.servers = append(s.servers, srv)
+	return srv, nil
+}
+
+func (s *rpcServer) Start() error {
+	httpSrv, err := s.newServer(s.cfg.HTTPModules, false)
+	if err != nil {
 		return err
 	}
 
@@ -67,37 +106,185 @@ func (s *rpcServer) Start() error {
 	// other services to connect to the opnode. VHosts in particular
 	// defaults to localhost, which will prevent containers from
 	// calling into the opnode without an "invalid host" error.
-	nodeHandler := node.NewHTTPHandlerStack(srv, []string{"*"}, []string{"*"}, nil)
+	cors, vhosts := s.cfg.CORS, s.cfg.VHosts
+	if len(cors) == 0 {
+		cors = []string{"*"}
+	}
+	if len(vhosts) == 0 {
+		vhosts = []string{"*"}
+	}
+	nodeHandler := node.NewHTTPHandlerStack(httpSrv, cors, vhosts, nil)
+
+	var wsHandler http.Handler
+	if s.cfg.WSEnabled {
+		wsSrv, err := s.newServer(s.cfg.WSModules, false)
+		if err != nil {
+			return err
+		}
+		wsHandler = newVHostsHandler(vhosts, node.NewWSHandlerStack(wsSrv.WebsocketHandler(cors), nil))
+	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/", nodeHandler)
+	if wsHandler != nil && s.cfg.wsSharesHTTPPort() {
+		mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isWebsocket(r) {
+				wsHandler.ServeHTTP(w, r)
+				return
+			}
+			nodeHandler.ServeHTTP(w, r)
+		}))
+	} else {
+		mux.Handle("/", nodeHandler)
+	}
 	mux.HandleFunc("/healthz", healthzHandler(s.appVersion))
 
-	listener, err := net.Listen("tcp", s.endpoint)
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.cfg.ListenAddr, s.cfg.ListenPort))
 	if err != nil {
 		return err
 	}
 	s.listenAddr = listener.Addr()
-
 	s.httpServer = &http.Server{Handler: mux}
+	s.serve(s.httpServer, listener, "http")
+
+	if wsHandler != nil {
+		if s.cfg.wsSharesHTTPPort() {
+			s.wsListenAddr = s.listenAddr
+		} else {
+			wsListener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.cfg.ListenAddr, s.cfg.WSListenPort))
+			if err != nil {
+				return err
+			}
+			s.wsListenAddr = wsListener.Addr()
+			s.wsServer = &http.Server{Handler: wsHandler}
+			s.serve(s.wsServer, wsListener, "ws")
+		}
+	}
+
+	if s.cfg.AdminJWTSecret != nil {
+		adminSrv, err := s.newServer(nil, true)
+		if err != nil {
+			return err
+		}
+		adminListener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.cfg.ListenAddr, s.cfg.AdminListenPort))
+		if err != nil {
+			return err
+		}
+		s.adminListenAddr = adminListener.Addr()
+		s.adminServer = &http.Server{Handler: node.NewHTTPHandlerStack(adminSrv, cors, vhosts, s.cfg.AdminJWTSecret)}
+		s.serve(s.adminServer, adminListener, "admin")
+	}
+
+	if s.cfg.IPCPath != "" {
+		ipcSrv, err := s.newServer(s.cfg.IPCModules, false)
+		if err != nil {
+			return err
+		}
+		listener, err := ipcListen(s.cfg.IPCPath)
+		if err != nil {
+			return fmt.Errorf("failed to open IPC endpoint %q: %w", s.cfg.IPCPath, err)
+		}
+		s.ipcListener = listener
+		go func() {
+			_ = ipcSrv.ServeListener(listener)
+		}()
+	}
+	return nil
+}
+
+func (s *rpcServer) serve(srv *http.Server, listener net.Listener, name string) {
 	go func() {
-		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) { // todo improve error handling
-			s.log.Error("http server failed", "err", err)
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) { // todo improve error handling
+			s.log.Error("rpc server failed", "transport", name, "err", err)
 		}
 	}()
-	return nil
 }
 
 func (r *rpcServer) Stop() {
-	_ = r.httpServer.Shutdown(context.Background())
+	if r.httpServer != nil {
+		_ = r.httpServer.Shutdown(context.Background())
+	}
+	if r.wsServer != nil {
+		_ = r.wsServer.Shutdown(context.Background())
+	}
+	if r.adminServer != nil {
+		_ = r.adminServer.Shutdown(context.Background())
+	}
+	if r.ipcListener != nil {
+		_ = r.ipcListener.Close()
+	}
+	// stopping the servers closes open WebSocket and IPC connections, which are not closed by a http shutdown
+	for _, srv := range r.servers {
+		srv.Stop()
+	}
 }
 
 func (r *rpcServer) Addr() net.Addr {
 	return r.listenAddr
 }
 
+// WSAddr returns the address WebSocket connections are served on, or nil if WebSocket is disabled.
+func (r *rpcServer) WSAddr() net.Addr {
+	return r.wsListenAddr
+}
+
+// AdminAddr returns the address the admin API is served on, or nil if the admin API is disabled.
+func (r *rpcServer) AdminAddr() net.Addr {
+	return r.adminListenAddr
+}
+
 func healthzHandler(appVersion string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(appVersion))
 	}
 }
+
+// isWebsocket checks the header of a http request for a websocket upgrade request.
+func isWebsocket(r *http.Request) bool {
+	return strings.ToLower(r.Header.Get("Upgrade")) == "websocket" &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// newVHostsHandler only passes requests with an allowed virtual hostname on to the next handler.
+// Requests to IP addresses are always allowed, since DNS rebinding attacks do not apply to them.
+func newVHostsHandler(vhosts []string, next http.Handler) http.Handler {
+	allowed := make(map[string]struct{})
+	for _, v := range vhosts {
+		allowed[strings.ToLower(v)] = struct{}{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := allowed["*"]; ok || r.Host == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host // no port in the host
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := allowed[strings.ToLower(host)]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "invalid host specified", http.StatusForbidden)
+	})
+}
+
+// ipcListen opens a Unix socket at the given path, replacing any stale socket file.
+func ipcListen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0751); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
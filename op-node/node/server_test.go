@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/version"
 
@@ -14,13 +18,19 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/eth"
 	"github.com/ethereum-optimism/optimism/op-node/predeploy"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/ethereum-optimism/optimism/op-node/l2"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOutputAtBlock(t *testing.T) {
@@ -81,11 +91,27 @@ func TestOutputAtBlock(t *testing.T) {
 		// ignore other rollup config info in this test
 	}
 
+	ref := eth.L2BlockRef{
+		Hash:       header.Hash(),
+		Number:     header.Number.Uint64(),
+		ParentHash: header.ParentHash,
+		Time:       header.Time,
+		L1Origin:   eth.BlockID{Hash: common.Hash{0xaa}, Number: 1234},
+	}
+
 	l2Client := &mockL2Client{}
-	l2Client.mock.On("GetBlockHeader", "latest").Return(&header)
-	l2Client.mock.On("GetProof", predeploy.WithdrawalContractAddress, "latest").Return(&result)
+	l2Client.mock.On("GetBlockHeader", "0xdcdc89").Return(&header)
+	l2Client.mock.On("GetProof", predeploy.WithdrawalContractAddress, "0xdcdc89").Return(&result)
+	l2Client.mock.On("L2BlockRefByHash", header.Hash()).Return(ref)
 
-	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, log, "0.0")
+	dr := &mockDriverClient{}
+	dr.mock.On("SyncStatus").Return(&driver.SyncStatus{
+		UnsafeL2:    ref,
+		SafeL2:      ref,
+		FinalizedL2: eth.BlockID{Hash: common.Hash{0xcc}, Number: header.Number.Uint64()},
+	})
+
+	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, dr, log, "0.0")
 	assert.NoError(t, err)
 	assert.NoError(t, server.Start())
 	defer server.Stop()
@@ -93,10 +119,43 @@ func TestOutputAtBlock(t *testing.T) {
 	client, err := dialRPCClientWithBackoff(context.Background(), log, "http://"+server.Addr().String())
 	assert.NoError(t, err)
 
-	var out []l2.Bytes32
-	err = client.CallContext(context.Background(), &out, "optimism_outputAtBlock", "latest")
+	for _, tag := range []string{"latest", "safe", "0xdcdc89"} {
+		var out *OutputResponse
+		err = client.CallContext(context.Background(), &out, "optimism_outputAtBlock", tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, ref, out.BlockRef, tag)
+		assert.Equal(t, l2.Bytes32{}, out.Version, tag)
+	}
+
+	// the finalized block does not match the block of the engine
+	var out *OutputResponse
+	err = client.CallContext(context.Background(), &out, "optimism_outputAtBlock", "finalized")
+	assert.Error(t, err)
+
+	err = client.CallContext(context.Background(), &out, "optimism_outputAtBlock", "pending")
+	assert.Error(t, err)
+
+	var outWithProof *l2.OutputWithProof
+	err = client.CallContext(context.Background(), &outWithProof, "optimism_outputWithProof", "safe")
+	assert.NoError(t, err)
+	assert.Equal(t, ref, outWithProof.BlockRef)
+	assert.Equal(t, header.Hash(), outWithProof.Header.Hash())
+	assert.Equal(t, result.StorageHash, outWithProof.MessagePasserProof.StorageHash)
+	assert.Equal(t, l2.ComputeL2OutputRoot(outWithProof.Version, header.Hash(), header.Root, result.StorageHash), outWithProof.OutputRoot)
+
+	// without a driver, the latest label falls back to the latest block of the engine
+	l2Client.mock.On("GetBlockHeader", "latest").Return(&header)
+	noDriverServer, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, nil, log, "0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, noDriverServer.Start())
+	defer noDriverServer.Stop()
+	noDriverClient, err := dialRPCClientWithBackoff(context.Background(), log, "http://"+noDriverServer.Addr().String())
 	assert.NoError(t, err)
-	assert.Len(t, out, 2)
+	err = noDriverClient.CallContext(context.Background(), &out, "optimism_outputAtBlock", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, ref, out.BlockRef)
+	err = noDriverClient.CallContext(context.Background(), &out, "optimism_outputAtBlock", "safe")
+	assert.Error(t, err, "safe label needs a driver")
 	l2Client.mock.AssertExpectations(t)
 }
 
@@ -110,7 +169,7 @@ func TestVersion(t *testing.T) {
 	rollupCfg := &rollup.Config{
 		// ignore other rollup config info in this test
 	}
-	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, log, "0.0")
+	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, l2Client, nil, log, "0.0")
 	assert.NoError(t, err)
 	assert.NoError(t, server.Start())
 	defer server.Stop()
@@ -124,6 +183,47 @@ func TestVersion(t *testing.T) {
 	assert.Equal(t, version.Version+"-"+version.Meta, out)
 }
 
+func TestCheckerStatus(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	rpcCfg := &RPCConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+	}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	status := &driver.CheckerStatus{
+		Checked: eth.L2BlockRef{Hash: common.Hash{0xaa}, Number: 10},
+		Divergence: &driver.Divergence{
+			Reference:       "http://reference",
+			Local:           eth.L2BlockRef{Hash: common.Hash{0xaa}, Number: 10},
+			LocalStateRoot:  common.Hash{0x1},
+			Remote:          eth.L2BlockRef{Hash: common.Hash{0xbb}, Number: 10},
+			RemoteStateRoot: common.Hash{0x2},
+		},
+		Halted: true,
+	}
+	dr := &mockDriverClient{}
+	dr.mock.On("CheckerStatus").Return(status).Once()
+	dr.mock.On("CheckerStatus").Return((*driver.CheckerStatus)(nil))
+
+	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, &mockL2Client{}, dr, log, "0.0")
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	client, err := rpc.Dial("http://" + server.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	var out *driver.CheckerStatus
+	require.NoError(t, client.CallContext(context.Background(), &out, "optimism_checkerStatus"))
+	require.Equal(t, status, out)
+
+	// the driver does not check against any references
+	require.Error(t, client.CallContext(context.Background(), &out, "optimism_checkerStatus"))
+}
+
 type mockL2Client struct {
 	mock mock.Mock
 }
@@ -147,3 +247,250 @@ func (c *mockL2Client) GetBlockHeader(ctx context.Context, blockTag string) (*ty
 func (c *mockL2Client) GetProof(ctx context.Context, address common.Address, blockTag string) (*l2.AccountResult, error) {
 	return c.mock.MethodCalled("GetProof", address, blockTag).Get(0).(*l2.AccountResult), nil
 }
+
+type mockDriverClient struct {
+	mock mock.Mock
+	feed event.Feed
+}
+
+func (c *mockDriverClient) SyncStatus(ctx context.Context) (*driver.SyncStatus, error) {
+	return c.mock.MethodCalled("SyncStatus").Get(0).(*driver.SyncStatus), nil
+}
+
+func (c *mockDriverClient) SubscribeHeadChanges(ch chan<- driver.HeadChange) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func (c *mockDriverClient) CheckerStatus() *driver.CheckerStatus {
+	return c.mock.MethodCalled("CheckerStatus").Get(0).(*driver.CheckerStatus)
+}
+
+func TestRPCTransports(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	expected := version.Version + "-" + version.Meta
+
+	t.Run("shared port", func(t *testing.T) {
+		rpcCfg := &RPCConfig{
+			ListenAddr: "localhost",
+			ListenPort: 0,
+			WSEnabled:  true,
+			IPCPath:    filepath.Join(t.TempDir(), "opnode.ipc"),
+		}
+		server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, &mockL2Client{}, nil, log, "0.0")
+		require.NoError(t, err)
+		require.NoError(t, server.Start())
+		defer server.Stop()
+		require.Equal(t, server.Addr(), server.WSAddr())
+
+		for _, addr := range []string{
+			"http://" + server.Addr().String(),
+			"ws://" + server.WSAddr().String(),
+			rpcCfg.IPCPath,
+		} {
+			client, err := rpc.Dial(addr)
+			require.NoError(t, err)
+			var out string
+			require.NoError(t, client.CallContext(context.Background(), &out, "optimism_version"), addr)
+			require.Equal(t, expected, out)
+			client.Close()
+		}
+	})
+
+	t.Run("separate port and modules", func(t *testing.T) {
+		rpcCfg := &RPCConfig{
+			ListenAddr:   "localhost",
+			ListenPort:   0,
+			WSEnabled:    true,
+			WSListenPort: freePort(t),
+			WSModules:    []string{"optimism"},
+			HTTPModules:  []string{"other"},
+			VHosts:       []string{"example.com"},
+		}
+		server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, &mockL2Client{}, nil, log, "0.0")
+		require.NoError(t, err)
+		require.NoError(t, server.Start())
+		defer server.Stop()
+		require.NotEqual(t, server.Addr(), server.WSAddr())
+
+		wsClient, err := rpc.Dial("ws://" + server.WSAddr().String())
+		require.NoError(t, err)
+		defer wsClient.Close()
+		var out string
+		require.NoError(t, wsClient.CallContext(context.Background(), &out, "optimism_version"))
+		require.Equal(t, expected, out)
+
+		// the optimism namespace is not enabled on HTTP
+		httpClient, err := rpc.Dial("http://" + server.Addr().String())
+		require.NoError(t, err)
+		defer httpClient.Close()
+		require.Error(t, httpClient.CallContext(context.Background(), &out, "optimism_version"))
+
+		// only the configured virtual hosts are served, requests to IP addresses are always allowed.
+		_, err = rpc.Dial(strings.Replace("ws://"+server.WSAddr().String(), "127.0.0.1", "localhost", 1))
+		require.Error(t, err)
+	})
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestHeadSubscriptions(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	rpcCfg := &RPCConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+		WSEnabled:  true,
+	}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	dr := &mockDriverClient{}
+	server, err := newRPCServer(context.Background(), rpcCfg, rollupCfg, &mockL2Client{}, dr, log, "0.0")
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	client, err := rpc.Dial("ws://" + server.WSAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	unsafeHeads := make(chan eth.L2BlockRef, 10)
+	unsafeSub, err := client.Subscribe(ctx, "optimism", unsafeHeads, "unsafeHead")
+	require.NoError(t, err)
+	defer unsafeSub.Unsubscribe()
+	safeHeads := make(chan eth.L2BlockRef, 10)
+	safeSub, err := client.Subscribe(ctx, "optimism", safeHeads, "safeHead")
+	require.NoError(t, err)
+	defer safeSub.Unsubscribe()
+	reorgs := make(chan ReorgEvent, 10)
+	reorgSub, err := client.Subscribe(ctx, "optimism", reorgs, "reorg")
+	require.NoError(t, err)
+	defer reorgSub.Unsubscribe()
+
+	// wait for the server to subscribe to the driver feed
+	for dr.feed.Send(driver.HeadChange{}) < 3 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	a := eth.L2BlockRef{Hash: common.Hash{0xa}, Number: 1}
+	b := eth.L2BlockRef{Hash: common.Hash{0xb}, Number: 2, ParentHash: a.Hash}
+	b2 := eth.L2BlockRef{Hash: common.Hash{0xb, 2}, Number: 2, ParentHash: a.Hash}
+	dr.feed.Send(driver.HeadChange{
+		Old: driver.SyncStatus{UnsafeL2: a, SafeL2: a},
+		New: driver.SyncStatus{UnsafeL2: b, SafeL2: a},
+	})
+	dr.feed.Send(driver.HeadChange{
+		Old:   driver.SyncStatus{UnsafeL2: b, SafeL2: a},
+		New:   driver.SyncStatus{UnsafeL2: b2, SafeL2: b2},
+		Reorg: true,
+	})
+
+	recv := func(ch <-chan eth.L2BlockRef) eth.L2BlockRef {
+		select {
+		case v := <-ch:
+			return v
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for head change")
+			return eth.L2BlockRef{}
+		}
+	}
+	require.Equal(t, b, recv(unsafeHeads))
+	require.Equal(t, b2, recv(unsafeHeads))
+	require.Equal(t, b2, recv(safeHeads))
+	select {
+	case ev := <-reorgs:
+		require.Equal(t, ReorgEvent{OldUnsafeL2: b, NewUnsafeL2: b2, OldSafeL2: a, NewSafeL2: b2}, ev)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for reorg")
+	}
+}
+
+type mockAdminDriver struct {
+	queued []*types.Transaction
+}
+
+func (d *mockAdminDriver) QueueTransaction(tx *types.Transaction) error {
+	d.queued = append(d.queued, tx)
+	return nil
+}
+
+func (d *mockAdminDriver) InclusionStatus(hash common.Hash) *driver.InclusionStatus {
+	for _, tx := range d.queued {
+		if tx.Hash() == hash {
+			return &driver.InclusionStatus{State: driver.InclusionQueued}
+		}
+	}
+	return nil
+}
+
+func TestAdminRPC(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	secret := make([]byte, 32)
+	secret[0] = 0x42
+	rpcCfg := &RPCConfig{
+		ListenAddr:      "localhost",
+		ListenPort:      0,
+		AdminJWTSecret:  secret,
+		AdminListenPort: 0,
+	}
+	require.NoError(t, rpcCfg.Check())
+	server, err := newRPCServer(context.Background(), rpcCfg, &rollup.Config{}, &mockL2Client{}, nil, log, "0.0")
+	require.NoError(t, err)
+	dr := &mockAdminDriver{}
+	server.EnableAdmin(newAdminAPI(dr, log))
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21_000})
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	// the admin API is not served on the public port
+	client, err := rpc.Dial("http://" + server.Addr().String())
+	require.NoError(t, err)
+	var hash common.Hash
+	err = client.Call(&hash, "admin_queueTransaction", hexutil.Bytes(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+	client.Close()
+
+	adminClient, err := rpc.Dial("http://" + server.AdminAddr().String())
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	// requests must be authenticated
+	err = adminClient.Call(&hash, "admin_queueTransaction", hexutil.Bytes(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing token")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iat": time.Now().Unix()}).SignedString([]byte("wrong secret"))
+	require.NoError(t, err)
+	adminClient.SetHeader("Authorization", "Bearer "+token)
+	err = adminClient.Call(&hash, "admin_queueTransaction", hexutil.Bytes(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature is invalid")
+
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iat": time.Now().Unix()}).SignedString(secret)
+	require.NoError(t, err)
+	adminClient.SetHeader("Authorization", "Bearer "+token)
+	require.NoError(t, adminClient.Call(&hash, "admin_queueTransaction", hexutil.Bytes(data)))
+	require.Equal(t, tx.Hash(), hash)
+	require.Len(t, dr.queued, 1)
+
+	var status driver.InclusionStatus
+	require.NoError(t, adminClient.Call(&status, "admin_transactionStatus", hash))
+	require.Equal(t, driver.InclusionQueued, status.State)
+	require.Error(t, adminClient.Call(&status, "admin_transactionStatus", common.Hash{0x1}))
+
+	// the public API is not served on the admin port
+	var version string
+	require.Error(t, adminClient.Call(&version, "optimism_version"))
+}
@@ -0,0 +1,317 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/conngater"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const bansNamespace = "/optimism/bans"
+
+// Ban is a block of a peer, IP address or IP subnet that expires.
+// Exactly one of the peer, IP or subnet is set.
+type Ban struct {
+	Peer   peer.ID `json:"peer,omitempty"`
+	IP     net.IP  `json:"ip,omitempty"`
+	Subnet string  `json:"subnet,omitempty"` // CIDR notation
+	Expiry uint64  `json:"expiry"`           // unix timestamp
+}
+
+func (b *Ban) key() datastore.Key {
+	switch {
+	case b.Peer != "":
+		return datastore.NewKey("/peer/" + b.Peer.String())
+	case b.IP != nil:
+		return datastore.NewKey("/addr/" + b.IP.String())
+	default:
+		return datastore.NewKey("/subnet/" + b.Subnet)
+	}
+}
+
+// ExpiryConnectionGater extends the basic connection gater with bans that expire.
+// Bans are persisted in the datastore, next to the rules of the basic connection gater,
+// and are lifted when they expire, also if the node was restarted in the meantime.
+// Blocking or unblocking a banned peer, IP or subnet makes it permanent or lifts the ban.
+type ExpiryConnectionGater struct {
+	*conngater.BasicConnectionGater
+
+	ds  datastore.Datastore // nil if not persisted
+	now func() time.Time
+
+	mu         sync.Mutex
+	bans       map[datastore.Key]*Ban
+	nextExpiry uint64
+}
+
+var _ ConnectionGater = (*ExpiryConnectionGater)(nil)
+
+// NewExpiryConnectionGater creates a connection gater, and loads the blocks and bans from the datastore, if not nil.
+func NewExpiryConnectionGater(store datastore.Batching) (*ExpiryConnectionGater, error) {
+	return newExpiryConnectionGater(store, time.Now)
+}
+
+func newExpiryConnectionGater(store datastore.Batching, now func() time.Time) (*ExpiryConnectionGater, error) {
+	basic, err := conngater.NewBasicConnectionGater(store)
+	if err != nil {
+		return nil, err
+	}
+	g := &ExpiryConnectionGater{
+		BasicConnectionGater: basic,
+		now:                  now,
+		bans:                 make(map[datastore.Key]*Ban),
+	}
+	if store == nil {
+		return g, nil
+	}
+	g.ds = namespace.Wrap(store, datastore.NewKey(bansNamespace))
+	res, err := g.ds.Query(context.Background(), query.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bans: %w", err)
+	}
+	defer res.Close()
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, fmt.Errorf("failed to read ban: %w", r.Error)
+		}
+		var b Ban
+		if err := json.Unmarshal(r.Value, &b); err != nil {
+			return nil, fmt.Errorf("failed to decode ban %q: %w", r.Key, err)
+		}
+		// the ban may not have been blocked if the node stopped in between, blocking again is a no-op otherwise.
+		if err := g.block(&b); err != nil {
+			return nil, fmt.Errorf("failed to restore ban %q: %w", r.Key, err)
+		}
+		g.bans[b.key()] = &b
+	}
+	g.checkExpiry()
+	return g, nil
+}
+
+func (g *ExpiryConnectionGater) block(b *Ban) error {
+	switch {
+	case b.Peer != "":
+		return g.BasicConnectionGater.BlockPeer(b.Peer)
+	case b.IP != nil:
+		return g.BasicConnectionGater.BlockAddr(b.IP)
+	default:
+		_, ipnet, err := net.ParseCIDR(b.Subnet)
+		if err != nil {
+			return err
+		}
+		return g.BasicConnectionGater.BlockSubnet(ipnet)
+	}
+}
+
+func (g *ExpiryConnectionGater) unblock(b *Ban) error {
+	switch {
+	case b.Peer != "":
+		return g.BasicConnectionGater.UnblockPeer(b.Peer)
+	case b.IP != nil:
+		return g.BasicConnectionGater.UnblockAddr(b.IP)
+	default:
+		_, ipnet, err := net.ParseCIDR(b.Subnet)
+		if err != nil {
+			return err
+		}
+		return g.BasicConnectionGater.UnblockSubnet(ipnet)
+	}
+}
+
+func (g *ExpiryConnectionGater) ban(b *Ban, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("invalid ban duration: %s", duration)
+	}
+	b.Expiry = uint64(g.now().Add(duration).Unix())
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ds != nil {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		if err := g.ds.Put(context.Background(), b.key(), data); err != nil {
+			return fmt.Errorf("failed to persist ban: %w", err)
+		}
+	}
+	if err := g.block(b); err != nil {
+		return err
+	}
+	g.bans[b.key()] = b
+	if g.nextExpiry == 0 || b.Expiry < g.nextExpiry {
+		g.nextExpiry = b.Expiry
+	}
+	return nil
+}
+
+// forget drops the ban, if any, without changing the block. The lock must be held.
+func (g *ExpiryConnectionGater) forget(key datastore.Key) error {
+	if _, ok := g.bans[key]; !ok {
+		return nil
+	}
+	delete(g.bans, key)
+	if g.ds != nil {
+		return g.ds.Delete(context.Background(), key)
+	}
+	return nil
+}
+
+// expire lifts the bans that expired. The lock must be held.
+func (g *ExpiryConnectionGater) expire() {
+	now := uint64(g.now().Unix())
+	if g.nextExpiry == 0 && len(g.bans) == 0 || now < g.nextExpiry {
+		return
+	}
+	g.nextExpiry = 0
+	for key, b := range g.bans {
+		if b.Expiry > now {
+			if g.nextExpiry == 0 || b.Expiry < g.nextExpiry {
+				g.nextExpiry = b.Expiry
+			}
+			continue
+		}
+		// errors are from the datastore only, the ban is lifted regardless, and retried on the next restart if not removed.
+		_ = g.unblock(b)
+		_ = g.forget(key)
+	}
+}
+
+func (g *ExpiryConnectionGater) checkExpiry() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.expire()
+}
+
+// BanPeer blocks the peer until the ban expires.
+func (g *ExpiryConnectionGater) BanPeer(p peer.ID, duration time.Duration) error {
+	return g.ban(&Ban{Peer: p}, duration)
+}
+
+// BanAddr blocks the IP address until the ban expires.
+func (g *ExpiryConnectionGater) BanAddr(ip net.IP, duration time.Duration) error {
+	return g.ban(&Ban{IP: ip}, duration)
+}
+
+// BanSubnet blocks the IP subnet until the ban expires.
+func (g *ExpiryConnectionGater) BanSubnet(ipnet *net.IPNet, duration time.Duration) error {
+	return g.ban(&Ban{Subnet: ipnet.String()}, duration)
+}
+
+// ListBans returns the bans that did not expire yet, the first to expire first.
+func (g *ExpiryConnectionGater) ListBans() []*Ban {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.expire()
+	out := make([]*Ban, 0, len(g.bans))
+	for _, b := range g.bans {
+		cp := *b
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Expiry != out[j].Expiry {
+			return out[i].Expiry < out[j].Expiry
+		}
+		return out[i].key().String() < out[j].key().String()
+	})
+	return out
+}
+
+func (g *ExpiryConnectionGater) BlockPeer(p peer.ID) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{Peer: p}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.BlockPeer(p)
+}
+
+func (g *ExpiryConnectionGater) UnblockPeer(p peer.ID) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{Peer: p}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.UnblockPeer(p)
+}
+
+func (g *ExpiryConnectionGater) BlockAddr(ip net.IP) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{IP: ip}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.BlockAddr(ip)
+}
+
+func (g *ExpiryConnectionGater) UnblockAddr(ip net.IP) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{IP: ip}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.UnblockAddr(ip)
+}
+
+func (g *ExpiryConnectionGater) BlockSubnet(ipnet *net.IPNet) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{Subnet: ipnet.String()}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.BlockSubnet(ipnet)
+}
+
+func (g *ExpiryConnectionGater) UnblockSubnet(ipnet *net.IPNet) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.forget((&Ban{Subnet: ipnet.String()}).key()); err != nil {
+		return err
+	}
+	return g.BasicConnectionGater.UnblockSubnet(ipnet)
+}
+
+func (g *ExpiryConnectionGater) ListBlockedPeers() []peer.ID {
+	g.checkExpiry()
+	return g.BasicConnectionGater.ListBlockedPeers()
+}
+
+func (g *ExpiryConnectionGater) ListBlockedAddrs() []net.IP {
+	g.checkExpiry()
+	return g.BasicConnectionGater.ListBlockedAddrs()
+}
+
+func (g *ExpiryConnectionGater) ListBlockedSubnets() []*net.IPNet {
+	g.checkExpiry()
+	return g.BasicConnectionGater.ListBlockedSubnets()
+}
+
+func (g *ExpiryConnectionGater) InterceptPeerDial(p peer.ID) (allow bool) {
+	g.checkExpiry()
+	return g.BasicConnectionGater.InterceptPeerDial(p)
+}
+
+func (g *ExpiryConnectionGater) InterceptAddrDial(id peer.ID, a ma.Multiaddr) (allow bool) {
+	g.checkExpiry()
+	return g.BasicConnectionGater.InterceptAddrDial(id, a)
+}
+
+func (g *ExpiryConnectionGater) InterceptAccept(cma network.ConnMultiaddrs) (allow bool) {
+	g.checkExpiry()
+	return g.BasicConnectionGater.InterceptAccept(cma)
+}
+
+func (g *ExpiryConnectionGater) InterceptSecured(dir network.Direction, id peer.ID, cma network.ConnMultiaddrs) (allow bool) {
+	g.checkExpiry()
+	return g.BasicConnectionGater.InterceptSecured(dir, id, cma)
+}
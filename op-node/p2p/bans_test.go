@@ -0,0 +1,78 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryConnectionGater(t *testing.T) {
+	store := sync.MutexWrap(ds.NewMapDatastore())
+	now := time.Unix(1_000_000, 0)
+	clock := func() time.Time { return now }
+
+	g, err := newExpiryConnectionGater(store, clock)
+	require.NoError(t, err)
+
+	newID := func() peer.ID {
+		_, pub, err := crypto.GenerateSecp256k1Key(rand.Reader)
+		require.NoError(t, err)
+		id, err := peer.IDFromPublicKey(pub)
+		require.NoError(t, err)
+		return id
+	}
+	a, b, c := newID(), newID(), newID()
+	ip := net.IP{10, 0, 0, 1}
+	_, subnet, err := net.ParseCIDR("10.1.0.0/16")
+	require.NoError(t, err)
+
+	require.Error(t, g.BanPeer(a, 0), "ban must have a duration")
+	require.NoError(t, g.BanPeer(a, time.Minute))
+	require.NoError(t, g.BanPeer(b, time.Hour))
+	require.NoError(t, g.BanAddr(ip, time.Minute))
+	require.NoError(t, g.BanSubnet(subnet, 2*time.Hour))
+	require.NoError(t, g.BlockPeer(c))
+	require.False(t, g.InterceptPeerDial(a))
+	require.ElementsMatch(t, []peer.ID{a, b, c}, g.ListBlockedPeers())
+
+	bans := g.ListBans()
+	require.Len(t, bans, 4)
+	require.Equal(t, uint64(now.Add(2*time.Hour).Unix()), bans[3].Expiry)
+	require.Equal(t, subnet.String(), bans[3].Subnet)
+
+	// bans are persisted, and expire while the node is stopped
+	now = now.Add(30 * time.Minute)
+	g, err = newExpiryConnectionGater(store, clock)
+	require.NoError(t, err)
+	require.True(t, g.InterceptPeerDial(a), "ban expired")
+	require.False(t, g.InterceptPeerDial(b))
+	require.False(t, g.InterceptPeerDial(c), "blocks do not expire")
+	require.Empty(t, g.ListBlockedAddrs())
+	require.Len(t, g.ListBlockedSubnets(), 1)
+	require.Len(t, g.ListBans(), 2)
+
+	// unblocking lifts the ban, blocking makes it permanent
+	require.NoError(t, g.UnblockPeer(b))
+	require.NoError(t, g.BlockSubnet(subnet))
+	require.Empty(t, g.ListBans())
+
+	// bans expire while running
+	require.NoError(t, g.BanPeer(a, time.Minute))
+	require.False(t, g.InterceptPeerDial(a))
+	now = now.Add(time.Minute)
+	require.True(t, g.InterceptPeerDial(a))
+
+	now = now.Add(24 * time.Hour)
+	g, err = newExpiryConnectionGater(store, clock)
+	require.NoError(t, err)
+	require.Empty(t, g.ListBans())
+	require.Equal(t, []peer.ID{c}, g.ListBlockedPeers())
+	require.Len(t, g.ListBlockedSubnets(), 1)
+}
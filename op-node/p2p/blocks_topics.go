@@ -0,0 +1,230 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// blocksTopicOverlap is how long a version of the blocks topic is joined before it activates,
+// and how long the previous version is kept after the switch.
+// Blocks older than 20 seconds are rejected, there is no use in staying on the old topic for much longer.
+const blocksTopicOverlap = time.Minute
+
+// blocksTopicsCheckInterval is the interval at which the joined versions of the blocks topic are updated.
+const blocksTopicsCheckInterval = time.Second
+
+// blocksFormat is the encoding of the execution payload in the messages of a version of the blocks topic.
+// The signature of the sequencer covers the encoded payload.
+type blocksFormat struct {
+	decode func(data []byte) (*l2.ExecutionPayload, error)
+	encode func(w io.Writer, payload *l2.ExecutionPayload) error
+}
+
+var sszBlocksFormat = blocksFormat{
+	decode: func(data []byte) (*l2.ExecutionPayload, error) {
+		var payload l2.ExecutionPayload
+		if err := payload.UnmarshalSSZ(uint32(len(data)), bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		return &payload, nil
+	},
+	encode: func(w io.Writer, payload *l2.ExecutionPayload) error {
+		_, err := payload.MarshalSSZ(w)
+		return err
+	},
+}
+
+// blocksFormats are the supported versions of the blocks topic.
+// A new block format is introduced by adding a version here, and scheduling its activation in the rollup config.
+var blocksFormats = map[uint64]blocksFormat{
+	0: sszBlocksFormat,
+}
+
+func blocksTopic(cfg *rollup.Config, version uint64) string {
+	return fmt.Sprintf("/optimism/%s/%d/blocks", cfg.L2ChainID.String(), version)
+}
+
+// blocksTopicWindow is the time range in which a version of the blocks topic is joined.
+type blocksTopicWindow struct {
+	version uint64
+	start   uint64 // unix timestamp, inclusive
+	end     uint64 // unix timestamp, exclusive, or the max uint64 for the latest version
+}
+
+func (w *blocksTopicWindow) contains(now uint64) bool {
+	return w.start <= now && now < w.end
+}
+
+// blocksTopicWindows returns the windows of all versions of the blocks topic:
+// from the overlap before the activation, until the overlap after the activation of the next version.
+func blocksTopicWindows(cfg *rollup.Config) []blocksTopicWindow {
+	overlap := uint64(blocksTopicOverlap / time.Second)
+	versions := append([]rollup.P2PBlocksTopic{{Version: 0}}, cfg.P2PBlocksTopics...)
+	out := make([]blocksTopicWindow, len(versions))
+	for i, v := range versions {
+		out[i] = blocksTopicWindow{version: v.Version, end: math.MaxUint64}
+		if v.ActivationTime > overlap {
+			out[i].start = v.ActivationTime - overlap
+		}
+		if i+1 < len(versions) {
+			out[i].end = versions[i+1].ActivationTime + overlap
+		}
+	}
+	return out
+}
+
+// allBlocksTopics returns the names of all versions of the blocks topic.
+func allBlocksTopics(cfg *rollup.Config) []string {
+	var out []string
+	for _, w := range blocksTopicWindows(cfg) {
+		out = append(out, blocksTopic(cfg, w.version))
+	}
+	return out
+}
+
+// checkBlocksTopics checks that all scheduled versions of the blocks topic are supported.
+func checkBlocksTopics(cfg *rollup.Config) error {
+	for _, w := range blocksTopicWindows(cfg) {
+		if _, ok := blocksFormats[w.version]; !ok {
+			return fmt.Errorf("unsupported blocks topic version %d", w.version)
+		}
+	}
+	return nil
+}
+
+// joinedBlocksTopic is a version of the blocks topic that the node joined and subscribed to.
+type joinedBlocksTopic struct {
+	name   string
+	format blocksFormat
+	topic  *pubsub.Topic
+	events *pubsub.TopicEventHandler
+	sub    *pubsub.Subscription
+	cancel context.CancelFunc
+	// eventsDone is closed when the events are not logged anymore, and the event handler is cancelled
+	eventsDone chan struct{}
+}
+
+// joinBlocksTopic registers the validator of the version of the blocks topic, joins and subscribes to it.
+func (p *publisher) joinBlocksTopic(version uint64) (*joinedBlocksTopic, error) {
+	name := blocksTopic(p.cfg, version)
+	validator, err := buildBlocksValidator(p.log, p.cfg, version, p.evidence)
+	if err != nil {
+		return nil, err
+	}
+	err = p.ps.RegisterTopicValidator(name,
+		logValidationResult(p.self, "validated block", p.log, p.stats, validator),
+		pubsub.WithValidatorTimeout(3*time.Second),
+		pubsub.WithValidatorConcurrency(4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register blocks gossip topic: %v", err)
+	}
+	t := &joinedBlocksTopic{name: name, format: blocksFormats[version]}
+	ctx, cancel := context.WithCancel(p.ctx)
+	t.cancel = cancel
+	t.topic, err = p.ps.Join(name)
+	if err != nil {
+		p.leaveBlocksTopic(t)
+		return nil, fmt.Errorf("failed to join blocks gossip topic: %v", err)
+	}
+	t.events, err = t.topic.EventHandler()
+	if err != nil {
+		p.leaveBlocksTopic(t)
+		return nil, fmt.Errorf("failed to create blocks gossip topic handler: %v", err)
+	}
+	t.eventsDone = make(chan struct{})
+	go func() {
+		defer close(t.eventsDone)
+		LogTopicEvents(ctx, p.log.New("topic", "blocks", "version", version), t.events)
+	}()
+
+	if err := t.topic.SetScoreParams(BuildBlocksTopicScoreParams(p.cfg)); err != nil {
+		p.leaveBlocksTopic(t)
+		return nil, fmt.Errorf("failed to set blocks gossip topic score params: %v", err)
+	}
+
+	t.sub, err = t.topic.Subscribe()
+	if err != nil {
+		p.leaveBlocksTopic(t)
+		return nil, fmt.Errorf("failed to subscribe to blocks gossip topic: %v", err)
+	}
+	subscriber := MakeSubscriber(p.log, BlocksHandler(p.gossipIn.OnUnsafeL2Payload))
+	go subscriber(ctx, t.sub)
+	return t, nil
+}
+
+// leaveBlocksTopic unsubscribes from the version of the blocks topic, leaves it, and removes its validator.
+func (p *publisher) leaveBlocksTopic(t *joinedBlocksTopic) error {
+	t.cancel()
+	// the event handler is cancelled by the events logger, and must be before the topic can be closed
+	if t.eventsDone != nil {
+		<-t.eventsDone
+	}
+	if t.sub != nil {
+		t.sub.Cancel()
+	}
+	var result error
+	if t.topic != nil {
+		if err := t.topic.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close blocks gossip topic %q: %w", t.name, err))
+		}
+	}
+	if err := p.ps.UnregisterTopicValidator(t.name); err != nil {
+		result = multierror.Append(result, fmt.Errorf("failed to unregister blocks gossip topic validator %q: %w", t.name, err))
+	}
+	return result
+}
+
+// updateBlocksTopics joins the versions of the blocks topic that are within their window at the given time,
+// and leaves the versions that are not.
+func (p *publisher) updateBlocksTopics(now uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var result error
+	for _, w := range blocksTopicWindows(p.cfg) {
+		t, joined := p.topics[w.version]
+		if active := w.contains(now); active && !joined {
+			t, err := p.joinBlocksTopic(w.version)
+			if err != nil {
+				result = multierror.Append(result, fmt.Errorf("failed to join blocks topic version %d: %w", w.version, err))
+				continue
+			}
+			p.topics[w.version] = t
+			p.log.Info("joined blocks topic", "version", w.version, "topic", t.name)
+		} else if !active && joined {
+			delete(p.topics, w.version)
+			if err := p.leaveBlocksTopic(t); err != nil {
+				result = multierror.Append(result, err)
+				continue
+			}
+			p.log.Info("left blocks topic", "version", w.version, "topic", t.name)
+		}
+	}
+	return result
+}
+
+// blocksTopicsLoop keeps the joined versions of the blocks topic up to date with the activation schedule.
+func (p *publisher) blocksTopicsLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(blocksTopicsCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.updateBlocksTopics(uint64(time.Now().Unix())); err != nil {
+				p.log.Error("failed to switch blocks topics", "err", err)
+			}
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
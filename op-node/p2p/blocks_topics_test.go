@@ -0,0 +1,162 @@
+package p2p
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestBlocksTopicWindows(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901)}
+	require.Equal(t, []blocksTopicWindow{{version: 0, start: 0, end: math.MaxUint64}}, blocksTopicWindows(cfg))
+
+	cfg.P2PBlocksTopics = []rollup.P2PBlocksTopic{{Version: 1, ActivationTime: 30}, {Version: 2, ActivationTime: 1000}}
+	require.Equal(t, []blocksTopicWindow{
+		{version: 0, start: 0, end: 90},
+		{version: 1, start: 0, end: 1060},
+		{version: 2, start: 940, end: math.MaxUint64},
+	}, blocksTopicWindows(cfg))
+	require.Equal(t, []string{"/optimism/901/0/blocks", "/optimism/901/1/blocks", "/optimism/901/2/blocks"}, allBlocksTopics(cfg))
+
+	err := checkBlocksTopics(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported blocks topic version 1")
+}
+
+func testPayload(t *testing.T, height uint64, timestamp uint64) *l2.ExecutionPayload {
+	block := types.NewBlockWithHeader(&types.Header{
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Difficulty:  common.Big0,
+		Number:      new(big.Int).SetUint64(height),
+		GasLimit:    30_000_000,
+		Time:        timestamp,
+		BaseFee:     big.NewInt(7),
+	})
+	payload, err := l2.BlockAsPayload(block)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestBlocksTopicSwitch(t *testing.T) {
+	// the new version of the topic uses the same format, only the topic is different
+	blocksFormats[1] = sszBlocksFormat
+	t.Cleanup(func() {
+		delete(blocksFormats, 1)
+	})
+
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := NewLocalSigner(priv)
+	now := uint64(time.Now().Unix())
+	activation := now + 2
+	cfg := &rollup.Config{
+		BlockTime:           2,
+		L2ChainID:           big.NewInt(901),
+		P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey),
+		P2PBlocksTopics:     []rollup.P2PBlocksTopic{{Version: 1, ActivationTime: activation}},
+	}
+
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	defer mnet.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *l2.ExecutionPayload, 10)
+	receiverIn := &mockGossipIn{OnUnsafeL2PayloadFn: func(ctx context.Context, from peer.ID, msg *l2.ExecutionPayload) error {
+		received <- msg
+		return nil
+	}}
+	var publishers []*publisher
+	var stats []*GossipStats
+	for i, h := range mnet.Hosts() {
+		logger := testlog.Logger(t, log.LvlError).New("host", i)
+		st := NewGossipStats(h.ID())
+		ps, err := NewGossipSub(ctx, h, cfg, logger, st)
+		require.NoError(t, err)
+		var gossipIn GossipIn = &mockGossipIn{}
+		if i == 1 {
+			gossipIn = receiverIn
+		}
+		p, err := newPublisher(ctx, h.ID(), ps, logger, cfg, gossipIn, nil, st)
+		require.NoError(t, err)
+		defer p.Close()
+		publishers = append(publishers, p)
+		stats = append(stats, st)
+	}
+	sender := publishers[0]
+
+	// both versions are joined during the overlap
+	for _, p := range publishers {
+		require.NoError(t, p.updateBlocksTopics(now))
+		require.Len(t, p.topics, 2)
+	}
+	require.Eventually(t, func() bool {
+		return len(sender.topics[0].topic.ListPeers()) == 1 && len(sender.topics[1].topic.ListPeers()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "receiver joins both versions")
+	require.Equal(t, []peer.ID{mnet.Hosts()[1].ID()}, sender.BlocksTopicPeers())
+
+	// blocks are published on the version that is active at the block time.
+	// The first blocks may be published before the gossip mesh is formed, so blocks are published until one arrives.
+	height := uint64(0)
+	publishUntilReceived := func(timestamp uint64) {
+		require.Eventually(t, func() bool {
+			height += 1
+			require.NoError(t, sender.PublishL2Payload(ctx, testPayload(t, height, timestamp), signer))
+			for {
+				select {
+				case payload := <-received:
+					if uint64(payload.Timestamp) == timestamp {
+						return true
+					}
+				case <-time.After(100 * time.Millisecond):
+					return false
+				}
+			}
+		}, 5*time.Second, 10*time.Millisecond, "receive block with timestamp %d", timestamp)
+	}
+	publishUntilReceived(now)
+	publishUntilReceived(activation)
+	snap := stats[1].Snapshot()
+	require.NotZero(t, snap[blocksTopic(cfg, 0)].Total.Accepted)
+	require.Empty(t, snap[blocksTopic(cfg, 0)].Total.Rejected)
+	require.NotZero(t, snap[blocksTopic(cfg, 1)].Total.Accepted)
+	require.Empty(t, snap[blocksTopic(cfg, 1)].Total.Rejected)
+
+	// the old version is left after the overlap
+	for _, p := range publishers {
+		require.NoError(t, p.updateBlocksTopics(activation+uint64(blocksTopicOverlap/time.Second)))
+		require.Len(t, p.topics, 1)
+		require.NotContains(t, p.ps.GetTopics(), blocksTopic(cfg, 0))
+	}
+	err = sender.PublishL2Payload(ctx, testPayload(t, height+1, now), signer)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not joined")
+
+	// blocks from before the activation are rejected on the new version
+	validator, err := buildBlocksValidator(testlog.Logger(t, log.LvlCrit), cfg, 1, nil)
+	require.NoError(t, err)
+	topic := blocksTopic(cfg, 1)
+	msg := &pubsub.Message{Message: &pb.Message{Data: signedBlockMessage(t, cfg, signer, 100, common.Hash{0x1}), Topic: &topic}}
+	res, reason := validator(ctx, "", msg)
+	require.Equal(t, pubsub.ValidationReject, res)
+	require.Equal(t, "wrong_topic_version", reason)
+}
@@ -29,12 +29,13 @@ import (
 	"github.com/libp2p/go-libp2p-core/connmgr"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	mplex "github.com/libp2p/go-libp2p-mplex"
 	noise "github.com/libp2p/go-libp2p-noise"
 	tls "github.com/libp2p/go-libp2p-tls"
 	yamux "github.com/libp2p/go-libp2p-yamux"
 	lconf "github.com/libp2p/go-libp2p/config"
-	"github.com/libp2p/go-libp2p/p2p/net/conngater"
 	cmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/urfave/cli"
@@ -46,7 +47,13 @@ type SetupP2P interface {
 	// Host creates a libp2p host service. Returns nil, nil if p2p is disabled.
 	Host(log log.Logger) (host.Host, error)
 	// Discovery creates a disc-v5 service. Returns nil, nil, nil if discovery is disabled.
-	Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort uint16) (*enode.LocalNode, *discover.UDPv5, error)
+	Discovery(log log.Logger, rollupCfg *rollup.Config, ports HostPorts) (*enode.LocalNode, *discover.UDPv5, error)
+	// Advertised returns the addresses that are explicitly configured to be advertised in the node record.
+	Advertised() AdvertisedAddrs
+	// Evidence creates the store of sequencer equivocation evidence.
+	Evidence(log log.Logger, metrics EquivocationMetrics) (*EvidenceStore, error)
+	// PeerPolicy creates the connection policy of the peer groups. Returns nil if there are no peer groups.
+	PeerPolicy(log log.Logger) *PeerPolicy
 	TargetPeers() uint
 }
 
@@ -58,6 +65,10 @@ type Config struct {
 	DisableP2P  bool
 	NoDiscovery bool
 
+	// PSK is the pre-shared key of a private network, nil for the public network.
+	// Connections are only established with peers that have the same key.
+	PSK pnet.PSK
+
 	ListenIP      net.IP
 	ListenTCPPort uint16
 
@@ -67,11 +78,24 @@ type Config struct {
 	AdvertiseIP      net.IP
 	AdvertiseTCPPort uint16
 	AdvertiseUDPPort uint16
-	Bootnodes        []*enode.Node
-	DiscoveryDB      *enode.DB
+
+	// Optional QUIC and WebSocket listeners, in addition to TCP.
+	// The advertised ports are put into the ENR, and default to the ports that are listened on.
+	EnableQUIC        bool
+	ListenQUICPort    uint16
+	AdvertiseQUICPort uint16
+	EnableWS          bool
+	ListenWSPort      uint16
+	AdvertiseWSPort   uint16
+
+	Bootnodes   []*enode.Node
+	DiscoveryDB *enode.DB
 
 	StaticPeers []core.Multiaddr
 
+	// Named groups of peers, with reserved connection slots and redialing, nil if none.
+	PeerGroups []*PeerGroup
+
 	HostMux             []lconf.MsMuxC
 	HostSecurity        []lconf.MsSecC
 	NoTransportSecurity bool
@@ -94,8 +118,17 @@ type Config struct {
 
 	ConnGater func(conf *Config) (connmgr.ConnectionGater, error)
 	ConnMngr  func(conf *Config) (connmgr.ConnManager, error)
+
+	// Limits of the resource manager
+	ResourceLimits ResourceLimits
+	// nil to disable resource management
+	ResourceMngr func(conf *Config) (network.ResourceManager, error)
+
 	// nil to disable bandwidth metrics
 	BandwidthMetrics metrics.Reporter
+
+	// Directory to persist the evidence of sequencer equivocations in, empty to only keep it in memory
+	EvidencePath string
 }
 
 type ConnectionGater interface {
@@ -118,10 +151,18 @@ type ConnectionGater interface {
 	BlockSubnet(ipnet *net.IPNet) error
 	UnblockSubnet(ipnet *net.IPNet) error
 	ListBlockedSubnets() []*net.IPNet
+
+	// BanPeer, BanAddr and BanSubnet block a peer, IP address or IP subnet until the ban expires.
+	// Note: active connections are not automatically closed.
+	BanPeer(p peer.ID, duration time.Duration) error
+	BanAddr(ip net.IP, duration time.Duration) error
+	BanSubnet(ipnet *net.IPNet, duration time.Duration) error
+	// ListBans lists the bans that did not expire yet.
+	ListBans() []*Ban
 }
 
 func DefaultConnGater(conf *Config) (connmgr.ConnectionGater, error) {
-	return conngater.NewBasicConnectionGater(conf.Store)
+	return NewExpiryConnectionGater(conf.Store)
 }
 
 func DefaultConnManager(conf *Config) (connmgr.ConnManager, error) {
@@ -160,6 +201,11 @@ func NewConfig(ctx *cli.Context) (*Config, error) {
 	}
 	conf.Priv = p
 
+	conf.PSK, err = loadPSK(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load p2p private network key: %v", err)
+	}
+
 	if err := conf.loadListenOpts(ctx); err != nil {
 		return nil, fmt.Errorf("failed to load p2p listen options: %v", err)
 	}
@@ -174,10 +220,74 @@ func NewConfig(ctx *cli.Context) (*Config, error) {
 
 	conf.ConnGater = DefaultConnGater
 	conf.ConnMngr = DefaultConnManager
+	conf.ResourceMngr = DefaultResourceManager
+
+	return conf, nil
+}
+
+// NewDiscoveryConfig creates the config of a discovery-only node, like a bootnode, that does not run a libp2p host.
+func NewDiscoveryConfig(ctx *cli.Context) (*Config, error) {
+	conf := &Config{DisableP2P: true}
+
+	p, err := loadNetworkPrivKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load p2p priv key: %v", err)
+	}
+	conf.Priv = p
+
+	if err := conf.loadListenOpts(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load p2p listen options: %v", err)
+	}
 
+	if err := conf.loadDiscoveryOpts(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load p2p discovery options: %v", err)
+	}
+	if conf.NoDiscovery {
+		return nil, errors.New("discovery-only node cannot run with discovery disabled")
+	}
 	return conf, nil
 }
 
+// NewCrawlConfig creates the config of a network crawler. The crawler runs discovery with a new identity every run,
+// and does not persist the records it finds, to not affect the view of the network of a regular node.
+func NewCrawlConfig(ctx *cli.Context) (*Config, error) {
+	p, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate p2p priv key: %v", err)
+	}
+	conf := &Config{DisableP2P: true, Priv: (*ecdsa.PrivateKey)((p).(*crypto.Secp256k1PrivateKey))}
+
+	if err := conf.loadListenOpts(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load p2p listen options: %v", err)
+	}
+
+	conf.DiscoveryDB, err = enode.OpenDB("") // in-memory
+	if err != nil {
+		return nil, fmt.Errorf("failed to open discovery db: %v", err)
+	}
+	conf.Bootnodes, err = parseBootnodes(ctx.GlobalString(flags.Bootnodes.Name))
+	if err != nil {
+		conf.DiscoveryDB.Close()
+		return nil, err
+	}
+	if len(conf.Bootnodes) == 0 {
+		conf.DiscoveryDB.Close()
+		return nil, errors.New("crawler requires bootnodes to start from")
+	}
+	return conf, nil
+}
+
+func (conf *Config) Evidence(log log.Logger, metrics EquivocationMetrics) (*EvidenceStore, error) {
+	return NewEvidenceStore(log, conf.EvidencePath, metrics)
+}
+
+func (conf *Config) PeerPolicy(log log.Logger) *PeerPolicy {
+	if len(conf.PeerGroups) == 0 {
+		return nil
+	}
+	return NewPeerPolicy(log, conf.PeerGroups, conf.PeersHi)
+}
+
 func (conf *Config) TargetPeers() uint {
 	return conf.PeersLo
 }
@@ -199,11 +309,23 @@ func (conf *Config) loadListenOpts(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("bad listen UDP port: %v", err)
 	}
+	conf.EnableQUIC = ctx.GlobalBool(flags.QUIC.Name)
+	conf.ListenQUICPort, err = validatePort(ctx.GlobalUint(flags.ListenQUICPort.Name))
+	if err != nil {
+		return fmt.Errorf("bad listen QUIC port: %v", err)
+	}
+	conf.EnableWS = ctx.GlobalBool(flags.WebSocket.Name)
+	conf.ListenWSPort, err = validatePort(ctx.GlobalUint(flags.ListenWSPort.Name))
+	if err != nil {
+		return fmt.Errorf("bad listen WebSocket port: %v", err)
+	}
 	return nil
 }
 
 func (conf *Config) loadDiscoveryOpts(ctx *cli.Context) error {
-	if ctx.GlobalBool(flags.NoDiscovery.Name) {
+	// Private networks are statically peered:
+	// their node records would otherwise be shared in the public discv5 DHT.
+	if ctx.GlobalBool(flags.NoDiscovery.Name) || conf.PSK != nil {
 		conf.NoDiscovery = true
 	}
 
@@ -216,6 +338,14 @@ func (conf *Config) loadDiscoveryOpts(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("bad advertised UDP port: %v", err)
 	}
+	conf.AdvertiseQUICPort, err = validatePort(ctx.GlobalUint(flags.AdvertiseQUICPort.Name))
+	if err != nil {
+		return fmt.Errorf("bad advertised QUIC port: %v", err)
+	}
+	conf.AdvertiseWSPort, err = validatePort(ctx.GlobalUint(flags.AdvertiseWSPort.Name))
+	if err != nil {
+		return fmt.Errorf("bad advertised WebSocket port: %v", err)
+	}
 	adIP := ctx.GlobalString(flags.AdvertiseIP.Name)
 	if adIP != "" { // optional
 		conf.AdvertiseIP = net.ParseIP(adIP)
@@ -236,7 +366,18 @@ func (conf *Config) loadDiscoveryOpts(ctx *cli.Context) error {
 		return fmt.Errorf("failed to open discovery db: %v", err)
 	}
 
-	records := strings.Split(ctx.GlobalString(flags.Bootnodes.Name), ",")
+	conf.Bootnodes, err = parseBootnodes(ctx.GlobalString(flags.Bootnodes.Name))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseBootnodes parses a comma-separated list of base64-format ENRs.
+func parseBootnodes(v string) ([]*enode.Node, error) {
+	var out []*enode.Node
+	records := strings.Split(v, ",")
 	for i, recordB64 := range records {
 		recordB64 = strings.TrimSpace(recordB64)
 		if recordB64 == "" { // ignore empty records
@@ -244,12 +385,11 @@ func (conf *Config) loadDiscoveryOpts(ctx *cli.Context) error {
 		}
 		nodeRecord, err := enode.Parse(enode.ValidSchemes, recordB64)
 		if err != nil {
-			return fmt.Errorf("bootnode record %d (of %d) is invalid: %q err: %v", i, len(records), recordB64, err)
+			return nil, fmt.Errorf("bootnode record %d (of %d) is invalid: %q err: %v", i, len(records), recordB64, err)
 		}
-		conf.Bootnodes = append(conf.Bootnodes, nodeRecord)
+		out = append(out, nodeRecord)
 	}
-
-	return nil
+	return out, nil
 }
 
 func yamuxC() (lconf.MsMuxC, error) {
@@ -299,6 +439,14 @@ func (conf *Config) loadLibp2pOpts(ctx *cli.Context) error {
 		conf.StaticPeers = append(conf.StaticPeers, a)
 	}
 
+	if path := ctx.GlobalString(flags.PeerGroups.Name); path != "" {
+		groups, err := LoadPeerGroups(path)
+		if err != nil {
+			return err
+		}
+		conf.PeerGroups = groups
+	}
+
 	for _, v := range strings.Split(ctx.GlobalString(flags.HostMux.Name), ",") {
 		v = strings.ToLower(strings.TrimSpace(v))
 		var mc lconf.MsMuxC
@@ -349,6 +497,16 @@ func (conf *Config) loadLibp2pOpts(ctx *cli.Context) error {
 	conf.TimeoutNegotiation = ctx.GlobalDuration(flags.TimeoutNegotiation.Name)
 	conf.TimeoutAccept = ctx.GlobalDuration(flags.TimeoutAccept.Name)
 	conf.TimeoutDial = ctx.GlobalDuration(flags.TimeoutDial.Name)
+	conf.ResourceLimits = ResourceLimits{
+		MaxMemory:              int64(ctx.GlobalUint64(flags.ResourcesMemory.Name)) << 20,
+		MaxConns:               ctx.GlobalInt(flags.ResourcesConns.Name),
+		MaxStreams:             ctx.GlobalInt(flags.ResourcesStreams.Name),
+		PeerMaxMemory:          int64(ctx.GlobalUint64(flags.ResourcesPeerMemory.Name)) << 20,
+		PeerMaxConns:           ctx.GlobalInt(flags.ResourcesPeerConns.Name),
+		PeerMaxStreams:         ctx.GlobalInt(flags.ResourcesPeerStreams.Name),
+		ProtocolMaxStreams:     ctx.GlobalInt(flags.ResourcesProtocolStreams.Name),
+		ProtocolPeerMaxStreams: ctx.GlobalInt(flags.ResourcesProtocolPeerStreams.Name),
+	}
 
 	peerstorePath := ctx.GlobalString(flags.PeerstorePath.Name)
 	if peerstorePath == "" {
@@ -367,6 +525,11 @@ func (conf *Config) loadLibp2pOpts(ctx *cli.Context) error {
 	}
 	conf.Store = store
 
+	conf.EvidencePath = ctx.GlobalString(flags.EvidencePath.Name)
+	if conf.EvidencePath == "memory" {
+		conf.EvidencePath = ""
+	}
+
 	return nil
 }
 
@@ -405,6 +568,35 @@ func loadNetworkPrivKey(ctx *cli.Context) (*ecdsa.PrivateKey, error) {
 	}
 }
 
+// loadPSK loads the pre-shared key of the private network, if any. Returns nil if on the public network.
+func loadPSK(ctx *cli.Context) (pnet.PSK, error) {
+	raw := ctx.GlobalString(flags.PSKRaw.Name)
+	path := ctx.GlobalString(flags.PSKPath.Name)
+	if raw != "" && path != "" {
+		return nil, errors.New("private network key can be configured either by file or raw, not both")
+	}
+	if raw != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+		if err != nil {
+			return nil, errors.New("private network key is not formatted in hex chars")
+		}
+		return b, nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open private network key file: %v", err)
+	}
+	defer f.Close()
+	psk, err := pnet.DecodeV1PSK(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private network key file: %v", err)
+	}
+	return psk, nil
+}
+
 func parsePriv(data string) (*ecdsa.PrivateKey, error) {
 	if len(data) > 2 && data[:2] == "0x" {
 		data = data[2:]
@@ -428,6 +620,17 @@ func (conf *Config) Check() error {
 	if conf.Store == nil {
 		return errors.New("p2p requires a persistent or in-memory peerstore, but found none")
 	}
+	if conf.PSK != nil {
+		if len(conf.PSK) != 32 {
+			return fmt.Errorf("private network key must be 32 bytes, got %d", len(conf.PSK))
+		}
+		if !conf.NoDiscovery {
+			return errors.New("discovery must be disabled on a private network")
+		}
+		if conf.EnableQUIC {
+			return errors.New("the QUIC transport does not support private networks")
+		}
+	}
 	if !conf.NoDiscovery {
 		if conf.DiscoveryDB == nil {
 			return errors.New("discovery requires a persistent or in-memory discv5 db, but found none")
@@ -436,11 +639,19 @@ func (conf *Config) Check() error {
 	if conf.PeersLo == 0 || conf.PeersHi == 0 || conf.PeersLo > conf.PeersHi {
 		return fmt.Errorf("peers lo/hi tides are invalid: %d, %d", conf.PeersLo, conf.PeersHi)
 	}
+	if err := CheckPeerGroups(conf.PeerGroups, conf.PeersHi); err != nil {
+		return fmt.Errorf("invalid peer groups: %w", err)
+	}
 	if conf.ConnMngr == nil {
 		return errors.New("need a connection manager")
 	}
 	if conf.ConnGater == nil {
 		return errors.New("need a connection gater")
 	}
+	if conf.ResourceMngr != nil {
+		if err := conf.ResourceLimits.Check(); err != nil {
+			return fmt.Errorf("invalid resource limits: %w", err)
+		}
+	}
 	return nil
 }
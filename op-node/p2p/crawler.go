@@ -0,0 +1,226 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-peerstore/pstoremem"
+	lconf "github.com/libp2p/go-libp2p/config"
+	tcp "github.com/libp2p/go-tcp-transport"
+	ws "github.com/libp2p/go-ws-transport"
+	madns "github.com/multiformats/go-multiaddr-dns"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// CrawlerUserAgent is the user-agent the crawler identifies itself with to the nodes it dials.
+const CrawlerUserAgent = "optimism-crawler"
+
+// CrawlHost creates a libp2p host to identify the crawled nodes with.
+// The host does not listen, and supports all the security and multiplexing protocols of the rollup node,
+// to identify nodes regardless of their preferences.
+// Only TCP and WebSocket addresses are dialed.
+func (conf *Config) CrawlHost() (host.Host, error) {
+	ps, err := pstoremem.NewPeerstore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open peerstore: %v", err)
+	}
+	tcpTransport, err := lconf.TransportConstructor(tcp.NewTCPTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TCP transport: %v", err)
+	}
+	wsTransport, err := lconf.TransportConstructor(ws.New)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WebSocket transport: %v", err)
+	}
+	yamux, err := yamuxC()
+	if err != nil {
+		return nil, err
+	}
+	mplex, err := mplexC()
+	if err != nil {
+		return nil, err
+	}
+	noise, err := noiseC()
+	if err != nil {
+		return nil, err
+	}
+	tls, err := tlsC()
+	if err != nil {
+		return nil, err
+	}
+	p2pConf := &lconf.Config{
+		UserAgent:          CrawlerUserAgent,
+		PeerKey:            (*crypto.Secp256k1PrivateKey)(conf.Priv),
+		Transports:         []lconf.TptC{tcpTransport, wsTransport},
+		Muxers:             []lconf.MsMuxC{yamux, mplex},
+		SecurityTransports: []lconf.MsSecC{noise, tls},
+		Peerstore:          ps,
+		MultiaddrResolver:  madns.DefaultResolver,
+	}
+	return p2pConf.NewNode()
+}
+
+// CrawlResult describes a rollup node found by the crawler, and what it identified itself as.
+type CrawlResult struct {
+	NodeID string `json:"nodeID"`
+	PeerID string `json:"peerID,omitempty"`
+	ENR    string `json:"enr"`
+	Seq    uint64 `json:"seq"`
+
+	// Addrs are the libp2p addresses advertised in the node record.
+	Addrs []string `json:"addrs"`
+
+	// Reachable is true if the node could be dialed and identified.
+	Reachable       bool     `json:"reachable"`
+	AgentVersion    string   `json:"agentVersion,omitempty"`
+	ProtocolVersion string   `json:"protocolVersion,omitempty"`
+	Protocols       []string `json:"protocols,omitempty"`
+
+	// Error is the reason the node is not reachable, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Crawler walks the discv5 DHT to find the rollup nodes of a chain, and identifies them with libp2p.
+type Crawler struct {
+	log    log.Logger
+	udpV5  *discover.UDPv5
+	host   host.Host
+	filter func(node *enode.Node) bool
+}
+
+func NewCrawler(log log.Logger, cfg *rollup.Config, udpV5 *discover.UDPv5, h host.Host) *Crawler {
+	return &Crawler{
+		log:    log,
+		udpV5:  udpV5,
+		host:   h,
+		filter: FilterEnodes(log, cfg),
+	}
+}
+
+// Walk randomly walks the DHT until the context is done, and returns the rollup nodes that it found.
+// Nodes are deduplicated by ID, only the latest record of each node is kept.
+func (c *Crawler) Walk(ctx context.Context) []*enode.Node {
+	found := make(map[enode.ID]*enode.Node)
+	add := func(n *enode.Node) {
+		if prev, ok := found[n.ID()]; !ok || prev.Seq() < n.Seq() {
+			if !ok {
+				c.log.Debug("found rollup node", "node", n.ID(), "count", len(found)+1)
+			}
+			found[n.ID()] = n
+		}
+	}
+
+	iter := enode.Filter(c.udpV5.RandomNodes(), c.filter)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		// closing unblocks the iteration
+		iter.Close()
+	}()
+	for iter.Next() {
+		add(iter.Node())
+	}
+	close(done)
+
+	// The random walk may not have returned some of the nodes that made it into the table
+	for _, n := range c.udpV5.AllNodes() {
+		if c.filter(n) {
+			add(n)
+		}
+	}
+
+	out := make([]*enode.Node, 0, len(found))
+	for _, n := range found {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID().String() < out[j].ID().String()
+	})
+	return out
+}
+
+// Identify dials the given nodes with the given number of parallel workers, and identifies them.
+// Each node is disconnected from after identification. Results are in the same order as the nodes.
+func (c *Crawler) Identify(ctx context.Context, nodes []*enode.Node, workers int, timeout time.Duration) []CrawlResult {
+	results := make([]CrawlResult, len(nodes))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = c.identify(ctx, nodes[i], timeout)
+			}
+		}()
+	}
+	for i := range nodes {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+func (c *Crawler) identify(ctx context.Context, n *enode.Node, timeout time.Duration) CrawlResult {
+	res := CrawlResult{
+		NodeID: n.ID().String(),
+		ENR:    n.String(),
+		Seq:    n.Seq(),
+		Addrs:  []string{},
+	}
+	info, err := enrToAddrInfo(n)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.PeerID = info.ID.String()
+	for _, addr := range info.Addrs {
+		res.Addrs = append(res.Addrs, addr.String())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Connect waits for identification to complete
+	if err := c.host.Connect(ctx, *info); err != nil {
+		c.log.Debug("failed to identify node", "node", n.ID(), "peer", info.ID, "err", err)
+		res.Error = err.Error()
+		return res
+	}
+	defer c.disconnect(info.ID)
+	res.Reachable = true
+	ps := c.host.Peerstore()
+	if v, err := ps.Get(info.ID, "AgentVersion"); err == nil {
+		res.AgentVersion, _ = v.(string)
+	}
+	if v, err := ps.Get(info.ID, "ProtocolVersion"); err == nil {
+		res.ProtocolVersion, _ = v.(string)
+	}
+	if protocols, err := ps.GetProtocols(info.ID); err == nil {
+		sort.Strings(protocols)
+		res.Protocols = protocols
+	}
+	c.log.Debug("identified node", "node", n.ID(), "peer", info.ID, "agent", res.AgentVersion)
+	return res
+}
+
+func (c *Crawler) disconnect(id peer.ID) {
+	if err := c.host.Network().ClosePeer(id); err != nil {
+		c.log.Debug("failed to disconnect from identified node", "peer", id, "err", err)
+	}
+	// forget about the peer, the crawler does not need to remember its addresses or protocols
+	c.host.Peerstore().RemovePeer(id)
+}
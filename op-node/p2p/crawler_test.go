@@ -0,0 +1,85 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	lconf "github.com/libp2p/go-libp2p/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestCrawler(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlError)
+	rollupCfg := &rollup.Config{L2ChainID: big.NewInt(901)}
+
+	// A rollup node, with discovery enabled, for the crawler to start from
+	noise, err := noiseC()
+	require.NoError(t, err)
+	conf := TestingConfig(t)
+	conf.NoDiscovery = false
+	conf.HostSecurity = []lconf.MsSecC{noise}
+	conf.NoTransportSecurity = false
+	conf.AdvertiseIP = net.IP{127, 0, 0, 1}
+	conf.Store = sync.MutexWrap(ds.NewMapDatastore())
+	conf.DiscoveryDB, err = enode.OpenDB("")
+	require.NoError(t, err)
+	node, err := NewNodeP2P(context.Background(), rollupCfg, logger.New("host", "A"), conf, &mockGossipIn{}, nil, nil)
+	require.NoError(t, err)
+	defer node.Close()
+
+	p, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	crawlDB, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer crawlDB.Close()
+	crawlConf := &Config{
+		DisableP2P:  true,
+		Priv:        (*ecdsa.PrivateKey)((p).(*crypto.Secp256k1PrivateKey)),
+		ListenIP:    net.IP{127, 0, 0, 1},
+		DiscoveryDB: crawlDB,
+		Bootnodes:   []*enode.Node{node.dv5Local.Node()},
+	}
+	_, udpV5, err := crawlConf.Discovery(logger.New("host", "crawler"), rollupCfg, HostPorts{})
+	require.NoError(t, err)
+	defer udpV5.Close()
+	h, err := crawlConf.CrawlHost()
+	require.NoError(t, err)
+	defer h.Close()
+
+	crawler := NewCrawler(logger, rollupCfg, udpV5, h)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	nodes := crawler.Walk(ctx)
+	require.Len(t, nodes, 1)
+	require.Equal(t, node.dv5Local.ID(), nodes[0].ID())
+
+	// A record without libp2p port, like that of a bootnode, is reported as unreachable
+	bootnode := udpV5.Self()
+	results := crawler.Identify(context.Background(), append(nodes, bootnode), 2, 5*time.Second)
+	require.Len(t, results, 2)
+
+	res := results[0]
+	require.True(t, res.Reachable, res.Error)
+	require.Equal(t, node.Host().ID().String(), res.PeerID)
+	require.Equal(t, "optimism-testing", res.AgentVersion)
+	require.Contains(t, res.Protocols, "/meshsub/1.1.0")
+	require.Len(t, h.Network().Peers(), 0, "disconnected after identification")
+
+	require.False(t, results[1].Reachable)
+	require.Equal(t, bootnode.ID().String(), results[1].NodeID)
+	require.NotEmpty(t, results[1].Error)
+}
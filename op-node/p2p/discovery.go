@@ -5,6 +5,7 @@ import (
 	"context"
 	secureRand "crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -19,9 +20,11 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/event"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
 )
 
 const (
@@ -37,7 +40,7 @@ const (
 	collectiveDialTimeout  = time.Second * 30
 )
 
-func (conf *Config) Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort uint16) (*enode.LocalNode, *discover.UDPv5, error) {
+func (conf *Config) Discovery(log log.Logger, rollupCfg *rollup.Config, ports HostPorts) (*enode.LocalNode, *discover.UDPv5, error) {
 	if conf.NoDiscovery {
 		return nil, nil, nil
 	}
@@ -53,13 +56,28 @@ func (conf *Config) Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort
 	}
 	if conf.AdvertiseTCPPort != 0 { // explicitly advertised port gets priority
 		localNode.Set(enr.TCP(conf.AdvertiseTCPPort))
-	} else if tcpPort != 0 { // otherwise try to pick up whatever port LibP2P binded to (listen port, or dynamically picked)
-		localNode.Set(enr.TCP(tcpPort))
+	} else if ports.TCP != 0 { // otherwise try to pick up whatever port LibP2P binded to (listen port, or dynamically picked)
+		localNode.Set(enr.TCP(ports.TCP))
 	} else if conf.ListenTCPPort != 0 { // otherwise default to the port we configured it to listen on
 		localNode.Set(enr.TCP(conf.ListenTCPPort))
-	} else {
+	} else if !conf.DisableP2P { // discovery-only nodes, like bootnodes, have no libp2p host to advertise
 		return nil, nil, fmt.Errorf("no TCP port to put in discovery record")
 	}
+	// The optional transports are advertised with the same priority of ports as TCP.
+	if conf.EnableQUIC {
+		if port := firstNonZero(conf.AdvertiseQUICPort, ports.QUIC, conf.ListenQUICPort); port != 0 {
+			localNode.Set(QUICPort(port))
+		} else {
+			log.Warn("no QUIC port to put in discovery record")
+		}
+	}
+	if conf.EnableWS {
+		if port := firstNonZero(conf.AdvertiseWSPort, ports.WS, conf.ListenWSPort); port != 0 {
+			localNode.Set(WSPort(port))
+		} else {
+			log.Warn("no WebSocket port to put in discovery record")
+		}
+	}
 	dat := OptimismENRData{
 		chainID: rollupCfg.L2ChainID.Uint64(),
 		version: 0,
@@ -79,6 +97,17 @@ func (conf *Config) Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort
 		localUDPAddr := conn.LocalAddr().(*net.UDPAddr)
 		localNode.SetFallbackUDP(localUDPAddr.Port)
 	}
+	// Discovery-only nodes, like bootnodes, have no libp2p host to learn the external address from,
+	// and would have no address in the record until the discv5 peers agree on one, leaving the record unusable.
+	// Fall back to the address we bound to, if it is specific.
+	if conf.DisableP2P {
+		if conf.AdvertiseUDPPort == 0 {
+			localNode.SetFallbackUDP(conn.LocalAddr().(*net.UDPAddr).Port)
+		}
+		if conf.ListenIP != nil && !conf.ListenIP.IsUnspecified() {
+			localNode.SetFallbackIP(conf.ListenIP)
+		}
+	}
 
 	cfg := discover.Config{
 		PrivateKey:   &priv,
@@ -95,24 +124,166 @@ func (conf *Config) Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort
 
 	log.Info("started discovery service", "enr", localNode.Node(), "id", localNode.ID())
 
-	// TODO: periodically we can pull the external IP and TCP port from libp2p NAT service,
-	// and add it as a statement to keep the localNode accurate (if we trust the NAT device more than the discv5 statements)
-
 	return localNode, udpV5, nil
 }
 
+// AdvertisedAddrs are the address components that are explicitly configured to be advertised in the ENR.
+// These are never replaced with the external addresses found by the host. Zero values are not configured.
+type AdvertisedAddrs struct {
+	IP   net.IP
+	TCP  uint16
+	QUIC uint16
+	WS   uint16
+}
+
+func (conf *Config) Advertised() AdvertisedAddrs {
+	return AdvertisedAddrs{
+		IP:   conf.AdvertiseIP,
+		TCP:  conf.AdvertiseTCPPort,
+		QUIC: conf.AdvertiseQUICPort,
+		WS:   conf.AdvertiseWSPort,
+	}
+}
+
+// externalAddrs finds the public IP of the host, preferring IPv4, and the ports of the transports on that IP.
+// The public addresses of the host are mapped by the NAT manager, or observed by peers.
+// The IP is nil if the host does not know of any public address yet.
+func externalAddrs(addrs []multiaddr.Multiaddr) (net.IP, HostPorts) {
+	var ip net.IP
+	for _, addr := range addrs {
+		if !manet.IsPublicAddr(addr) {
+			continue
+		}
+		addrIP, err := manet.ToIP(addr)
+		if err != nil {
+			continue
+		}
+		if ip == nil || (ip.To4() == nil && addrIP.To4() != nil) {
+			ip = addrIP
+		}
+	}
+	if ip == nil {
+		return nil, HostPorts{}
+	}
+	var sameIP []multiaddr.Multiaddr
+	for _, addr := range addrs {
+		if addrIP, err := manet.ToIP(addr); err == nil && addrIP.Equal(ip) {
+			sameIP = append(sameIP, addr)
+		}
+	}
+	return ip, findPorts(sameIP)
+}
+
+// updateENR updates the IP and ports in the local node record to the external addresses of the host,
+// unless they are explicitly configured to be advertised. The ENR sequence number is bumped on changes.
+func (n *NodeP2P) updateENR(log log.Logger, advertised AdvertisedAddrs, addrs []multiaddr.Multiaddr) {
+	ip, ports := externalAddrs(addrs)
+	if ip == nil {
+		return
+	}
+	prev := n.dv5Local.Node()
+	var changes []interface{}
+	if advertised.IP == nil && !ip.Equal(prev.IP()) {
+		// We trust the NAT device and identified peers more than the discv5 endpoint statements,
+		// since these cover the libp2p ports as well.
+		n.dv5Local.SetStaticIP(ip)
+		changes = append(changes, "ip", ip, "prev_ip", prev.IP())
+	}
+	if advertised.TCP == 0 && ports.TCP != 0 && int(ports.TCP) != prev.TCP() {
+		n.dv5Local.Set(enr.TCP(ports.TCP))
+		changes = append(changes, "tcp", ports.TCP, "prev_tcp", prev.TCP())
+	}
+	var prevQUIC QUICPort
+	_ = prev.Load(&prevQUIC)
+	if advertised.QUIC == 0 && ports.QUIC != 0 && QUICPort(ports.QUIC) != prevQUIC {
+		n.dv5Local.Set(QUICPort(ports.QUIC))
+		changes = append(changes, "quic", ports.QUIC, "prev_quic", uint16(prevQUIC))
+	}
+	var prevWS WSPort
+	_ = prev.Load(&prevWS)
+	if advertised.WS == 0 && ports.WS != 0 && WSPort(ports.WS) != prevWS {
+		n.dv5Local.Set(WSPort(ports.WS))
+		changes = append(changes, "ws", ports.WS, "prev_ws", uint16(prevWS))
+	}
+	if len(changes) > 0 {
+		log.Info("updated external address in local node record", append(changes, "seq", n.dv5Local.Node().Seq())...)
+	}
+}
+
+// ENRUpdateProcess keeps the local node record up to date with the external addresses of the host,
+// for nodes behind NAT or with changing IPs to not advertise stale records.
+func (n *NodeP2P) ENRUpdateProcess(ctx context.Context, log log.Logger, advertised AdvertisedAddrs) {
+	if n.dv5Local == nil {
+		return
+	}
+	sub, err := n.host.EventBus().Subscribe(new(event.EvtLocalAddressesUpdated))
+	if err != nil {
+		log.Error("failed to subscribe to local address updates", "err", err)
+		return
+	}
+	defer sub.Close()
+	n.updateENR(log, advertised, n.host.Addrs())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-sub.Out():
+			if !ok {
+				return
+			}
+			n.updateENR(log, advertised, n.host.Addrs())
+		}
+	}
+}
+
+func firstNonZero(ports ...uint16) uint16 {
+	for _, p := range ports {
+		if p != 0 {
+			return p
+		}
+	}
+	return 0
+}
+
+// QUICPort is the ENR entry of the UDP port of the libp2p QUIC transport.
+type QUICPort uint16
+
+func (v QUICPort) ENRKey() string { return "quic" }
+
+// WSPort is the ENR entry of the TCP port of the libp2p WebSocket transport.
+type WSPort uint16
+
+func (v WSPort) ENRKey() string { return "ws" }
+
 func enrToAddrInfo(r *enode.Node) (*peer.AddrInfo, error) {
-	ip := r.IP()
-	ipScheme := "ip4"
-	if ip4 := ip.To4(); ip4 == nil {
-		ipScheme = "ip6"
-	} else {
-		ip = ip4
+	if r.TCP() == 0 {
+		// e.g. bootnodes, these only run discovery
+		return nil, errors.New("node record has no libp2p TCP port")
 	}
-	mAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", ipScheme, ip.String(), r.TCP()))
+	ip := r.IP()
+	mAddr, err := addrFromIPAndPort(ip, uint16(r.TCP()))
 	if err != nil {
 		return nil, fmt.Errorf("could not construct multi addr: %v", err)
 	}
+	addrs := []multiaddr.Multiaddr{mAddr}
+	// Nodes may advertise additional transports. We can't dial the transports we don't run ourselves,
+	// but the peerstore keeps these addresses around regardless, the dialer will skip them.
+	var quicPort QUICPort
+	if err := r.Load(&quicPort); err == nil && quicPort != 0 {
+		quicAddr, err := quicAddrFromIPAndPort(ip, uint16(quicPort))
+		if err != nil {
+			return nil, fmt.Errorf("could not construct QUIC multi addr: %v", err)
+		}
+		addrs = append(addrs, quicAddr)
+	}
+	var wsPort WSPort
+	if err := r.Load(&wsPort); err == nil && wsPort != 0 {
+		wsAddr, err := wsAddrFromIPAndPort(ip, uint16(wsPort))
+		if err != nil {
+			return nil, fmt.Errorf("could not construct WebSocket multi addr: %v", err)
+		}
+		addrs = append(addrs, wsAddr)
+	}
 	pub := r.Pubkey()
 	peerID, err := peer.IDFromPublicKey((*crypto.Secp256k1PublicKey)(pub))
 	if err != nil {
@@ -120,7 +291,7 @@ func enrToAddrInfo(r *enode.Node) (*peer.AddrInfo, error) {
 	}
 	return &peer.AddrInfo{
 		ID:    peerID,
-		Addrs: []multiaddr.Multiaddr{mAddr},
+		Addrs: addrs,
 	}, nil
 }
 
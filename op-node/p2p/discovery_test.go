@@ -0,0 +1,163 @@
+package p2p
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	gcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestDiscoveryAdvertisedTransports(t *testing.T) {
+	priv, err := gcrypto.GenerateKey()
+	require.NoError(t, err)
+	db, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer db.Close()
+	conf := &Config{
+		Priv:              priv,
+		ListenIP:          net.IP{127, 0, 0, 1},
+		AdvertiseIP:       net.IP{10, 0, 0, 1},
+		DiscoveryDB:       db,
+		EnableQUIC:        true,
+		AdvertiseQUICPort: 9001,
+		EnableWS:          true,
+		ListenWSPort:      9003, // used if the host did not report the bound WS port
+	}
+	rollupCfg := &rollup.Config{L2ChainID: big.NewInt(901)}
+	localNode, udpV5, err := conf.Discovery(testlog.Logger(t, log.LvlError), rollupCfg, HostPorts{TCP: 9000, QUIC: 9002})
+	require.NoError(t, err)
+	defer udpV5.Close()
+
+	node := localNode.Node()
+	var quicPort QUICPort
+	require.NoError(t, node.Load(&quicPort))
+	require.Equal(t, QUICPort(9001), quicPort, "advertised port has priority")
+	var wsPort WSPort
+	require.NoError(t, node.Load(&wsPort))
+	require.Equal(t, WSPort(9003), wsPort)
+
+	info, err := enrToAddrInfo(node)
+	require.NoError(t, err)
+	expected := []string{
+		"/ip4/10.0.0.1/tcp/9000",
+		"/ip4/10.0.0.1/udp/9001/quic",
+		"/ip4/10.0.0.1/tcp/9003/ws",
+	}
+	require.Len(t, info.Addrs, len(expected))
+	for i, addr := range info.Addrs {
+		require.Equal(t, ma.StringCast(expected[i]), addr)
+	}
+}
+
+func TestENRToAddrInfoTCPOnly(t *testing.T) {
+	priv, err := gcrypto.GenerateKey()
+	require.NoError(t, err)
+	var r enr.Record
+	r.Set(enr.IPv4(net.IP{10, 0, 0, 2}))
+	r.Set(enr.TCP(9222))
+	require.NoError(t, enode.SignV4(&r, priv))
+	node, err := enode.New(enode.ValidSchemes, &r)
+	require.NoError(t, err)
+
+	info, err := enrToAddrInfo(node)
+	require.NoError(t, err)
+	require.Equal(t, []ma.Multiaddr{ma.StringCast("/ip4/10.0.0.2/tcp/9222")}, info.Addrs)
+}
+
+func TestDiscoveryOnly(t *testing.T) {
+	priv, err := gcrypto.GenerateKey()
+	require.NoError(t, err)
+	db, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer db.Close()
+	conf := &Config{
+		DisableP2P:  true,
+		Priv:        priv,
+		ListenIP:    net.IP{127, 0, 0, 1},
+		DiscoveryDB: db,
+	}
+	rollupCfg := &rollup.Config{L2ChainID: big.NewInt(901)}
+	localNode, udpV5, err := conf.Discovery(testlog.Logger(t, log.LvlError), rollupCfg, HostPorts{})
+	require.NoError(t, err)
+	defer udpV5.Close()
+
+	node := localNode.Node()
+	require.Equal(t, net.IP{127, 0, 0, 1}, node.IP().To4(), "falls back to the listen IP")
+	require.Equal(t, udpV5.Self().UDP(), node.UDP())
+	require.NotZero(t, node.UDP(), "advertises the dynamically bound UDP port")
+	require.Zero(t, node.TCP())
+	_, err = enrToAddrInfo(node)
+	require.Error(t, err, "a discovery-only node cannot be dialed with libp2p")
+
+	// regular nodes learn their external address from the libp2p host instead
+	conf.DisableP2P = false
+	conf.ListenTCPPort = 9222
+	localNode, udpV5, err = conf.Discovery(testlog.Logger(t, log.LvlError), rollupCfg, HostPorts{})
+	require.NoError(t, err)
+	defer udpV5.Close()
+	require.Nil(t, localNode.Node().IP(), "does not fall back to the listen IP")
+}
+
+func TestUpdateENR(t *testing.T) {
+	priv, err := gcrypto.GenerateKey()
+	require.NoError(t, err)
+	db, err := enode.OpenDB("")
+	require.NoError(t, err)
+	defer db.Close()
+	localNode := enode.NewLocalNode(db, priv)
+	localNode.SetFallbackIP(net.IP{127, 0, 0, 1})
+	localNode.Set(enr.TCP(9222))
+	n := &NodeP2P{dv5Local: localNode}
+	logger := testlog.Logger(t, log.LvlError)
+
+	// only private addresses: nothing to update
+	seq := localNode.Node().Seq()
+	n.updateENR(logger, AdvertisedAddrs{}, []ma.Multiaddr{
+		ma.StringCast("/ip4/127.0.0.1/tcp/9222"),
+		ma.StringCast("/ip4/192.168.1.2/tcp/9222"),
+	})
+	require.Equal(t, seq, localNode.Node().Seq())
+
+	// NAT-mapped public addresses
+	addrs := []ma.Multiaddr{
+		ma.StringCast("/ip4/127.0.0.1/tcp/9222"),
+		ma.StringCast("/ip6/2001:4860::1/tcp/9999"),
+		ma.StringCast("/ip4/8.8.8.8/tcp/30000"),
+		ma.StringCast("/ip4/8.8.8.8/udp/30001/quic"),
+		ma.StringCast("/ip4/8.8.8.8/tcp/30002/ws"),
+	}
+	n.updateENR(logger, AdvertisedAddrs{}, addrs)
+	node := localNode.Node()
+	require.Greater(t, node.Seq(), seq, "sequence number must be bumped")
+	require.Equal(t, net.IP{8, 8, 8, 8}.String(), node.IP().String(), "IPv4 is preferred")
+	require.Equal(t, 30000, node.TCP())
+	var quicPort QUICPort
+	require.NoError(t, node.Load(&quicPort))
+	require.Equal(t, QUICPort(30001), quicPort)
+	var wsPort WSPort
+	require.NoError(t, node.Load(&wsPort))
+	require.Equal(t, WSPort(30002), wsPort)
+
+	// no changes: no new sequence number
+	seq = node.Seq()
+	n.updateENR(logger, AdvertisedAddrs{}, addrs)
+	require.Equal(t, seq, localNode.Node().Seq())
+
+	// explicitly advertised addresses are not updated
+	n.updateENR(logger, AdvertisedAddrs{IP: net.IP{8, 8, 8, 8}, TCP: 30000}, []ma.Multiaddr{
+		ma.StringCast("/ip4/1.1.1.1/tcp/40000"),
+	})
+	node = localNode.Node()
+	require.Equal(t, seq, node.Seq())
+	require.Equal(t, net.IP{8, 8, 8, 8}.String(), node.IP().String())
+	require.Equal(t, 30000, node.TCP())
+}
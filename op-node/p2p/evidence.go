@@ -0,0 +1,197 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// SignedBlock is a block as published on the blocks topic, with the signature of the sequencer.
+type SignedBlock struct {
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Signer     common.Address `json:"signer"`
+	Signature  hexutil.Bytes  `json:"signature"`
+	// Payload is the SSZ-encoded execution payload that the signature covers.
+	Payload hexutil.Bytes `json:"payload"`
+}
+
+// Equivocation is the evidence of the sequencer misbehaving:
+// two different blocks at the same height on the same parent, both validly signed by an authorized sequencer key.
+type Equivocation struct {
+	Height     uint64      `json:"height"`
+	BlockA     SignedBlock `json:"blockA"`
+	BlockB     SignedBlock `json:"blockB"`
+	DetectedAt uint64      `json:"detectedAt"` // unix timestamp
+}
+
+// decodeSignedBlock decodes and verifies a gossiped blocks topic message.
+func decodeSignedBlock(cfg *rollup.Config, msg []byte) (*SignedBlock, uint64, error) {
+	data, err := snappy.Decode(nil, msg)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid snappy compression: %w", err)
+	}
+	if len(data) < 65 {
+		return nil, 0, errors.New("message too short to contain signature")
+	}
+	signatureBytes, payloadBytes := data[:65], data[65:]
+	var payload l2.ExecutionPayload
+	if err := payload.UnmarshalSSZ(uint32(len(payloadBytes)), bytes.NewReader(payloadBytes)); err != nil {
+		return nil, 0, fmt.Errorf("invalid payload: %w", err)
+	}
+	signingHash := BlockSigningHash(cfg, payloadBytes)
+	pub, err := crypto.SigToPub(signingHash[:], signatureBytes)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid block signature: %w", err)
+	}
+	return &SignedBlock{
+		Hash:       payload.BlockHash,
+		ParentHash: payload.ParentHash,
+		Signer:     crypto.PubkeyToAddress(*pub),
+		Signature:  signatureBytes,
+		Payload:    payloadBytes,
+	}, uint64(payload.BlockNumber), nil
+}
+
+// NewEquivocation builds the evidence of an equivocation from two different blocks topic messages at the same height,
+// on the same parent. Blocks on different parents may be the result of re-sequencing after a L1 reorg.
+func NewEquivocation(cfg *rollup.Config, msgA []byte, msgB []byte) (*Equivocation, error) {
+	a, heightA, err := decodeSignedBlock(cfg, msgA)
+	if err != nil {
+		return nil, fmt.Errorf("bad first block: %w", err)
+	}
+	b, heightB, err := decodeSignedBlock(cfg, msgB)
+	if err != nil {
+		return nil, fmt.Errorf("bad second block: %w", err)
+	}
+	if heightA != heightB {
+		return nil, fmt.Errorf("blocks are at different heights: %d <> %d", heightA, heightB)
+	}
+	if a.Hash == b.Hash {
+		return nil, fmt.Errorf("blocks are the same: %s", a.Hash)
+	}
+	if a.ParentHash != b.ParentHash {
+		return nil, fmt.Errorf("blocks have different parents: %s <> %s", a.ParentHash, b.ParentHash)
+	}
+	return &Equivocation{
+		Height:     heightA,
+		BlockA:     *a,
+		BlockB:     *b,
+		DetectedAt: uint64(time.Now().Unix()),
+	}, nil
+}
+
+// key identifies the equivocation, regardless of the order the blocks were seen in.
+func (e *Equivocation) key() string {
+	a, b := e.BlockA.Hash.Hex(), e.BlockB.Hash.Hex()
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("equivocation-%d-%s-%s", e.Height, a[2:10], b[2:10])
+}
+
+type EquivocationMetrics interface {
+	RecordEquivocation()
+}
+
+// EvidenceStore keeps the evidence of sequencer equivocations, and persists each of them as JSON file, if a directory is configured.
+type EvidenceStore struct {
+	log     log.Logger
+	dir     string              // empty if not persisted
+	metrics EquivocationMetrics // may be nil
+
+	mu            sync.Mutex
+	equivocations map[string]*Equivocation
+}
+
+// NewEvidenceStore creates an evidence store, and loads the previously persisted evidence from the directory.
+// The evidence is only kept in memory if the directory is empty.
+func NewEvidenceStore(log log.Logger, dir string, metrics EquivocationMetrics) (*EvidenceStore, error) {
+	s := &EvidenceStore{log: log, dir: dir, metrics: metrics, equivocations: make(map[string]*Equivocation)}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create evidence dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evidence dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read evidence file %q: %w", entry.Name(), err)
+		}
+		var e Equivocation
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode evidence file %q: %w", entry.Name(), err)
+		}
+		s.equivocations[e.key()] = &e
+	}
+	return s, nil
+}
+
+// RecordEquivocation stores the evidence of an equivocation, if it was not already recorded.
+func (s *EvidenceStore) RecordEquivocation(e *Equivocation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := e.key()
+	if _, ok := s.equivocations[key]; ok {
+		return
+	}
+	s.equivocations[key] = e
+	s.log.Error("sequencer equivocated: signed different blocks at the same height", "height", e.Height,
+		"hash_a", e.BlockA.Hash, "signer_a", e.BlockA.Signer, "hash_b", e.BlockB.Hash, "signer_b", e.BlockB.Signer)
+	if s.metrics != nil {
+		s.metrics.RecordEquivocation()
+	}
+	if s.dir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		s.log.Error("failed to encode equivocation evidence", "err", err)
+		return
+	}
+	path := filepath.Join(s.dir, key+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		s.log.Error("failed to write equivocation evidence", "path", path, "err", err)
+	}
+}
+
+// Equivocations returns the recorded evidence, ordered by height.
+func (s *EvidenceStore) Equivocations() []*Equivocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Equivocation, 0, len(s.equivocations))
+	for _, e := range s.equivocations {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Height != out[j].Height {
+			return out[i].Height < out[j].Height
+		}
+		return out[i].key() < out[j].key()
+	})
+	return out
+}
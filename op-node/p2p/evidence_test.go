@@ -0,0 +1,149 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type countingMetrics struct {
+	equivocations int
+}
+
+func (m *countingMetrics) RecordEquivocation() {
+	m.equivocations++
+}
+
+// signedBlockMessage creates a blocks topic message, like the publisher does, for a block at the given height.
+func signedBlockMessage(t *testing.T, cfg *rollup.Config, signer Signer, height uint64, parent common.Hash) []byte {
+	return signedBlockMessageWithExtra(t, cfg, signer, height, parent, nil)
+}
+
+// signedBlockMessageWithExtra creates a blocks topic message, with the given extra-data to tell apart blocks on the same parent.
+func signedBlockMessageWithExtra(t *testing.T, cfg *rollup.Config, signer Signer, height uint64, parent common.Hash, extra []byte) []byte {
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash:  parent,
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Difficulty:  common.Big0,
+		Number:      new(big.Int).SetUint64(height),
+		GasLimit:    30_000_000,
+		Time:        uint64(time.Now().Unix()),
+		BaseFee:     big.NewInt(7),
+		Extra:       extra,
+	})
+	payload, err := l2.BlockAsPayload(block)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 65))
+	_, err = payload.MarshalSSZ(&buf)
+	require.NoError(t, err)
+	data := buf.Bytes()
+	sig, err := signer.Sign(context.Background(), SigningDomainBlocksV1, cfg.L2ChainID, data[65:])
+	require.NoError(t, err)
+	copy(data[:65], sig[:])
+	return snappy.Encode(nil, data)
+}
+
+func TestBlocksValidatorEquivocation(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey)}
+	signer := NewLocalSigner(priv)
+
+	m := &countingMetrics{}
+	evidence, err := NewEvidenceStore(testlog.Logger(t, log.LvlCrit), t.TempDir(), m)
+	require.NoError(t, err)
+	validator, err := BuildBlocksValidator(testlog.Logger(t, log.LvlCrit), cfg, 0, evidence)
+	require.NoError(t, err)
+	validate := func(msg []byte) pubsub.ValidationResult {
+		return validator(context.Background(), "", &pubsub.Message{Message: &pb.Message{Data: msg}})
+	}
+
+	msgA := signedBlockMessage(t, cfg, signer, 10, common.Hash{0xa})
+	require.Equal(t, pubsub.ValidationAccept, validate(msgA))
+	require.Equal(t, pubsub.ValidationIgnore, validate(msgA), "seen before")
+	require.Empty(t, evidence.Equivocations())
+
+	other := signedBlockMessage(t, cfg, signer, 11, common.Hash{0xb})
+	require.Equal(t, pubsub.ValidationAccept, validate(other))
+	require.Empty(t, evidence.Equivocations(), "different heights are fine")
+
+	reorged := signedBlockMessage(t, cfg, signer, 10, common.Hash{0xc})
+	require.Equal(t, pubsub.ValidationAccept, validate(reorged))
+	require.Empty(t, evidence.Equivocations(), "re-sequencing on a different parent is fine")
+
+	msgB := signedBlockMessageWithExtra(t, cfg, signer, 10, common.Hash{0xa}, []byte{0xb})
+	require.Equal(t, pubsub.ValidationAccept, validate(msgB))
+	equivocations := evidence.Equivocations()
+	require.Len(t, equivocations, 1)
+	e := equivocations[0]
+	require.Equal(t, uint64(10), e.Height)
+	require.Equal(t, cfg.P2PSequencerAddress, e.BlockA.Signer)
+	require.Equal(t, cfg.P2PSequencerAddress, e.BlockB.Signer)
+	require.NotEqual(t, e.BlockA.Hash, e.BlockB.Hash)
+	// the evidence can be verified by anyone, without trusting the node that recorded it
+	for _, b := range []SignedBlock{e.BlockA, e.BlockB} {
+		signingHash := BlockSigningHash(cfg, b.Payload)
+		pub, err := crypto.SigToPub(signingHash[:], b.Signature)
+		require.NoError(t, err)
+		require.Equal(t, b.Signer, crypto.PubkeyToAddress(*pub))
+	}
+	require.Equal(t, 1, m.equivocations)
+
+	// another block on the same parent is another equivocation, compared to the first block
+	msgC := signedBlockMessageWithExtra(t, cfg, signer, 10, common.Hash{0xa}, []byte{0xc})
+	require.Equal(t, pubsub.ValidationAccept, validate(msgC))
+	require.Len(t, evidence.Equivocations(), 2)
+	require.Equal(t, 2, m.equivocations)
+}
+
+func TestEvidenceStorePersistence(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey)}
+	signer := NewLocalSigner(priv)
+	logger := testlog.Logger(t, log.LvlCrit)
+
+	msgA := signedBlockMessage(t, cfg, signer, 10, common.Hash{0xa})
+	msgB := signedBlockMessageWithExtra(t, cfg, signer, 10, common.Hash{0xa}, []byte{0xb})
+	e, err := NewEquivocation(cfg, msgA, msgB)
+	require.NoError(t, err)
+	_, err = NewEquivocation(cfg, msgA, msgA)
+	require.Error(t, err, "same block is not an equivocation")
+	_, err = NewEquivocation(cfg, msgA, signedBlockMessage(t, cfg, signer, 11, common.Hash{0xa}))
+	require.Error(t, err, "different heights are not an equivocation")
+	_, err = NewEquivocation(cfg, msgA, signedBlockMessage(t, cfg, signer, 10, common.Hash{0xb}))
+	require.Error(t, err, "different parents are not an equivocation")
+
+	dir := t.TempDir()
+	m := &countingMetrics{}
+	store, err := NewEvidenceStore(logger, dir, m)
+	require.NoError(t, err)
+	store.RecordEquivocation(e)
+	reversed, err := NewEquivocation(cfg, msgB, msgA)
+	require.NoError(t, err)
+	store.RecordEquivocation(reversed)
+	require.Len(t, store.Equivocations(), 1, "same equivocation, in a different order, is only recorded once")
+	require.Equal(t, 1, m.equivocations)
+
+	reopened, err := NewEvidenceStore(logger, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*Equivocation{e}, reopened.Equivocations())
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/golang/snappy"
+	"github.com/hashicorp/go-multierror"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -42,14 +43,10 @@ var MessageDomainValidSnappy = [4]byte{1, 0, 0, 0}
 
 const MaxGossipSize = 1 << 20
 
-func blocksTopicV1(cfg *rollup.Config) string {
-	return fmt.Sprintf("/optimism/%s/0/blocks", cfg.L2ChainID.String())
-}
-
 // BuildSubscriptionFilter builds a simple subscription filter,
 // to help protect against peers spamming useless subscriptions.
 func BuildSubscriptionFilter(cfg *rollup.Config) pubsub.SubscriptionFilter {
-	return pubsub.NewAllowlistSubscriptionFilter(blocksTopicV1(cfg)) // add more topics here in the future, if any.
+	return pubsub.NewAllowlistSubscriptionFilter(allBlocksTopics(cfg)...) // add more topics here in the future, if any.
 }
 
 var msgBufPool = sync.Pool{New: func() any {
@@ -111,12 +108,13 @@ func BuildGlobalGossipParams(cfg *rollup.Config) pubsub.GossipSubParams {
 	return params
 }
 
-func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config) (*pubsub.PubSub, error) {
+// NewGossipSub creates the gossip router. Message stats are traced into the gossip stats, if not nil.
+func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config, log log.Logger, stats *GossipStats) (*pubsub.PubSub, error) {
 	denyList, err := pubsub.NewTimeCachedBlacklist(30 * time.Second)
 	if err != nil {
 		return nil, err
 	}
-	return pubsub.NewGossipSub(p2pCtx, h,
+	opts := []pubsub.Option{
 		pubsub.WithMaxMessageSize(maxGossipSize),
 		pubsub.WithMessageIdFn(BuildMsgIdFn(cfg)),
 		pubsub.WithNoAuthor(),
@@ -128,8 +126,14 @@ func NewGossipSub(p2pCtx context.Context, h host.Host, cfg *rollup.Config) (*pub
 		pubsub.WithPeerExchange(false),
 		pubsub.WithBlacklist(denyList),
 		pubsub.WithGossipSubParams(BuildGlobalGossipParams(cfg)),
-	)
-	// TODO: pubsub.WithPeerScoreInspect(inspect, InspectInterval) to update peerstore scores with gossip scores
+		pubsub.WithPeerScore(BuildPeerScoreParams(cfg), &PeerScoreThresholds),
+		// must be after WithPeerScore
+		pubsub.WithPeerScoreInspect(gossipScoresInspector(h, cfg, log), peerScoreInspectFrequency),
+	}
+	if stats != nil {
+		opts = append(opts, pubsub.WithRawTracer(stats))
+	}
+	return pubsub.NewGossipSub(p2pCtx, h, opts...)
 }
 
 func validationResultString(v pubsub.ValidationResult) string {
@@ -145,15 +149,29 @@ func validationResultString(v pubsub.ValidationResult) string {
 	}
 }
 
-func logValidationResult(self peer.ID, msg string, log log.Logger, fn pubsub.ValidatorEx) pubsub.ValidatorEx {
+// validatorWithReason is a topic validator that also returns the reason why a message is not accepted.
+type validatorWithReason func(ctx context.Context, id peer.ID, message *pubsub.Message) (pubsub.ValidationResult, string)
+
+func dropReason(fn validatorWithReason) pubsub.ValidatorEx {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		res, _ := fn(ctx, id, message)
+		return res
+	}
+}
+
+// logValidationResult logs the validation results, and records them in the gossip stats, if not nil.
+func logValidationResult(self peer.ID, msg string, log log.Logger, stats *GossipStats, fn validatorWithReason) pubsub.ValidatorEx {
 	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
-		res := fn(ctx, id, message)
+		res, reason := fn(ctx, id, message)
+		if stats != nil {
+			stats.RecordValidation(message.GetTopic(), id, res, reason)
+		}
 		var src interface{}
 		src = id
 		if id == self {
 			src = "self"
 		}
-		log.Debug(msg, "result", validationResultString(res), "from", src)
+		log.Debug(msg, "result", validationResultString(res), "reason", reason, "from", src)
 		return res
 	}
 }
@@ -161,6 +179,14 @@ func logValidationResult(self peer.ID, msg string, log log.Logger, fn pubsub.Val
 type seenBlocks struct {
 	sync.Mutex
 	blockHashes []common.Hash
+	// first is the message of the first validly signed block at this height, by parent hash,
+	// kept as evidence in case the sequencer signs another block at the same height on the same parent.
+	first map[common.Hash]seenBlock
+}
+
+type seenBlock struct {
+	hash common.Hash
+	msg  []byte
 }
 
 // hasSeen checks if the hash has been marked as seen, and how many have been seen.
@@ -175,14 +201,43 @@ func (sb *seenBlocks) hasSeen(h common.Hash) (count int, hasSeen bool) {
 	return len(sb.blockHashes), false
 }
 
-// markSeen marks the block hash as seen
-func (sb *seenBlocks) markSeen(h common.Hash) {
+// markSeen marks the block hash as seen, and returns the message of the first block at the same height
+// with the same parent, if the given block is a different one.
+// Blocks with a different parent are not compared: the sequencer builds a new block at the same height
+// when it re-sequences after a L1 reorg, which is not an equivocation.
+func (sb *seenBlocks) markSeen(h common.Hash, parent common.Hash, msg []byte) (first []byte) {
 	sb.Lock()
 	defer sb.Unlock()
 	sb.blockHashes = append(sb.blockHashes, h)
+	if sb.first == nil {
+		sb.first = make(map[common.Hash]seenBlock)
+	}
+	prev, ok := sb.first[parent]
+	if !ok {
+		sb.first[parent] = seenBlock{hash: h, msg: msg}
+		return nil
+	}
+	if prev.hash == h {
+		return nil
+	}
+	return prev.msg
+}
+
+// BuildBlocksValidator builds the validator of a version of the blocks topic.
+// Sequencer equivocations are recorded in the evidence store, if not nil.
+func BuildBlocksValidator(log log.Logger, cfg *rollup.Config, version uint64, evidence *EvidenceStore) (pubsub.ValidatorEx, error) {
+	fn, err := buildBlocksValidator(log, cfg, version, evidence)
+	if err != nil {
+		return nil, err
+	}
+	return dropReason(fn), nil
 }
 
-func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx {
+func buildBlocksValidator(log log.Logger, cfg *rollup.Config, version uint64, evidence *EvidenceStore) (validatorWithReason, error) {
+	format, ok := blocksFormats[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported blocks topic version %d", version)
+	}
 
 	// Seen block hashes per block height
 	// uint64 -> *seenBlocks
@@ -191,16 +246,16 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		panic(fmt.Errorf("failed to set up block height LRU cache: %v", err))
 	}
 
-	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) (pubsub.ValidationResult, string) {
 		// [REJECT] if the compression is not valid
 		outLen, err := snappy.DecodedLen(message.Data)
 		if err != nil {
 			log.Warn("invalid snappy compression length data", "err", err, "peer", id)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "invalid_snappy"
 		}
 		if outLen > maxGossipSize {
 			log.Warn("possible snappy zip bomb, decoded length is too large", "decoded_length", outLen, "peer", id)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "too_large"
 		}
 
 		res := msgBufPool.Get().(*[]byte)
@@ -208,7 +263,7 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		data, err := snappy.Decode((*res)[:0], message.Data)
 		if err != nil {
 			log.Warn("invalid snappy compression", "err", err, "peer", id)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "invalid_snappy"
 		}
 		*res = data // if we ended up growing the slice capacity, fine, keep the larger one.
 
@@ -216,10 +271,16 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		signatureBytes, payloadBytes := data[:65], data[65:]
 
 		// [REJECT] if the block encoding is not valid
-		var payload l2.ExecutionPayload
-		if err := payload.UnmarshalSSZ(uint32(len(payloadBytes)), bytes.NewReader(payloadBytes)); err != nil {
+		payload, err := format.decode(payloadBytes)
+		if err != nil {
 			log.Warn("invalid payload", "err", err, "peer", id)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "invalid_payload"
+		}
+
+		// [REJECT] if the block is not published on the topic version that is active at the block time
+		if v := cfg.P2PBlocksTopicVersion(uint64(payload.Timestamp)); v != version {
+			log.Warn("payload on wrong topic version", "timestamp", uint64(payload.Timestamp), "version", version, "expected", v, "peer", id)
+			return pubsub.ValidationReject, "wrong_topic_version"
 		}
 
 		// rounding down to seconds is fine here.
@@ -228,19 +289,19 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		// [REJECT] if the `payload.timestamp` is older than 20 seconds in the past
 		if uint64(payload.Timestamp) < now-20 {
 			log.Warn("payload is too old", "timestamp", uint64(payload.Timestamp))
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "too_old"
 		}
 
 		// [REJECT] if the `payload.timestamp` is more than 5 seconds into the future
 		if uint64(payload.Timestamp) > now+5 {
 			log.Warn("payload is too new", "timestamp", uint64(payload.Timestamp))
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "too_new"
 		}
 
 		// [REJECT] if the `block_hash` in the `payload` is not valid
 		if actual, ok := payload.CheckBlockHash(); !ok {
 			log.Warn("payload has bad block hash", "bad_hash", payload.BlockHash.String(), "actual", actual.String())
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "bad_block_hash"
 		}
 
 		seen, ok := blockHeightLRU.Get(uint64(payload.BlockNumber))
@@ -252,11 +313,11 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		if count, hasSeen := seen.(*seenBlocks).hasSeen(payload.BlockHash); count > 5 {
 			// [REJECT] if more than 5 blocks have been seen with the same block height
 			log.Warn("seen too many different blocks at same height", "height", payload.BlockNumber)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "too_many_blocks"
 		} else if hasSeen {
 			// [IGNORE] if the block has already been seen
 			log.Warn("validated already seen message again")
-			return pubsub.ValidationIgnore
+			return pubsub.ValidationIgnore, "seen"
 		}
 
 		// [REJECT] if the signature by the sequencer is not valid
@@ -265,24 +326,31 @@ func BuildBlocksValidator(log log.Logger, cfg *rollup.Config) pubsub.ValidatorEx
 		pub, err := crypto.SigToPub(signingHash[:], signatureBytes)
 		if err != nil {
 			log.Warn("invalid block signature", "err", err, "peer", id)
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, "invalid_signature"
 		}
 		addr := crypto.PubkeyToAddress(*pub)
 
-		// TODO: in the future we can support multiple valid p2p addresses.
-		if addr != cfg.P2PSequencerAddress {
-			log.Warn("unexpected block author", "err", err, "peer", id)
-			return pubsub.ValidationReject
+		// [REJECT] if the block is not signed by a sequencer key that is authorized at the block time
+		if !cfg.IsP2PSequencerAddress(addr, uint64(payload.Timestamp)) {
+			log.Warn("unexpected block author", "author", addr, "timestamp", uint64(payload.Timestamp), "peer", id)
+			return pubsub.ValidationReject, "unexpected_author"
 		}
 
 		// mark it as seen. (note: with concurrent validation more than 5 blocks may be marked as seen still,
 		// but validator concurrency is limited anyway)
-		seen.(*seenBlocks).markSeen(payload.BlockHash)
+		// Two different validly signed blocks at the same height, on the same parent, prove that the sequencer equivocated.
+		if first := seen.(*seenBlocks).markSeen(payload.BlockHash, payload.ParentHash, message.Data); first != nil && evidence != nil {
+			if e, err := NewEquivocation(cfg, first, message.Data); err != nil {
+				log.Warn("failed to build equivocation evidence", "height", uint64(payload.BlockNumber), "err", err)
+			} else {
+				evidence.RecordEquivocation(e)
+			}
+		}
 
 		// remember the decoded payload for later usage in topic subscriber.
-		message.ValidatorData = &payload
-		return pubsub.ValidationAccept
-	}
+		message.ValidatorData = payload
+		return pubsub.ValidationAccept, ""
+	}, nil
 }
 
 type GossipIn interface {
@@ -299,19 +367,53 @@ type GossipOut interface {
 	Close() error
 }
 
+// publisher publishes blocks on the version of the blocks topic that is active at the block time,
+// and joins and leaves the versions of the blocks topic as they activate.
 type publisher struct {
-	log         log.Logger
-	cfg         *rollup.Config
-	blocksTopic *pubsub.Topic
+	log      log.Logger
+	cfg      *rollup.Config
+	self     peer.ID
+	ps       *pubsub.PubSub
+	gossipIn GossipIn
+	evidence *EvidenceStore
+	stats    *GossipStats
+
+	mu     sync.Mutex
+	topics map[uint64]*joinedBlocksTopic // by version
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 var _ GossipOut = (*publisher)(nil)
 
+// BlocksTopicPeers returns the peers of all joined versions of the blocks topic.
 func (p *publisher) BlocksTopicPeers() []peer.ID {
-	return p.blocksTopic.ListPeers()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := make(map[peer.ID]struct{})
+	var out []peer.ID
+	for _, t := range p.topics {
+		for _, id := range t.topic.ListPeers() {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				out = append(out, id)
+			}
+		}
+	}
+	return out
 }
 
 func (p *publisher) PublishL2Payload(ctx context.Context, payload *l2.ExecutionPayload, signer Signer) error {
+	version := p.cfg.P2PBlocksTopicVersion(uint64(payload.Timestamp))
+	p.mu.Lock()
+	t, ok := p.topics[version]
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("cannot publish block with timestamp %d, blocks topic version %d is not joined", uint64(payload.Timestamp), version)
+	}
+
 	res := msgBufPool.Get().(*[]byte)
 	buf := bytes.NewBuffer((*res)[:0])
 	defer func() {
@@ -320,7 +422,7 @@ func (p *publisher) PublishL2Payload(ctx context.Context, payload *l2.ExecutionP
 	}()
 
 	buf.Write(make([]byte, 65))
-	if _, err := payload.MarshalSSZ(buf); err != nil {
+	if err := t.format.encode(buf, payload); err != nil {
 		return fmt.Errorf("failed to encoded execution payload to publish: %v", err)
 	}
 	data := buf.Bytes()
@@ -335,48 +437,57 @@ func (p *publisher) PublishL2Payload(ctx context.Context, payload *l2.ExecutionP
 	// This also copies the data, freeing up the original buffer to go back into the pool
 	out := snappy.Encode(nil, data)
 
-	return p.blocksTopic.Publish(ctx, out)
+	return t.topic.Publish(ctx, out)
 }
 
 func (p *publisher) Close() error {
-	return p.blocksTopic.Close()
+	p.cancel()
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var result *multierror.Error
+	for version, t := range p.topics {
+		delete(p.topics, version)
+		if err := p.leaveBlocksTopic(t); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
 }
 
-func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn) (GossipOut, error) {
-	val := logValidationResult(self, "validated block", log, BuildBlocksValidator(log, cfg))
-	blocksTopicName := blocksTopicV1(cfg)
-	err := ps.RegisterTopicValidator(blocksTopicName,
-		val,
-		pubsub.WithValidatorTimeout(3*time.Second),
-		pubsub.WithValidatorConcurrency(4))
+// JoinGossip joins the versions of the blocks topic that are active now,
+// and switches between versions of the blocks topic as they activate.
+func JoinGossip(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn, evidence *EvidenceStore, stats *GossipStats) (GossipOut, error) {
+	p, err := newPublisher(p2pCtx, self, ps, log, cfg, gossipIn, evidence, stats)
 	if err != nil {
-		return nil, fmt.Errorf("failed to register blocks gossip topic: %v", err)
-	}
-	blocksTopic, err := ps.Join(blocksTopicName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to join blocks gossip topic: %v", err)
+		return nil, err
 	}
-	blocksTopicEvents, err := blocksTopic.EventHandler()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create blocks gossip topic handler: %v", err)
+	if err := p.updateBlocksTopics(uint64(time.Now().Unix())); err != nil {
+		_ = p.Close()
+		return nil, err
 	}
-	go LogTopicEvents(p2pCtx, log.New("topic", "blocks"), blocksTopicEvents)
-
-	// TODO: block topic scoring parameters
-	// See prysm: https://github.com/prysmaticlabs/prysm/blob/develop/beacon-chain/p2p/gossip_scoring_params.go
-	// And research from lighthouse: https://gist.github.com/blacktemplar/5c1862cb3f0e32a1a7fb0b25e79e6e2c
-	// And docs: https://github.com/libp2p/specs/blob/master/pubsub/gossipsub/gossipsub-v1.1.md#topic-parameter-calculation-and-decay
-	//err := blocksTopic.SetScoreParams(&pubsub.TopicScoreParams{......})
+	p.wg.Add(1)
+	go p.blocksTopicsLoop()
+	return p, nil
+}
 
-	subscription, err := blocksTopic.Subscribe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe to blocks gossip topic: %v", err)
+// newPublisher creates a publisher that did not join any version of the blocks topic yet.
+func newPublisher(p2pCtx context.Context, self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, gossipIn GossipIn, evidence *EvidenceStore, stats *GossipStats) (*publisher, error) {
+	if err := checkBlocksTopics(cfg); err != nil {
+		return nil, err
 	}
-
-	subscriber := MakeSubscriber(log, BlocksHandler(gossipIn.OnUnsafeL2Payload))
-	go subscriber(p2pCtx, subscription)
-
-	return &publisher{log: log, cfg: cfg, blocksTopic: blocksTopic}, nil
+	p := &publisher{
+		log:      log,
+		cfg:      cfg,
+		self:     self,
+		ps:       ps,
+		gossipIn: gossipIn,
+		evidence: evidence,
+		stats:    stats,
+		topics:   make(map[uint64]*joinedBlocksTopic),
+	}
+	p.ctx, p.cancel = context.WithCancel(p2pCtx)
+	return p, nil
 }
 
 type TopicSubscriber func(ctx context.Context, sub *pubsub.Subscription)
@@ -0,0 +1,171 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+)
+
+// GossipMessageStats counts the messages received on a topic, by the result of validation.
+type GossipMessageStats struct {
+	Received   uint64            `json:"received"`
+	Accepted   uint64            `json:"accepted"`
+	Ignored    uint64            `json:"ignored"`
+	Rejected   map[string]uint64 `json:"rejected"`   // by reason
+	Duplicates uint64            `json:"duplicates"` // dropped before validation, the message was already seen
+	// FirstDeliveries counts the messages that were accepted and delivered first by the peer.
+	FirstDeliveries uint64 `json:"firstDeliveries"`
+	// AvgDeliveryLatency is the average time between the payload timestamp and the first delivery of the payload.
+	AvgDeliveryLatency time.Duration `json:"avgDeliveryLatency"`
+
+	latencySum time.Duration
+}
+
+func (s *GossipMessageStats) copy() *GossipMessageStats {
+	out := *s
+	out.Rejected = make(map[string]uint64, len(s.Rejected))
+	for reason, count := range s.Rejected {
+		out.Rejected[reason] = count
+	}
+	if s.FirstDeliveries > 0 {
+		out.AvgDeliveryLatency = s.latencySum / time.Duration(s.FirstDeliveries)
+	}
+	return &out
+}
+
+// TopicGossipStats are the message stats of a topic, in total and per peer.
+type TopicGossipStats struct {
+	Total *GossipMessageStats             `json:"total"`
+	Peers map[peer.ID]*GossipMessageStats `json:"peers"` // connected peers only
+}
+
+// GossipStats tracks the message stats of the gossip topics.
+// Validation results, with the reason of rejection, are recorded by the topic validators.
+// The remaining events are traced from the gossip router.
+type GossipStats struct {
+	self peer.ID
+
+	mu     sync.Mutex
+	topics map[string]*TopicGossipStats
+}
+
+var _ pubsub.RawTracer = (*GossipStats)(nil)
+
+func NewGossipStats(self peer.ID) *GossipStats {
+	return &GossipStats{self: self, topics: make(map[string]*TopicGossipStats)}
+}
+
+// update applies fn to the total and peer stats of the topic. Messages published by the node itself are not counted.
+func (s *GossipStats) update(topic string, from peer.ID, fn func(st *GossipMessageStats)) {
+	if from == s.self {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ts, ok := s.topics[topic]
+	if !ok {
+		ts = &TopicGossipStats{Total: &GossipMessageStats{}, Peers: make(map[peer.ID]*GossipMessageStats)}
+		s.topics[topic] = ts
+	}
+	ps, ok := ts.Peers[from]
+	if !ok {
+		ps = &GossipMessageStats{}
+		ts.Peers[from] = ps
+	}
+	fn(ts.Total)
+	fn(ps)
+}
+
+func reject(st *GossipMessageStats, reason string) {
+	if st.Rejected == nil {
+		st.Rejected = make(map[string]uint64)
+	}
+	st.Rejected[reason] += 1
+}
+
+// RecordValidation records the result of validating a message from a peer.
+// The reason is the reason of rejection, and is ignored for other results.
+func (s *GossipStats) RecordValidation(topic string, from peer.ID, res pubsub.ValidationResult, reason string) {
+	s.update(topic, from, func(st *GossipMessageStats) {
+		st.Received += 1
+		switch res {
+		case pubsub.ValidationAccept:
+			st.Accepted += 1
+		case pubsub.ValidationIgnore:
+			st.Ignored += 1
+		default:
+			reject(st, reason)
+		}
+	})
+}
+
+// Snapshot returns a copy of the stats of all topics.
+func (s *GossipStats) Snapshot() map[string]*TopicGossipStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]*TopicGossipStats, len(s.topics))
+	for topic, ts := range s.topics {
+		cp := &TopicGossipStats{Total: ts.Total.copy(), Peers: make(map[peer.ID]*GossipMessageStats, len(ts.Peers))}
+		for id, ps := range ts.Peers {
+			cp.Peers[id] = ps.copy()
+		}
+		out[topic] = cp
+	}
+	return out
+}
+
+func (s *GossipStats) DeliverMessage(msg *pubsub.Message) {
+	var latency time.Duration
+	if payload, ok := msg.ValidatorData.(*l2.ExecutionPayload); ok {
+		latency = time.Since(time.Unix(int64(payload.Timestamp), 0))
+	}
+	s.update(msg.GetTopic(), msg.ReceivedFrom, func(st *GossipMessageStats) {
+		st.FirstDeliveries += 1
+		st.latencySum += latency
+	})
+}
+
+func (s *GossipStats) RejectMessage(msg *pubsub.Message, reason string) {
+	switch reason {
+	case pubsub.RejectValidationFailed, pubsub.RejectValidationIgnored, pubsub.RejectSelfOrigin:
+		// validation results are recorded by the validator, with a more specific reason
+		return
+	}
+	s.update(msg.GetTopic(), msg.ReceivedFrom, func(st *GossipMessageStats) {
+		st.Received += 1
+		reject(st, reason)
+	})
+}
+
+func (s *GossipStats) DuplicateMessage(msg *pubsub.Message) {
+	s.update(msg.GetTopic(), msg.ReceivedFrom, func(st *GossipMessageStats) {
+		st.Received += 1
+		st.Duplicates += 1
+	})
+}
+
+// RemovePeer drops the stats of a peer that is removed from the gossip router. The topic totals are kept.
+func (s *GossipStats) RemovePeer(p peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ts := range s.topics {
+		delete(ts.Peers, p)
+	}
+}
+
+func (s *GossipStats) AddPeer(p peer.ID, proto protocol.ID)     {}
+func (s *GossipStats) Join(topic string)                        {}
+func (s *GossipStats) Leave(topic string)                       {}
+func (s *GossipStats) Graft(p peer.ID, topic string)            {}
+func (s *GossipStats) Prune(p peer.ID, topic string)            {}
+func (s *GossipStats) ValidateMessage(msg *pubsub.Message)      {}
+func (s *GossipStats) ThrottlePeer(p peer.ID)                   {}
+func (s *GossipStats) RecvRPC(rpc *pubsub.RPC)                  {}
+func (s *GossipStats) SendRPC(rpc *pubsub.RPC, p peer.ID)       {}
+func (s *GossipStats) DropRPC(rpc *pubsub.RPC, p peer.ID)       {}
+func (s *GossipStats) UndeliverableMessage(msg *pubsub.Message) {}
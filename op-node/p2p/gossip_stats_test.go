@@ -0,0 +1,92 @@
+package p2p
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestGossipStats(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901), P2PSequencerAddress: crypto.PubkeyToAddress(priv.PublicKey)}
+	signer := NewLocalSigner(priv)
+	logger := testlog.Logger(t, log.LvlCrit)
+
+	self, good, bad := peer.ID("self"), peer.ID("good"), peer.ID("bad")
+	stats := NewGossipStats(self)
+	blocksValidator, err := buildBlocksValidator(logger, cfg, 0, nil)
+	require.NoError(t, err)
+	validator := logValidationResult(self, "validated block", logger, stats, blocksValidator)
+	topic := blocksTopic(cfg, 0)
+	receive := func(from peer.ID, data []byte) *pubsub.Message {
+		msg := &pubsub.Message{Message: &pb.Message{Data: data, Topic: &topic}, ReceivedFrom: from}
+		if validator(context.Background(), from, msg) == pubsub.ValidationAccept {
+			stats.DeliverMessage(msg)
+		}
+		return msg
+	}
+
+	receive(self, signedBlockMessage(t, cfg, signer, 9, common.Hash{0x9}))
+	msg := receive(good, signedBlockMessage(t, cfg, signer, 10, common.Hash{0xa}))
+	stats.DuplicateMessage(&pubsub.Message{Message: msg.Message, ReceivedFrom: bad})
+	receive(bad, msg.Data)
+	receive(bad, []byte("not snappy"))
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	receive(bad, signedBlockMessage(t, cfg, NewLocalSigner(otherKey), 11, common.Hash{0xb}))
+	stats.RejectMessage(&pubsub.Message{Message: msg.Message, ReceivedFrom: bad}, pubsub.RejectValidationThrottled)
+	stats.RejectMessage(&pubsub.Message{Message: msg.Message, ReceivedFrom: bad}, pubsub.RejectValidationFailed)
+
+	snap := stats.Snapshot()
+	require.Len(t, snap, 1)
+	ts := snap[topic]
+	require.NotContains(t, ts.Peers, self, "own messages are not counted")
+
+	g := ts.Peers[good]
+	require.Equal(t, uint64(1), g.Received)
+	require.Equal(t, uint64(1), g.Accepted)
+	require.Equal(t, uint64(1), g.FirstDeliveries)
+	require.Empty(t, g.Rejected)
+	require.Less(t, g.AvgDeliveryLatency, 10*time.Second)
+
+	b := ts.Peers[bad]
+	require.Equal(t, uint64(5), b.Received)
+	require.Equal(t, uint64(0), b.Accepted)
+	require.Equal(t, uint64(1), b.Duplicates)
+	require.Equal(t, uint64(1), b.Ignored, "same block, already seen")
+	require.Equal(t, map[string]uint64{
+		"invalid_snappy":                 1,
+		"unexpected_author":              1,
+		pubsub.RejectValidationThrottled: 1,
+	}, b.Rejected)
+	require.Equal(t, uint64(0), b.FirstDeliveries)
+
+	require.Equal(t, uint64(6), ts.Total.Received)
+	require.Equal(t, uint64(1), ts.Total.Accepted)
+	require.Equal(t, uint64(3), sumRejected(ts.Total))
+
+	stats.RemovePeer(bad)
+	ts = stats.Snapshot()[topic]
+	require.NotContains(t, ts.Peers, bad)
+	require.Equal(t, uint64(6), ts.Total.Received, "totals are kept")
+}
+
+func sumRejected(st *GossipMessageStats) (out uint64) {
+	for _, count := range st.Rejected {
+		out += count
+	}
+	return out
+}
@@ -11,11 +11,13 @@ import (
 
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-peerstore/pstoreds"
 	lconf "github.com/libp2p/go-libp2p/config"
 	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
 	tcp "github.com/libp2p/go-tcp-transport"
+	ws "github.com/libp2p/go-ws-transport"
 	ma "github.com/multiformats/go-multiaddr"
 	madns "github.com/multiformats/go-multiaddr-dns"
 )
@@ -30,6 +32,7 @@ type extraHost struct {
 	host.Host
 	gater   ConnectionGater
 	connMgr connmgr.ConnManager
+	rcMgr   network.ResourceManager // may be nil
 }
 
 func (e *extraHost) ConnectionGater() ConnectionGater {
@@ -40,6 +43,17 @@ func (e *extraHost) ConnectionManager() connmgr.ConnManager {
 	return e.connMgr
 }
 
+func (e *extraHost) Close() error {
+	err := e.Host.Close()
+	// the resource manager is not closed by the host itself
+	if e.rcMgr != nil {
+		if rcErr := e.rcMgr.Close(); rcErr != nil && err == nil {
+			err = rcErr
+		}
+	}
+	return err
+}
+
 var _ ExtraHostFeatures = (*extraHost)(nil)
 
 func (conf *Config) Host(log log.Logger) (host.Host, error) {
@@ -76,17 +90,18 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 		return nil, fmt.Errorf("failed to open connection manager: %v", err)
 	}
 
-	listenAddr, err := addrFromIPAndPort(conf.ListenIP, conf.ListenTCPPort)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make listen addr: %v", err)
+	var rcMngr network.ResourceManager // disabled if nil
+	if conf.ResourceMngr != nil {
+		rcMngr, err = conf.ResourceMngr(conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open resource manager: %v", err)
+		}
 	}
-	tcpTransport, err := lconf.TransportConstructor(
-		tcp.NewTCPTransport,
-		tcp.WithConnectionTimeout(time.Minute*60)) // break unused connections
+
+	transports, listenAddrs, err := conf.transports()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create TCP transport: %v", err)
+		return nil, err
 	}
-	// TODO: technically we can also run the node on websocket and QUIC transports. Maybe in the future?
 
 	var nat lconf.NATManagerC // disabled if nil
 	if conf.NAT {
@@ -98,11 +113,11 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 		UserAgent: conf.UserAgent,
 
 		PeerKey:            priv,
-		Transports:         []lconf.TptC{tcpTransport},
+		Transports:         transports,
 		Muxers:             conf.HostMux,
 		SecurityTransports: conf.HostSecurity,
 		Insecure:           conf.NoTransportSecurity,
-		PSK:                nil, // TODO: expose private subnet option to CLI / testing
+		PSK:                conf.PSK, // nil if on the public network
 		DialTimeout:        conf.TimeoutDial,
 		// No relay services, direct connections between peers only.
 		RelayCustom:        false,
@@ -110,12 +125,12 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 		EnableRelayService: false,
 		RelayServiceOpts:   nil,
 		// host will start and listen to network directly after construction from config.
-		ListenAddrs: []ma.Multiaddr{listenAddr},
+		ListenAddrs: listenAddrs,
 
 		AddrsFactory:      nil,
 		ConnectionGater:   connGtr,
 		ConnManager:       connMngr,
-		ResourceManager:   nil, // TODO use resource manager interface to manage resources per peer better.
+		ResourceManager:   rcMngr,
 		NATManager:        nat,
 		Peerstore:         ps,
 		Reporter:          conf.BandwidthMetrics, // may be nil if disabled
@@ -141,6 +156,9 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 	}
 	h, err := p2pConf.NewNode()
 	if err != nil {
+		if rcMngr != nil {
+			_ = rcMngr.Close()
+		}
 		return nil, err
 	}
 	for _, peerAddr := range conf.StaticPeers {
@@ -162,7 +180,7 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 			}
 		}()
 	}
-	out := &extraHost{Host: h, connMgr: connMngr}
+	out := &extraHost{Host: h, connMgr: connMngr, rcMgr: rcMngr}
 	// Only add the connection gater if it offers the full interface we're looking for.
 	if g, ok := connGtr.(ConnectionGater); ok {
 		out.gater = g
@@ -170,13 +188,71 @@ func (conf *Config) Host(log log.Logger) (host.Host, error) {
 	return out, nil
 }
 
+// transports creates the TCP transport, and the QUIC and WebSocket transports if enabled,
+// along with the addresses to listen on.
+func (conf *Config) transports() ([]lconf.TptC, []ma.Multiaddr, error) {
+	listenAddr, err := addrFromIPAndPort(conf.ListenIP, conf.ListenTCPPort)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make listen addr: %v", err)
+	}
+	tcpTransport, err := lconf.TransportConstructor(
+		tcp.NewTCPTransport,
+		tcp.WithConnectionTimeout(time.Minute*60)) // break unused connections
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create TCP transport: %v", err)
+	}
+	transports := []lconf.TptC{tcpTransport}
+	listenAddrs := []ma.Multiaddr{listenAddr}
+
+	if conf.EnableQUIC {
+		quicAddr, err := quicAddrFromIPAndPort(conf.ListenIP, conf.ListenQUICPort)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to make QUIC listen addr: %v", err)
+		}
+		quicTransport, err := newQUICTransport()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create QUIC transport: %v", err)
+		}
+		transports = append(transports, quicTransport)
+		listenAddrs = append(listenAddrs, quicAddr)
+	}
+
+	if conf.EnableWS {
+		wsAddr, err := wsAddrFromIPAndPort(conf.ListenIP, conf.ListenWSPort)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to make WebSocket listen addr: %v", err)
+		}
+		wsTransport, err := lconf.TransportConstructor(ws.New)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create WebSocket transport: %v", err)
+		}
+		transports = append(transports, wsTransport)
+		listenAddrs = append(listenAddrs, wsAddr)
+	}
+	return transports, listenAddrs, nil
+}
+
+func ipScheme(ip net.IP) (string, net.IP) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return "ip4", ip4
+	}
+	return "ip6", ip
+}
+
 // Creates a multi-addr to bind to. Does not contain a PeerID component (required for usage by external peers)
 func addrFromIPAndPort(ip net.IP, port uint16) (ma.Multiaddr, error) {
-	ipScheme := "ip4"
-	if ip4 := ip.To4(); ip4 == nil {
-		ipScheme = "ip6"
-	} else {
-		ip = ip4
-	}
-	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", ipScheme, ip.String(), port))
+	scheme, ip := ipScheme(ip)
+	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", scheme, ip.String(), port))
+}
+
+// Creates a QUIC multi-addr to bind to. Does not contain a PeerID component.
+func quicAddrFromIPAndPort(ip net.IP, port uint16) (ma.Multiaddr, error) {
+	scheme, ip := ipScheme(ip)
+	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/udp/%d/quic", scheme, ip.String(), port))
+}
+
+// Creates a WebSocket multi-addr to bind to. Does not contain a PeerID component.
+func wsAddrFromIPAndPort(ip net.IP, port uint16) (ma.Multiaddr, error) {
+	scheme, ip := ipScheme(ip)
+	return ma.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d/ws", scheme, ip.String(), port))
 }
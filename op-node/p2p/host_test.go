@@ -1,6 +1,7 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
@@ -21,10 +22,12 @@ import (
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/pnet"
 	tswarm "github.com/libp2p/go-libp2p-swarm/testing"
 	yamux "github.com/libp2p/go-libp2p-yamux"
 	lconf "github.com/libp2p/go-libp2p/config"
 	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
 )
 
@@ -131,7 +134,7 @@ func TestP2PFull(t *testing.T) {
 	// TODO: maybe swap the order of sec/mux preferences, to test that negotiation works
 
 	logA := testlog.Logger(t, log.LvlError).New("host", "A")
-	nodeA, err := NewNodeP2P(context.Background(), &rollup.Config{}, logA, &confA, &mockGossipIn{})
+	nodeA, err := NewNodeP2P(context.Background(), &rollup.Config{}, logA, &confA, &mockGossipIn{}, nil, nil)
 	require.NoError(t, err)
 	defer nodeA.Close()
 
@@ -154,7 +157,7 @@ func TestP2PFull(t *testing.T) {
 
 	logB := testlog.Logger(t, log.LvlError).New("host", "B")
 
-	nodeB, err := NewNodeP2P(context.Background(), &rollup.Config{}, logB, &confB, &mockGossipIn{})
+	nodeB, err := NewNodeP2P(context.Background(), &rollup.Config{}, logB, &confB, &mockGossipIn{}, nil, nil)
 	require.NoError(t, err)
 	defer nodeB.Close()
 	hostB := nodeB.Host()
@@ -224,6 +227,13 @@ func TestP2PFull(t *testing.T) {
 	require.NoError(t, p2pClientA.UnprotectPeer(ctx, hostB.ID()))
 }
 
+func TestP2PDisabledEvidence(t *testing.T) {
+	// a node without p2p host does not track equivocations
+	api := NewP2PAPIBackend(&NodeP2P{}, testlog.Logger(t, log.LvlError))
+	_, err := api.Equivocations(context.Background())
+	require.ErrorIs(t, err, DisabledEvidence)
+}
+
 func TestDiscovery(t *testing.T) {
 	pA, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
 	require.NoError(t, err, "failed to generate new p2p priv key")
@@ -288,7 +298,7 @@ func TestDiscovery(t *testing.T) {
 	resourcesCtx, resourcesCancel := context.WithCancel(context.Background())
 	defer resourcesCancel()
 
-	nodeA, err := NewNodeP2P(context.Background(), rollupCfg, logA, &confA, &mockGossipIn{})
+	nodeA, err := NewNodeP2P(context.Background(), rollupCfg, logA, &confA, &mockGossipIn{}, nil, nil)
 	require.NoError(t, err)
 	defer nodeA.Close()
 	hostA := nodeA.Host()
@@ -303,7 +313,7 @@ func TestDiscovery(t *testing.T) {
 	confB.DiscoveryDB = discDBC
 
 	// Start B
-	nodeB, err := NewNodeP2P(context.Background(), rollupCfg, logB, &confB, &mockGossipIn{})
+	nodeB, err := NewNodeP2P(context.Background(), rollupCfg, logB, &confB, &mockGossipIn{}, nil, nil)
 	require.NoError(t, err)
 	defer nodeB.Close()
 	hostB := nodeB.Host()
@@ -318,7 +328,7 @@ func TestDiscovery(t *testing.T) {
 		}})
 
 	// Start C
-	nodeC, err := NewNodeP2P(context.Background(), rollupCfg, logC, &confC, &mockGossipIn{})
+	nodeC, err := NewNodeP2P(context.Background(), rollupCfg, logC, &confC, &mockGossipIn{}, nil, nil)
 	require.NoError(t, err)
 	defer nodeC.Close()
 	hostC := nodeC.Host()
@@ -352,3 +362,74 @@ func TestP2PMocknet(t *testing.T) {
 	require.Equal(t, hostA.Network().Connectedness(hostC.ID()), network.Connected)
 	require.Equal(t, hostB.Network().Connectedness(hostC.ID()), network.Connected)
 }
+
+func TestP2PWebSocket(t *testing.T) {
+	confA := TestingConfig(t)
+	confA.EnableWS = true
+	confB := TestingConfig(t)
+	confB.EnableWS = true
+	hostA, err := confA.Host(testlog.Logger(t, log.LvlError).New("host", "A"))
+	require.NoError(t, err, "failed to launch host A")
+	defer hostA.Close()
+	hostB, err := confB.Host(testlog.Logger(t, log.LvlError).New("host", "B"))
+	require.NoError(t, err, "failed to launch host B")
+	defer hostB.Close()
+
+	ports := FindActivePorts(hostB)
+	require.NotZero(t, ports.TCP)
+	require.NotZero(t, ports.WS)
+	require.NotEqual(t, ports.TCP, ports.WS)
+	require.Zero(t, ports.QUIC)
+
+	// only dial the WebSocket address of B
+	wsAddr, err := wsAddrFromIPAndPort(confB.ListenIP, ports.WS)
+	require.NoError(t, err)
+	err = hostA.Connect(context.Background(), peer.AddrInfo{ID: hostB.ID(), Addrs: []ma.Multiaddr{wsAddr}})
+	require.NoError(t, err, "failed to connect to peer B from peer A over WebSocket")
+	conns := hostA.Network().ConnsToPeer(hostB.ID())
+	require.Len(t, conns, 1)
+	_, err = conns[0].RemoteMultiaddr().ValueForProtocol(ma.P_WS)
+	require.NoError(t, err, "expected WebSocket connection")
+}
+
+func TestP2PPrivateNetwork(t *testing.T) {
+	psk := pnet.PSK(bytes.Repeat([]byte{0x42}, 32))
+	confA := TestingConfig(t)
+	confA.PSK = psk
+	confB := TestingConfig(t)
+	confB.PSK = psk
+	confC := TestingConfig(t) // public network
+	confD := TestingConfig(t)
+	confD.PSK = pnet.PSK(bytes.Repeat([]byte{0x43}, 32)) // another private network
+	for _, conf := range []*Config{confA, confB, confC, confD} {
+		require.NoError(t, conf.Check())
+	}
+
+	logger := testlog.Logger(t, log.LvlError)
+	hostA, err := confA.Host(logger.New("host", "A"))
+	require.NoError(t, err, "failed to launch host A")
+	defer hostA.Close()
+	hostB, err := confB.Host(logger.New("host", "B"))
+	require.NoError(t, err, "failed to launch host B")
+	defer hostB.Close()
+	hostC, err := confC.Host(logger.New("host", "C"))
+	require.NoError(t, err, "failed to launch host C")
+	defer hostC.Close()
+	hostD, err := confD.Host(logger.New("host", "D"))
+	require.NoError(t, err, "failed to launch host D")
+	defer hostD.Close()
+
+	err = hostA.Connect(context.Background(), peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()})
+	require.NoError(t, err, "failed to connect to peer B on the same private network")
+	err = hostA.Connect(context.Background(), peer.AddrInfo{ID: hostC.ID(), Addrs: hostC.Addrs()})
+	require.Error(t, err, "must not connect to peer C on the public network")
+	err = hostC.Connect(context.Background(), peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()})
+	require.Error(t, err, "must not connect to peer B from the public network")
+	err = hostA.Connect(context.Background(), peer.AddrInfo{ID: hostD.ID(), Addrs: hostD.Addrs()})
+	require.Error(t, err, "must not connect to peer D on another private network")
+	err = hostD.Connect(context.Background(), peer.AddrInfo{ID: hostB.ID(), Addrs: hostB.Addrs()})
+	require.Error(t, err, "must not connect to peer B from another private network")
+
+	confA.NoDiscovery = false
+	require.Error(t, confA.Check(), "discovery is not allowed on private networks")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/libp2p/go-libp2p-core/connmgr"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
 )
@@ -28,14 +29,20 @@ type NodeP2P struct {
 	dv5Udp   *discover.UDPv5  // p2p discovery service
 	gs       *pubsub.PubSub   // p2p gossip router
 	gsOut    GossipOut        // p2p gossip application interface for publishing
+	evidence *EvidenceStore   // evidence of sequencer equivocations, seen on the blocks topic
+	status   *statusProtocol  // status handshake with peers
+	stats    *GossipStats     // message stats of the gossip topics
+	policy   *PeerPolicy      // connection policy of the peer groups, nil if there are none
 }
 
-func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn) (*NodeP2P, error) {
+// NewNodeP2P creates the p2p node. The status source provides the sync status to share with peers, and may be nil.
+// Equivocations are counted in the metrics, if not nil.
+func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn, statusSource StatusSource, metrics EquivocationMetrics) (*NodeP2P, error) {
 	if setup == nil {
 		return nil, errors.New("p2p node cannot be created without setup")
 	}
 	var n NodeP2P
-	if err := n.init(resourcesCtx, rollupCfg, log, setup, gossipIn); err != nil {
+	if err := n.init(resourcesCtx, rollupCfg, log, setup, gossipIn, statusSource, metrics); err != nil {
 		closeErr := n.Close()
 		if closeErr != nil {
 			log.Error("failed to close p2p after starting with err", "closeErr", closeErr, "err", err)
@@ -48,7 +55,7 @@ func NewNodeP2P(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.
 	return &n, nil
 }
 
-func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn) error {
+func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, log log.Logger, setup SetupP2P, gossipIn GossipIn, statusSource StatusSource, metrics EquivocationMetrics) error {
 	var err error
 	// nil if disabled.
 	n.host, err = setup.Host(log)
@@ -67,26 +74,35 @@ func (n *NodeP2P) init(resourcesCtx context.Context, rollupCfg *rollup.Config, l
 		}
 		// notify of any new connections/streams/etc.
 		n.host.Network().Notify(NewNetworkNotifier(log))
+		n.policy = setup.PeerPolicy(log.New("p2p", "policy"))
+		if n.policy != nil {
+			n.policy.Start(n.host, n.connMgr)
+		}
 		// unregister identify-push handler. Only identifying on dial is fine, and more robust against spam
 		n.host.RemoveStreamHandler(identify.IDDelta)
-		n.gs, err = NewGossipSub(resourcesCtx, n.host, rollupCfg)
+		// exchange the chain and sync status with every new peer
+		n.status = newStatusProtocol(log.New("p2p", "status"), rollupCfg, n.host, statusSource)
+		n.stats = NewGossipStats(n.host.ID())
+		n.gs, err = NewGossipSub(resourcesCtx, n.host, rollupCfg, log, n.stats)
 		if err != nil {
 			return fmt.Errorf("failed to start gossipsub router: %v", err)
 		}
 
-		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, gossipIn)
+		n.evidence, err = setup.Evidence(log.New("p2p", "evidence"), metrics)
 		if err != nil {
-			return fmt.Errorf("failed to join blocks gossip topic: %v", err)
+			return fmt.Errorf("failed to open evidence store: %v", err)
 		}
-		log.Info("started p2p host", "addrs", n.host.Addrs(), "peerID", n.host.ID().Pretty())
 
-		tcpPort, err := FindActiveTCPPort(n.host)
+		n.gsOut, err = JoinGossip(resourcesCtx, n.host.ID(), n.gs, log, rollupCfg, gossipIn, n.evidence, n.stats)
 		if err != nil {
-			log.Warn("failed to find what TCP port p2p is binded to", "err", err)
+			return fmt.Errorf("failed to join blocks gossip topic: %v", err)
 		}
+		log.Info("started p2p host", "addrs", n.host.Addrs(), "peerID", n.host.ID().Pretty())
+
+		ports := FindActivePorts(n.host)
 
 		// All nil if disabled.
-		n.dv5Local, n.dv5Udp, err = setup.Discovery(log.New("p2p", "discv5"), rollupCfg, tcpPort)
+		n.dv5Local, n.dv5Udp, err = setup.Discovery(log.New("p2p", "discv5"), rollupCfg, ports)
 		if err != nil {
 			return fmt.Errorf("failed to start discv5: %v", err)
 		}
@@ -114,6 +130,23 @@ func (n *NodeP2P) GossipOut() GossipOut {
 	return n.gsOut
 }
 
+func (n *NodeP2P) Evidence() *EvidenceStore {
+	return n.evidence
+}
+
+func (n *NodeP2P) GossipStats() *GossipStats {
+	return n.stats
+}
+
+// SyncPeers returns the connected peers on the same chain, most advanced unsafe head first.
+func (n *NodeP2P) SyncPeers() []peer.ID {
+	return SyncPeers(n.host)
+}
+
+func (n *NodeP2P) PeerPolicy() *PeerPolicy {
+	return n.policy
+}
+
 func (n *NodeP2P) ConnectionGater() ConnectionGater {
 	return n.gater
 }
@@ -127,6 +160,9 @@ func (n *NodeP2P) Close() error {
 	if n.dv5Udp != nil {
 		n.dv5Udp.Close()
 	}
+	if n.policy != nil {
+		n.policy.Close()
+	}
 	if n.gsOut != nil {
 		if err := n.gsOut.Close(); err != nil {
 			result = multierror.Append(result, fmt.Errorf("failed to close gossip cleanly: %v", err))
@@ -140,19 +176,42 @@ func (n *NodeP2P) Close() error {
 	return result.ErrorOrNil()
 }
 
-func FindActiveTCPPort(h host.Host) (uint16, error) {
-	var tcpPort uint16
-	for _, addr := range h.Addrs() {
-		tcpPortStr, err := addr.ValueForProtocol(ma.P_TCP)
+// HostPorts are the ports the libp2p host is bound to, per transport. A port is 0 if the transport is not active.
+type HostPorts struct {
+	TCP  uint16
+	QUIC uint16
+	WS   uint16
+}
+
+// FindActivePorts finds the first TCP, QUIC and WebSocket port the host is bound to.
+func FindActivePorts(h host.Host) HostPorts {
+	return findPorts(h.Addrs())
+}
+
+// findPorts finds the first TCP, QUIC and WebSocket port in the given addresses.
+func findPorts(addrs []ma.Multiaddr) (out HostPorts) {
+	for _, addr := range addrs {
+		var proto int
+		var port *uint16
+		if _, err := addr.ValueForProtocol(ma.P_QUIC); err == nil {
+			proto, port = ma.P_UDP, &out.QUIC
+		} else if _, err := addr.ValueForProtocol(ma.P_WS); err == nil {
+			proto, port = ma.P_TCP, &out.WS
+		} else {
+			proto, port = ma.P_TCP, &out.TCP
+		}
+		if *port != 0 {
+			continue
+		}
+		portStr, err := addr.ValueForProtocol(proto)
 		if err != nil {
 			continue
 		}
-		v, err := strconv.ParseUint(tcpPortStr, 10, 16)
+		v, err := strconv.ParseUint(portStr, 10, 16)
 		if err != nil {
 			continue
 		}
-		tcpPort = uint16(v)
-		break
+		*port = uint16(v)
 	}
-	return tcpPort, nil
+	return out
 }
@@ -0,0 +1,276 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Names of the peer groups
+const (
+	// PeerGroupTrusted are peers operated by the same party, or otherwise trusted, e.g. other rollup node operators.
+	PeerGroupTrusted = "trusted"
+	// PeerGroupSequencer are the sequencer nodes, to receive new blocks from as fast as possible.
+	PeerGroupSequencer = "sequencer"
+	// PeerGroupReplica are the replicas of this node, e.g. the standby nodes of a sequencer.
+	PeerGroupReplica = "replica"
+)
+
+// redialInterval is the interval at which disconnected peers of groups that are always redialed are dialed again.
+const redialInterval = 10 * time.Second
+
+// PeerGroup is a named group of peers, with a connection policy.
+type PeerGroup struct {
+	Name  string
+	Peers []peer.AddrInfo
+	// Slots is the number of connection slots reserved for peers of the group.
+	// Other peers can only use the slots up to the high-tide peer count that are not reserved.
+	Slots uint
+	// Redial makes the node dial the peers of the group again when they are disconnected.
+	Redial bool
+}
+
+type peerGroupJSON struct {
+	Peers  []string `json:"peers"`
+	Slots  uint     `json:"slots"`
+	Redial bool     `json:"redial"`
+}
+
+// LoadPeerGroups reads the peer groups from a JSON file: an object with a field per group,
+// each with the multiaddr-format peers, the number of reserved slots, and if the peers are always redialed.
+func LoadPeerGroups(path string) ([]*PeerGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peer groups file: %w", err)
+	}
+	var raw map[string]peerGroupJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode peer groups file: %w", err)
+	}
+	var groups []*PeerGroup
+	for name, g := range raw {
+		switch name {
+		case PeerGroupTrusted, PeerGroupSequencer, PeerGroupReplica:
+		default:
+			return nil, fmt.Errorf("unknown peer group %q", name)
+		}
+		group := &PeerGroup{Name: name, Slots: g.Slots, Redial: g.Redial}
+		for i, v := range g.Peers {
+			addr, err := ma.NewMultiaddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("bad multiaddr of peer %d in group %q: %w", i, name, err)
+			}
+			info, err := peer.AddrInfoFromP2pAddr(addr)
+			if err != nil {
+				return nil, fmt.Errorf("bad peer address %d in group %q: %w", i, name, err)
+			}
+			group.Peers = append(group.Peers, *info)
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+// CheckPeerGroups checks that the peer groups are consistent, and do not reserve more than the high-tide peer count.
+func CheckPeerGroups(groups []*PeerGroup, peersHi uint) error {
+	members := make(map[peer.ID]string)
+	reserved := uint(0)
+	for _, g := range groups {
+		if g.Slots > uint(len(g.Peers)) {
+			return fmt.Errorf("peer group %q reserves %d slots, but only has %d peers", g.Name, g.Slots, len(g.Peers))
+		}
+		for _, p := range g.Peers {
+			if other, ok := members[p.ID]; ok {
+				return fmt.Errorf("peer %s is in both group %q and %q", p.ID, other, g.Name)
+			}
+			members[p.ID] = g.Name
+		}
+		reserved += g.Slots
+	}
+	if reserved > peersHi {
+		return fmt.Errorf("peer groups reserve %d slots, more than the high-tide peer count %d", reserved, peersHi)
+	}
+	return nil
+}
+
+// PeerPolicy applies the connection policy of the peer groups:
+// peers of a group are protected from pruning by the connection manager, and redialed if the group says so.
+// Peers that are not in a group, nor otherwise protected, are disconnected
+// when they would use a slot that is reserved for a group.
+type PeerPolicy struct {
+	log     log.Logger
+	groups  []*PeerGroup
+	members map[peer.ID]*PeerGroup
+	// regularSlots is the number of connection slots for peers that are not in a group
+	regularSlots int
+
+	h       host.Host
+	connMgr connmgr.ConnManager // may be nil
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPeerPolicy creates the policy of the peer groups, the groups are expected to be checked already.
+func NewPeerPolicy(log log.Logger, groups []*PeerGroup, peersHi uint) *PeerPolicy {
+	p := &PeerPolicy{log: log, groups: groups, members: make(map[peer.ID]*PeerGroup)}
+	reserved := uint(0)
+	for _, g := range groups {
+		for _, info := range g.Peers {
+			p.members[info.ID] = g
+		}
+		reserved += g.Slots
+	}
+	p.regularSlots = int(peersHi - reserved)
+	return p
+}
+
+// Start applies the policy to the host, and starts redialing the peers of groups that are always redialed.
+func (p *PeerPolicy) Start(h host.Host, connMgr connmgr.ConnManager) {
+	p.h = h
+	p.connMgr = connMgr
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	for _, g := range p.groups {
+		for _, info := range g.Peers {
+			h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
+			// Tagged with the group name, so other protects/unprotects with different tags don't affect this protection.
+			if connMgr != nil {
+				connMgr.Protect(info.ID, g.Name)
+			}
+		}
+	}
+	h.Network().Notify(p)
+	p.wg.Add(1)
+	go p.redialLoop()
+}
+
+// Group returns the name of the group of the peer, or an empty string if the peer is not in a group.
+func (p *PeerPolicy) Group(id peer.ID) string {
+	if g, ok := p.members[id]; ok {
+		return g.Name
+	}
+	return ""
+}
+
+func (p *PeerPolicy) redialLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(redialInterval)
+	defer ticker.Stop()
+	for {
+		p.redial()
+		select {
+		case <-ticker.C:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// redial dials the disconnected peers of the groups that are always redialed.
+func (p *PeerPolicy) redial() {
+	for _, g := range p.groups {
+		if !g.Redial {
+			continue
+		}
+		for _, info := range g.Peers {
+			if p.h.Network().Connectedness(info.ID) == network.Connected {
+				continue
+			}
+			p.wg.Add(1)
+			go func(g *PeerGroup, info peer.AddrInfo) {
+				defer p.wg.Done()
+				ctx, cancel := context.WithTimeout(p.ctx, redialInterval)
+				defer cancel()
+				if err := p.h.Connect(ctx, info); err != nil {
+					p.log.Debug("failed to redial peer", "group", g.Name, "peer", info.ID, "err", err)
+				} else {
+					p.log.Info("redialed peer", "group", g.Name, "peer", info.ID)
+				}
+			}(g, info)
+		}
+	}
+}
+
+// isRegular checks if the peer is not in a group, nor protected otherwise, e.g. as static peer.
+func (p *PeerPolicy) isRegular(id peer.ID) bool {
+	if _, ok := p.members[id]; ok {
+		return false
+	}
+	return p.connMgr == nil || !p.connMgr.IsProtected(id, "")
+}
+
+// Connected disconnects the most recently connected regular peers that use slots reserved for the peer groups.
+func (p *PeerPolicy) Connected(n network.Network, c network.Conn) {
+	if !p.isRegular(c.RemotePeer()) {
+		return
+	}
+	type regularPeer struct {
+		id     peer.ID
+		opened time.Time
+	}
+	var regular []regularPeer
+	for _, id := range n.Peers() {
+		if !p.isRegular(id) {
+			continue
+		}
+		var opened time.Time
+		for _, conn := range n.ConnsToPeer(id) {
+			if t := conn.Stat().Opened; opened.IsZero() || t.Before(opened) {
+				opened = t
+			}
+		}
+		regular = append(regular, regularPeer{id: id, opened: opened})
+	}
+	if len(regular) <= p.regularSlots {
+		return
+	}
+	// the peer that just connected is the most recent, if the connection times are not known
+	newest := c.RemotePeer()
+	sort.SliceStable(regular, func(i, j int) bool {
+		if !regular[i].opened.Equal(regular[j].opened) {
+			return regular[i].opened.Before(regular[j].opened)
+		}
+		return regular[j].id == newest
+	})
+	for _, r := range regular[p.regularSlots:] {
+		p.log.Debug("disconnecting peer, remaining slots are reserved for peer groups", "peer", r.id, "regular", len(regular))
+		// don't block the notification, closing the connection notifies again
+		go func(id peer.ID) {
+			if err := n.ClosePeer(id); err != nil {
+				p.log.Warn("failed to disconnect peer", "peer", id, "err", err)
+			}
+		}(r.id)
+	}
+}
+
+func (p *PeerPolicy) Listen(n network.Network, a ma.Multiaddr)         {}
+func (p *PeerPolicy) ListenClose(n network.Network, a ma.Multiaddr)    {}
+func (p *PeerPolicy) Disconnected(n network.Network, c network.Conn)   {}
+func (p *PeerPolicy) OpenedStream(n network.Network, s network.Stream) {}
+func (p *PeerPolicy) ClosedStream(n network.Network, s network.Stream) {}
+
+// Close stops redialing peers.
+func (p *PeerPolicy) Close() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+	p.h.Network().StopNotify(p)
+}
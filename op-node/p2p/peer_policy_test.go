@@ -0,0 +1,101 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestLoadPeerGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.json")
+	write := func(data string) {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+	write(`{
+		"trusted": {"peers": ["/ip4/127.0.0.1/tcp/9222/p2p/16Uiu2HAmQnWnNwBfcHyBYCt3JiHWTGRJVTxe8vCoNAdW3nyWpPa8"], "slots": 1, "redial": true},
+		"replica": {"peers": ["/ip4/127.0.0.1/tcp/9223/p2p/16Uiu2HAm2y6DXp6THWHCyquczNUh8gVAm4spo6hjP3Ns1dGRiAdE"]}
+	}`)
+	groups, err := LoadPeerGroups(path)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Equal(t, PeerGroupReplica, groups[0].Name)
+	require.False(t, groups[0].Redial)
+	require.Equal(t, uint(0), groups[0].Slots)
+	require.Equal(t, PeerGroupTrusted, groups[1].Name)
+	require.True(t, groups[1].Redial)
+	require.Equal(t, "16Uiu2HAmQnWnNwBfcHyBYCt3JiHWTGRJVTxe8vCoNAdW3nyWpPa8", groups[1].Peers[0].ID.String())
+	require.NoError(t, CheckPeerGroups(groups, 1))
+	require.Error(t, CheckPeerGroups(groups, 0), "more slots reserved than the high tide")
+
+	groups[0].Peers = append(groups[0].Peers, groups[1].Peers[0])
+	require.Error(t, CheckPeerGroups(groups, 10), "peer in two groups")
+	groups[1].Slots = 2
+	require.Error(t, CheckPeerGroups(groups[1:], 10), "more slots than peers")
+
+	write(`{"friends": {"peers": []}}`)
+	_, err = LoadPeerGroups(path)
+	require.Error(t, err, "unknown group")
+	write(`{"trusted": {"peers": ["/ip4/127.0.0.1/tcp/9222"]}}`)
+	_, err = LoadPeerGroups(path)
+	require.Error(t, err, "no peer ID")
+}
+
+func TestPeerPolicy(t *testing.T) {
+	mnet, err := mocknet.WithNPeers(5)
+	require.NoError(t, err)
+	defer mnet.Close()
+	require.NoError(t, mnet.LinkAll())
+	hosts := mnet.Hosts()
+	self, trusted, replica, regularA, regularB := hosts[0], hosts[1], hosts[2], hosts[3], hosts[4]
+
+	groups := []*PeerGroup{
+		{Name: PeerGroupTrusted, Peers: []peer.AddrInfo{{ID: trusted.ID(), Addrs: trusted.Addrs()}}, Slots: 1, Redial: true},
+		{Name: PeerGroupReplica, Peers: []peer.AddrInfo{{ID: replica.ID(), Addrs: replica.Addrs()}}, Slots: 1},
+	}
+	require.NoError(t, CheckPeerGroups(groups, 3))
+	policy := NewPeerPolicy(testlog.Logger(t, log.LvlError), groups, 3)
+	policy.Start(self, nil)
+	defer policy.Close()
+	require.Equal(t, PeerGroupTrusted, policy.Group(trusted.ID()))
+	require.Equal(t, "", policy.Group(regularA.ID()))
+
+	connected := func(h peer.ID) func() bool {
+		return func() bool {
+			return self.Network().Connectedness(h) == network.Connected
+		}
+	}
+	// the trusted peer is dialed right away
+	require.Eventually(t, connected(trusted.ID()), 5*time.Second, 10*time.Millisecond)
+
+	// one slot is left for regular peers
+	_, err = mnet.ConnectPeers(regularA.ID(), self.ID())
+	require.NoError(t, err)
+	require.Eventually(t, connected(regularA.ID()), 5*time.Second, 10*time.Millisecond)
+	_, err = mnet.ConnectPeers(regularB.ID(), self.ID())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return !connected(regularB.ID())() }, 5*time.Second, 10*time.Millisecond)
+	require.True(t, connected(regularA.ID())())
+
+	// the reserved slot is still available to the replica
+	_, err = mnet.ConnectPeers(replica.ID(), self.ID())
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	require.True(t, connected(replica.ID())())
+
+	// the trusted peer is redialed, the replica is not
+	require.NoError(t, self.Network().ClosePeer(trusted.ID()))
+	require.NoError(t, self.Network().ClosePeer(replica.ID()))
+	policy.redial()
+	require.Eventually(t, connected(trusted.ID()), 5*time.Second, 10*time.Millisecond)
+	require.False(t, connected(replica.ID())())
+
+}
@@ -0,0 +1,152 @@
+package p2p
+
+import (
+	"encoding/gob"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+// Peer scoring is modeled after the eth2 gossip scoring parameters, with decay windows expressed in L2 blocks.
+// See prysm: https://github.com/prysmaticlabs/prysm/blob/develop/beacon-chain/p2p/gossip_scoring_params.go
+// And research from lighthouse: https://gist.github.com/blacktemplar/5c1862cb3f0e32a1a7fb0b25e79e6e2c
+// And docs: https://github.com/libp2p/specs/blob/master/pubsub/gossipsub/gossipsub-v1.1.md#topic-parameter-calculation-and-decay
+
+const (
+	decayToZero = 0.01
+
+	// peerScoreInspectFrequency is how often the gossip scores are copied into the peerstore.
+	peerScoreInspectFrequency = 15 * time.Second
+
+	// gossipScoresKey is the peerstore key of the latest gossip scores of a peer.
+	gossipScoresKey = "optimismGossipScores"
+
+	blocksTopicWeight = 0.8
+)
+
+// PeerScoreThresholds are the gossipsub score thresholds:
+// a peer with a single invalid block gets to recover, but peers that keep sending invalid blocks are graylisted,
+// and disconnected from.
+var PeerScoreThresholds = pubsub.PeerScoreThresholds{
+	GossipThreshold:             -4000,
+	PublishThreshold:            -8000,
+	GraylistThreshold:           -16000,
+	AcceptPXThreshold:           100,
+	OpportunisticGraftThreshold: 5,
+}
+
+// scoreDecayInterval is the interval of score decay, one L2 block, at least 1 second.
+func scoreDecayInterval(cfg *rollup.Config) time.Duration {
+	interval := time.Duration(cfg.BlockTime) * time.Second
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// scoreDecay computes the decay factor for a score counter to decay to zero over the given number of L2 blocks.
+func scoreDecay(cfg *rollup.Config, blocks uint64) float64 {
+	interval := scoreDecayInterval(cfg)
+	return pubsub.ScoreParameterDecayWithBase(time.Duration(blocks)*interval, interval, decayToZero)
+}
+
+// BuildPeerScoreParams builds the gossipsub peer score parameters, with decay rates derived from the L2 block time.
+func BuildPeerScoreParams(cfg *rollup.Config) *pubsub.PeerScoreParams {
+	interval := scoreDecayInterval(cfg)
+	return &pubsub.PeerScoreParams{
+		Topics:        make(map[string]*pubsub.TopicScoreParams),
+		TopicScoreCap: 34,
+		// No application specific scoring yet
+		AppSpecificScore:  func(p peer.ID) float64 { return 0 },
+		AppSpecificWeight: 1,
+		// Penalize large numbers of peers from the same IP
+		IPColocationFactorWeight:    -35.11,
+		IPColocationFactorThreshold: 10,
+		// Penalize gossip protocol violations, like re-grafting too early or broken IWANT promises
+		BehaviourPenaltyWeight:    -15.92,
+		BehaviourPenaltyThreshold: 6,
+		BehaviourPenaltyDecay:     scoreDecay(cfg, 320),
+		DecayInterval:             interval,
+		DecayToZero:               decayToZero,
+		RetainScore:               100 * interval,
+	}
+}
+
+// BuildBlocksTopicScoreParams builds the score parameters of the blocks topic.
+// The blocks topic has a single message per L2 block, too low of a rate to penalize missing mesh deliveries.
+// Peers are rewarded for their time in the mesh and first deliveries, and penalized for invalid blocks.
+func BuildBlocksTopicScoreParams(cfg *rollup.Config) *pubsub.TopicScoreParams {
+	return &pubsub.TopicScoreParams{
+		TopicWeight:                    blocksTopicWeight,
+		TimeInMeshWeight:               0.0324,
+		TimeInMeshQuantum:              scoreDecayInterval(cfg),
+		TimeInMeshCap:                  300,
+		FirstMessageDeliveriesWeight:   1,
+		FirstMessageDeliveriesDecay:    scoreDecay(cfg, 640),
+		FirstMessageDeliveriesCap:      23,
+		InvalidMessageDeliveriesWeight: -140.4475,
+		InvalidMessageDeliveriesDecay:  scoreDecay(cfg, 1600),
+	}
+}
+
+type TopicScores struct {
+	TimeInMesh               time.Duration `json:"timeInMesh"`
+	FirstMessageDeliveries   float64       `json:"firstMessageDeliveries"`
+	MeshMessageDeliveries    float64       `json:"meshMessageDeliveries"`
+	InvalidMessageDeliveries float64       `json:"invalidMessageDeliveries"`
+}
+
+// GossipScores is a snapshot of the gossip score of a peer, as stored in the peerstore.
+type GossipScores struct {
+	Total              float64     `json:"total"`
+	Blocks             TopicScores `json:"blocks"`
+	IPColocationFactor float64     `json:"IPColocationFactor"`
+	BehavioralPenalty  float64     `json:"behavioralPenalty"`
+}
+
+func init() {
+	// the peerstore may be persisted, and then uses gob to encode the metadata of peers
+	gob.Register(&GossipScores{})
+}
+
+// gossipScoresInspector copies the gossip scores into the peerstore,
+// and disconnects peers that are below the graylist threshold.
+func gossipScoresInspector(h host.Host, cfg *rollup.Config, log log.Logger) pubsub.ExtendedPeerScoreInspectFn {
+	blocksTopics := allBlocksTopics(cfg)
+	return func(scores map[peer.ID]*pubsub.PeerScoreSnapshot) {
+		for id, snap := range scores {
+			gs := &GossipScores{
+				Total:              snap.Score,
+				IPColocationFactor: snap.IPColocationFactor,
+				BehavioralPenalty:  snap.BehaviourPenalty,
+			}
+			// the versions of the blocks topic are combined, a peer is on more than one only around an upgrade
+			for _, name := range blocksTopics {
+				topic, ok := snap.Topics[name]
+				if !ok {
+					continue
+				}
+				if topic.TimeInMesh > gs.Blocks.TimeInMesh {
+					gs.Blocks.TimeInMesh = topic.TimeInMesh
+				}
+				gs.Blocks.FirstMessageDeliveries += topic.FirstMessageDeliveries
+				gs.Blocks.MeshMessageDeliveries += topic.MeshMessageDeliveries
+				gs.Blocks.InvalidMessageDeliveries += topic.InvalidMessageDeliveries
+			}
+			if err := h.Peerstore().Put(id, gossipScoresKey, gs); err != nil {
+				log.Warn("failed to store gossip scores of peer", "peer", id, "err", err)
+			}
+			if snap.Score < PeerScoreThresholds.GraylistThreshold {
+				log.Warn("disconnecting graylisted peer", "peer", id, "score", snap.Score)
+				if err := h.Network().ClosePeer(id); err != nil {
+					log.Warn("failed to disconnect graylisted peer", "peer", id, "err", err)
+				}
+			}
+		}
+	}
+}
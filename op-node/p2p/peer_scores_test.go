@@ -0,0 +1,70 @@
+package p2p
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+func TestPeerScoreParams(t *testing.T) {
+	for _, blockTime := range []uint64{0, 1, 2, 12} {
+		cfg := &rollup.Config{BlockTime: blockTime, L2ChainID: big.NewInt(901)}
+		mnet, err := mocknet.FullMeshConnected(1)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		// the gossipsub router validates the peer score params and thresholds
+		ps, err := NewGossipSub(ctx, mnet.Hosts()[0], cfg, testlog.Logger(t, log.LvlError), nil)
+		require.NoError(t, err, "block time %d", blockTime)
+		topic, err := ps.Join(blocksTopic(cfg, 0))
+		require.NoError(t, err)
+		require.NoError(t, topic.SetScoreParams(BuildBlocksTopicScoreParams(cfg)), "block time %d", blockTime)
+		cancel()
+		require.NoError(t, mnet.Close())
+	}
+}
+
+func TestGossipScoresInspector(t *testing.T) {
+	cfg := &rollup.Config{BlockTime: 2, L2ChainID: big.NewInt(901)}
+	mnet, err := mocknet.FullMeshConnected(3)
+	require.NoError(t, err)
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+	self, good, bad := hosts[0], hosts[1], hosts[2]
+
+	inspect := gossipScoresInspector(self, cfg, testlog.Logger(t, log.LvlError))
+	inspect(map[peer.ID]*pubsub.PeerScoreSnapshot{
+		good.ID(): {
+			Score: 10,
+			Topics: map[string]*pubsub.TopicScoreSnapshot{
+				blocksTopic(cfg, 0): {TimeInMesh: time.Minute, FirstMessageDeliveries: 5},
+			},
+		},
+		bad.ID(): {
+			Score: PeerScoreThresholds.GraylistThreshold - 1,
+			Topics: map[string]*pubsub.TopicScoreSnapshot{
+				blocksTopic(cfg, 0): {InvalidMessageDeliveries: 11},
+			},
+		},
+	})
+
+	dat, err := self.Peerstore().Get(good.ID(), gossipScoresKey)
+	require.NoError(t, err)
+	require.Equal(t, &GossipScores{Total: 10, Blocks: TopicScores{TimeInMesh: time.Minute, FirstMessageDeliveries: 5}}, dat)
+	require.Equal(t, network.Connected, self.Network().Connectedness(good.ID()))
+
+	dat, err = self.Peerstore().Get(bad.ID(), gossipScoresKey)
+	require.NoError(t, err)
+	require.Equal(t, float64(11), dat.(*GossipScores).Blocks.InvalidMessageDeliveries)
+	require.NotEqual(t, network.Connected, self.Network().Connectedness(bad.ID()), "graylisted peer is disconnected")
+}
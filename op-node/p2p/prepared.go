@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/pnet"
 )
 
 // Prepared provides a p2p host and discv5 service that is already set up.
@@ -20,6 +21,10 @@ type Prepared struct {
 	HostP2P   host.Host
 	LocalNode *enode.LocalNode
 	UDPv5     *discover.UDPv5
+
+	// PSK is the pre-shared key of the private network the host was set up for, nil for the public network.
+	// The host is expected to have been set up with this key already.
+	PSK pnet.PSK
 }
 
 var _ SetupP2P = (*Prepared)(nil)
@@ -35,6 +40,9 @@ func (p *Prepared) Check() error {
 	if p.LocalNode != nil && p.HostP2P == nil {
 		return errors.New("cannot provide discovery without p2p host")
 	}
+	if p.PSK != nil && p.LocalNode != nil {
+		return errors.New("cannot provide discovery on a private network")
+	}
 	return nil
 }
 
@@ -43,16 +51,37 @@ func (p *Prepared) Host(log log.Logger) (host.Host, error) {
 	return p.HostP2P, nil
 }
 
+// Advertised returns no explicitly advertised addresses, the prepared node record is kept up to date with the host.
+func (p *Prepared) Advertised() AdvertisedAddrs {
+	return AdvertisedAddrs{}
+}
+
+// Evidence creates an evidence store that only keeps the evidence in memory.
+func (p *Prepared) Evidence(log log.Logger, metrics EquivocationMetrics) (*EvidenceStore, error) {
+	return NewEvidenceStore(log, "", metrics)
+}
+
+// PeerPolicy returns nil, the prepared host has no peer groups.
+func (p *Prepared) PeerPolicy(log log.Logger) *PeerPolicy {
+	return nil
+}
+
 // Discovery creates a disc-v5 service. Returns nil, nil, nil if discovery is disabled.
-func (p *Prepared) Discovery(log log.Logger, rollupCfg *rollup.Config, tcpPort uint16) (*enode.LocalNode, *discover.UDPv5, error) {
+func (p *Prepared) Discovery(log log.Logger, rollupCfg *rollup.Config, ports HostPorts) (*enode.LocalNode, *discover.UDPv5, error) {
 	if p.LocalNode != nil {
 		dat := OptimismENRData{
 			chainID: rollupCfg.L2ChainID.Uint64(),
 			version: 0,
 		}
 		p.LocalNode.Set(&dat)
-		if tcpPort != 0 {
-			p.LocalNode.Set(enr.TCP(tcpPort))
+		if ports.TCP != 0 {
+			p.LocalNode.Set(enr.TCP(ports.TCP))
+		}
+		if ports.QUIC != 0 {
+			p.LocalNode.Set(QUICPort(ports.QUIC))
+		}
+		if ports.WS != 0 {
+			p.LocalNode.Set(WSPort(ports.WS))
 		}
 	}
 	return p.LocalNode, p.UDPv5, nil
@@ -0,0 +1,223 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-node/backoff"
+)
+
+// NamespaceSignerRPC is the JSON-RPC namespace of remote signers.
+const NamespaceSignerRPC = "opsigner"
+
+// SignPayloadArgs are the inputs of the signing hash, as sent to a remote signer.
+// Only the hash of the encoded payload is sent, the remote signer does not need the full payload.
+type SignPayloadArgs struct {
+	Domain      common.Hash  `json:"domain"`
+	ChainID     *hexutil.Big `json:"chainId"`
+	PayloadHash common.Hash  `json:"payloadHash"`
+}
+
+type RemoteSignerConfig struct {
+	// Endpoint is the HTTP(S) JSON-RPC endpoint of the remote signer
+	Endpoint string
+
+	// BearerToken is sent as Authorization header with every request, if not empty
+	BearerToken string
+
+	// TLSCACert is the PEM file of the CA to verify the server with. The system roots are used if empty.
+	TLSCACert string
+	// TLSCert and TLSKey are the PEM files of the client certificate for mutual TLS. mTLS is disabled if empty.
+	TLSCert string
+	TLSKey  string
+
+	// Timeout of a single signing request
+	Timeout time.Duration
+	// MaxAttempts of a signing request, before giving up
+	MaxAttempts int
+}
+
+func (c *RemoteSignerConfig) Check() error {
+	if c.Endpoint == "" {
+		return errors.New("missing remote signer endpoint")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("remote signer mTLS requires both a client certificate and key")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("invalid remote signer timeout: %s", c.Timeout)
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("expected at least 1 remote signer attempt, but max is %d", c.MaxAttempts)
+	}
+	return nil
+}
+
+func (c *RemoteSignerConfig) tlsConfig() (*tls.Config, error) {
+	if c.TLSCACert == "" && c.TLSCert == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSCACert != "" {
+		caPEM, err := os.ReadFile(c.TLSCACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("failed to parse CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// RemoteSignerSetup connects to a remote signer when the signer is set up.
+type RemoteSignerSetup struct {
+	Config *RemoteSignerConfig
+}
+
+func (r *RemoteSignerSetup) SetupSigner(ctx context.Context) (Signer, error) {
+	tlsConfig, err := r.Config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Timeout:   r.Config.Timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	client, err := rpc.DialHTTPWithClient(r.Config.Endpoint, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer: %w", err)
+	}
+	if r.Config.BearerToken != "" {
+		client.SetHeader("Authorization", "Bearer "+r.Config.BearerToken)
+	}
+	s := &RemoteSigner{client: client, timeout: r.Config.Timeout, maxAttempts: r.Config.MaxAttempts}
+	// Retrieve the address of the signer, to verify the signatures of the remote signer with.
+	if err := s.call(ctx, &s.address, NamespaceSignerRPC+"_address"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to retrieve remote signer address: %w", err)
+	}
+	return s, nil
+}
+
+// RemoteSigner signs with a remote signing service, and verifies the returned signatures.
+type RemoteSigner struct {
+	client      *rpc.Client
+	address     common.Address
+	timeout     time.Duration
+	maxAttempts int
+}
+
+func (s *RemoteSigner) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return backoff.Do(s.maxAttempts, backoff.Exponential(), func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		callCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		return s.client.CallContext(callCtx, result, method, args...)
+	})
+}
+
+// Address returns the address of the key of the remote signer.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, domain [32]byte, chainID *big.Int, encodedMsg []byte) (sig *[65]byte, err error) {
+	args := SignPayloadArgs{
+		Domain:      domain,
+		ChainID:     (*hexutil.Big)(chainID),
+		PayloadHash: crypto.Keccak256Hash(encodedMsg),
+	}
+	var result hexutil.Bytes
+	if err := s.call(ctx, &result, NamespaceSignerRPC+"_signBlockPayload", args); err != nil {
+		return nil, fmt.Errorf("remote signer failed to sign: %w", err)
+	}
+	if len(result) != 65 {
+		return nil, fmt.Errorf("remote signer returned signature of invalid length %d", len(result))
+	}
+	signingHash := signingHashFromPayloadHash(domain, chainID, args.PayloadHash)
+	pub, err := crypto.SigToPub(signingHash[:], result)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid signature: %w", err)
+	}
+	if addr := crypto.PubkeyToAddress(*pub); addr != s.address {
+		return nil, fmt.Errorf("remote signer signed with %s, expected %s", addr, s.address)
+	}
+	return (*[65]byte)(result), nil
+}
+
+func (s *RemoteSigner) Close() error {
+	s.client.Close()
+	return nil
+}
+
+// SignerAPI is the reference implementation of the remote signer RPC API, backed by a local key.
+// It is intended as a local stand-in for a proper signing service.
+type SignerAPI struct {
+	priv *ecdsa.PrivateKey
+}
+
+func NewSignerAPI(priv *ecdsa.PrivateKey) *SignerAPI {
+	return &SignerAPI{priv: priv}
+}
+
+func (api *SignerAPI) Address(ctx context.Context) (common.Address, error) {
+	return crypto.PubkeyToAddress(api.priv.PublicKey), nil
+}
+
+func (api *SignerAPI) SignBlockPayload(ctx context.Context, args SignPayloadArgs) (hexutil.Bytes, error) {
+	if args.Domain != SigningDomainBlocksV1 {
+		return nil, fmt.Errorf("unsupported signing domain: %s", args.Domain)
+	}
+	if args.ChainID == nil {
+		return nil, errors.New("missing chain ID")
+	}
+	signingHash := signingHashFromPayloadHash(args.Domain, args.ChainID.ToInt(), args.PayloadHash)
+	return crypto.Sign(signingHash[:], api.priv)
+}
+
+// NewSignerHandler serves the SignerAPI over HTTP, requiring the given bearer token if it is not empty.
+func NewSignerHandler(api *SignerAPI, bearerToken string) (http.Handler, error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(NamespaceSignerRPC, api); err != nil {
+		return nil, err
+	}
+	if bearerToken == "" {
+		return srv, nil
+	}
+	expected := []byte("Bearer " + bearerToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimSpace(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare([]byte(auth), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}), nil
+}
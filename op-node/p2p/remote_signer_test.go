@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"context"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	handler, err := NewSignerHandler(NewSignerAPI(priv), "secret")
+	require.NoError(t, err)
+	srv := httptest.NewTLSServer(handler)
+	defer srv.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0600))
+
+	cfg := &RemoteSignerConfig{
+		Endpoint:    srv.URL,
+		BearerToken: "secret",
+		TLSCACert:   caPath,
+		Timeout:     time.Second,
+		MaxAttempts: 1,
+	}
+	require.NoError(t, cfg.Check())
+	ctx := context.Background()
+	signer, err := (&RemoteSignerSetup{Config: cfg}).SetupSigner(ctx)
+	require.NoError(t, err)
+	defer signer.Close()
+	require.Equal(t, crypto.PubkeyToAddress(priv.PublicKey), signer.(*RemoteSigner).Address())
+
+	chainID := big.NewInt(901)
+	msg := []byte("encoded payload")
+	sig, err := signer.Sign(ctx, SigningDomainBlocksV1, chainID, msg)
+	require.NoError(t, err)
+	// the remote signature must match what the local signer produces
+	expected, err := NewLocalSigner(priv).Sign(ctx, SigningDomainBlocksV1, chainID, msg)
+	require.NoError(t, err)
+	require.Equal(t, expected, sig)
+
+	_, err = signer.Sign(ctx, [32]byte{0x42}, chainID, msg)
+	require.Error(t, err, "unsupported domain")
+
+	cfg.BearerToken = "wrong"
+	_, err = (&RemoteSignerSetup{Config: cfg}).SetupSigner(ctx)
+	require.Error(t, err, "bad token is rejected")
+}
@@ -0,0 +1,143 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	rcmgr "github.com/libp2p/go-libp2p-resource-manager"
+)
+
+// ResourceLimits limits the streams, connections and memory that the libp2p host may use,
+// at the system scope, per peer, and per protocol.
+type ResourceLimits struct {
+	// System-wide limits
+	MaxMemory  int64 // in bytes
+	MaxConns   int
+	MaxStreams int
+
+	// Limits per peer
+	PeerMaxMemory  int64 // in bytes
+	PeerMaxConns   int
+	PeerMaxStreams int
+
+	// Limits per protocol, and per peer within each protocol
+	ProtocolMaxStreams     int
+	ProtocolPeerMaxStreams int
+}
+
+func (l *ResourceLimits) Check() error {
+	if l.MaxMemory <= 0 || l.MaxConns <= 0 || l.MaxStreams <= 0 {
+		return fmt.Errorf("system resource limits must be positive: memory %d, conns %d, streams %d", l.MaxMemory, l.MaxConns, l.MaxStreams)
+	}
+	if l.PeerMaxMemory <= 0 || l.PeerMaxConns <= 0 || l.PeerMaxStreams <= 0 {
+		return fmt.Errorf("peer resource limits must be positive: memory %d, conns %d, streams %d", l.PeerMaxMemory, l.PeerMaxConns, l.PeerMaxStreams)
+	}
+	if l.ProtocolMaxStreams <= 0 || l.ProtocolPeerMaxStreams <= 0 {
+		return fmt.Errorf("protocol resource limits must be positive: streams %d, streams per peer %d", l.ProtocolMaxStreams, l.ProtocolPeerMaxStreams)
+	}
+	if l.PeerMaxMemory > l.MaxMemory || l.PeerMaxConns > l.MaxConns || l.PeerMaxStreams > l.MaxStreams {
+		return errors.New("peer resource limits cannot exceed the system resource limits")
+	}
+	if l.ProtocolPeerMaxStreams > l.ProtocolMaxStreams || l.ProtocolMaxStreams > l.MaxStreams {
+		return errors.New("protocol stream limits cannot exceed the system stream limit")
+	}
+	return nil
+}
+
+// LimitConfig builds the resource manager limit config,
+// starting from the libp2p defaults for the scopes that are not configured.
+func (l *ResourceLimits) LimitConfig() rcmgr.DefaultLimitConfig {
+	cfg := rcmgr.DefaultLimits
+	fixedMemory := func(v int64) rcmgr.MemoryLimit {
+		return rcmgr.MemoryLimit{MemoryFraction: 1, MinMemory: v, MaxMemory: v}
+	}
+	cfg.SystemMemory = fixedMemory(l.MaxMemory)
+	cfg.SystemBaseLimit.Conns = l.MaxConns
+	cfg.SystemBaseLimit.ConnsInbound = l.MaxConns
+	cfg.SystemBaseLimit.ConnsOutbound = l.MaxConns
+	if cfg.SystemBaseLimit.FD < l.MaxConns {
+		cfg.SystemBaseLimit.FD = l.MaxConns
+	}
+	cfg.SystemBaseLimit.Streams = l.MaxStreams
+	cfg.SystemBaseLimit.StreamsInbound = l.MaxStreams
+	cfg.SystemBaseLimit.StreamsOutbound = l.MaxStreams
+
+	cfg.PeerMemory = fixedMemory(l.PeerMaxMemory)
+	cfg.PeerBaseLimit.Conns = l.PeerMaxConns
+	cfg.PeerBaseLimit.ConnsInbound = l.PeerMaxConns
+	cfg.PeerBaseLimit.ConnsOutbound = l.PeerMaxConns
+	cfg.PeerBaseLimit.FD = l.PeerMaxConns
+	cfg.PeerBaseLimit.Streams = l.PeerMaxStreams
+	cfg.PeerBaseLimit.StreamsInbound = l.PeerMaxStreams
+	cfg.PeerBaseLimit.StreamsOutbound = l.PeerMaxStreams
+
+	cfg.ProtocolBaseLimit.Streams = l.ProtocolMaxStreams
+	cfg.ProtocolBaseLimit.StreamsInbound = l.ProtocolMaxStreams
+	cfg.ProtocolBaseLimit.StreamsOutbound = l.ProtocolMaxStreams
+
+	cfg.ProtocolPeerBaseLimit.Streams = l.ProtocolPeerMaxStreams
+	cfg.ProtocolPeerBaseLimit.StreamsInbound = l.ProtocolPeerMaxStreams
+	cfg.ProtocolPeerBaseLimit.StreamsOutbound = l.ProtocolPeerMaxStreams
+	return cfg
+}
+
+func DefaultResourceManager(conf *Config) (network.ResourceManager, error) {
+	return rcmgr.NewResourceManager(rcmgr.NewStaticLimiter(conf.ResourceLimits.LimitConfig()))
+}
+
+type ScopeStats struct {
+	StreamsInbound  int   `json:"streamsInbound"`
+	StreamsOutbound int   `json:"streamsOutbound"`
+	ConnsInbound    int   `json:"connsInbound"`
+	ConnsOutbound   int   `json:"connsOutbound"`
+	FD              int   `json:"fd"`
+	Memory          int64 `json:"memory"`
+}
+
+func toScopeStats(s network.ScopeStat) ScopeStats {
+	return ScopeStats{
+		StreamsInbound:  s.NumStreamsInbound,
+		StreamsOutbound: s.NumStreamsOutbound,
+		ConnsInbound:    s.NumConnsInbound,
+		ConnsOutbound:   s.NumConnsOutbound,
+		FD:              s.NumFD,
+		Memory:          s.Memory,
+	}
+}
+
+// ResourceStats is the current resource usage of the libp2p host.
+type ResourceStats struct {
+	System    ScopeStats            `json:"system"`
+	Transient ScopeStats            `json:"transient"`
+	Protocols map[string]ScopeStats `json:"protocols"`
+	Services  map[string]ScopeStats `json:"services"`
+	Peers     map[string]ScopeStats `json:"peers"`
+}
+
+func resourceStats(mgr network.ResourceManager) (*ResourceStats, error) {
+	state, ok := mgr.(rcmgr.ResourceManagerState)
+	if !ok { // e.g. the null resource manager, when resource management is disabled
+		return nil, NoResourceManager
+	}
+	stat := state.Stat()
+	out := &ResourceStats{
+		System:    toScopeStats(stat.System),
+		Transient: toScopeStats(stat.Transient),
+		Protocols: make(map[string]ScopeStats, len(stat.Protocols)),
+		Services:  make(map[string]ScopeStats, len(stat.Services)),
+		Peers:     make(map[string]ScopeStats, len(stat.Peers)),
+	}
+	for id, s := range stat.Protocols {
+		out.Protocols[string(id)] = toScopeStats(s)
+	}
+	for name, s := range stat.Services {
+		out.Services[name] = toScopeStats(s)
+	}
+	// We don't use the peer.ID type as key,
+	// since JSON decoding can't use the provided json unmarshaler (on *string type).
+	for id, s := range stat.Peers {
+		out.Peers[id.String()] = toScopeStats(s)
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/test"
+	"github.com/stretchr/testify/require"
+)
+
+func testResourceLimits() ResourceLimits {
+	return ResourceLimits{
+		MaxMemory:              512 << 20,
+		MaxConns:               256,
+		MaxStreams:             4096,
+		PeerMaxMemory:          32 << 20,
+		PeerMaxConns:           8,
+		PeerMaxStreams:         256,
+		ProtocolMaxStreams:     2048,
+		ProtocolPeerMaxStreams: 4,
+	}
+}
+
+func TestResourceLimitsCheck(t *testing.T) {
+	limits := testResourceLimits()
+	require.NoError(t, limits.Check())
+
+	cases := map[string]func(l *ResourceLimits){
+		"no system memory":          func(l *ResourceLimits) { l.MaxMemory = 0 },
+		"negative peer conns":       func(l *ResourceLimits) { l.PeerMaxConns = -1 },
+		"no protocol streams":       func(l *ResourceLimits) { l.ProtocolMaxStreams = 0 },
+		"peer exceeds system":       func(l *ResourceLimits) { l.PeerMaxConns = l.MaxConns + 1 },
+		"protocol peer exceeds all": func(l *ResourceLimits) { l.ProtocolPeerMaxStreams = l.ProtocolMaxStreams + 1 },
+		"protocol exceeds system":   func(l *ResourceLimits) { l.ProtocolMaxStreams = l.MaxStreams + 1 },
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := testResourceLimits()
+			tamper(&l)
+			require.Error(t, l.Check())
+		})
+	}
+}
+
+func TestResourceManagerLimits(t *testing.T) {
+	conf := &Config{ResourceLimits: testResourceLimits()}
+	mgr, err := DefaultResourceManager(conf)
+	require.NoError(t, err)
+	defer mgr.Close()
+
+	peerA, err := test.RandPeerID()
+	require.NoError(t, err)
+	conn, err := mgr.OpenConnection(network.DirInbound, true)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetPeer(peerA))
+	defer conn.Done()
+
+	proto := protocol.ID("/test/1.0.0")
+	var streams []network.StreamManagementScope
+	for i := 0; i < conf.ResourceLimits.ProtocolPeerMaxStreams; i++ {
+		s, err := mgr.OpenStream(peerA, network.DirInbound)
+		require.NoError(t, err)
+		require.NoError(t, s.SetProtocol(proto))
+		streams = append(streams, s)
+	}
+	s, err := mgr.OpenStream(peerA, network.DirInbound)
+	require.NoError(t, err)
+	require.Error(t, s.SetProtocol(proto), "streams of a protocol per peer are limited")
+	s.Done()
+
+	stats, err := resourceStats(mgr)
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.System.ConnsInbound)
+	require.Equal(t, len(streams), stats.System.StreamsInbound)
+	require.Equal(t, len(streams), stats.Protocols[string(proto)].StreamsInbound)
+	require.Equal(t, len(streams), stats.Peers[peerA.String()].StreamsInbound)
+
+	for _, s := range streams {
+		s.Done()
+	}
+	stats, err = resourceStats(mgr)
+	require.NoError(t, err)
+	require.Zero(t, stats.System.StreamsInbound)
+}
+
+func TestResourceStatsDisabled(t *testing.T) {
+	_, err := resourceStats(network.NullResourceManager)
+	require.ErrorIs(t, err, NoResourceManager)
+}
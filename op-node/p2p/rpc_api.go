@@ -11,20 +11,21 @@ import (
 )
 
 type PeerInfo struct {
-	PeerID          peer.ID  `json:"peerID"`
-	NodeID          enode.ID `json:"nodeID"`
-	UserAgent       string   `json:"userAgent"`
-	ProtocolVersion string   `json:"protocolVersion"`
-	ENR             string   `json:"ENR"`       // might not always be known, e.g. if the peer connected us instead of us discovering them
-	Addresses       []string `json:"addresses"` // multi-addresses. may be mix of LAN / docker / external IPs. All of them are communicated.
-	Protocols       []string `json:"protocols"` // negotiated protocols list
-	//GossipScore float64
-	//PeerScore float64
-	Connectedness network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
-	Direction     network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
-	Protected     bool                  `json:"protected"`     // Protected peers do not get
-	ChainID       uint64                `json:"chainID"`       // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
-	Latency       time.Duration         `json:"latency"`
+	PeerID          peer.ID               `json:"peerID"`
+	NodeID          enode.ID              `json:"nodeID"`
+	UserAgent       string                `json:"userAgent"`
+	ProtocolVersion string                `json:"protocolVersion"`
+	ENR             string                `json:"ENR"`           // might not always be known, e.g. if the peer connected us instead of us discovering them
+	Addresses       []string              `json:"addresses"`     // multi-addresses. may be mix of LAN / docker / external IPs. All of them are communicated.
+	Protocols       []string              `json:"protocols"`     // negotiated protocols list
+	GossipScores    *GossipScores         `json:"gossipScores"`  // latest gossip scores, nil if not known
+	Status          *PeerStatus           `json:"status"`        // latest status from the status handshake, nil if not known
+	Connectedness   network.Connectedness `json:"connectedness"` // "NotConnected", "Connected", "CanConnect" (gracefully disconnected), or "CannotConnect" (tried but failed)
+	Direction       network.Direction     `json:"direction"`     // "Unknown", "Inbound" (if the peer contacted us), "Outbound" (if we connected to them)
+	Protected       bool                  `json:"protected"`     // Protected peers do not get
+	Group           string                `json:"group"`         // name of the peer group the peer is in, empty if none
+	ChainID         uint64                `json:"chainID"`       // some peers might try to connect, but we figure out they are on a different chain later. This may be 0 if the peer is not an optimism node at all.
+	Latency         time.Duration         `json:"latency"`
 
 	GossipBlocks bool `json:"gossipBlocks"` // if the peer is in our gossip topic
 }
@@ -41,6 +42,8 @@ type API interface {
 	Self(ctx context.Context) (*PeerInfo, error)
 	Peers(ctx context.Context, connected bool) (*PeerDump, error)
 	PeerStats(ctx context.Context) (*PeerStats, error)
+	ResourceStats(ctx context.Context) (*ResourceStats, error)
+	GossipStats(ctx context.Context) (map[string]*TopicGossipStats, error)
 	DiscoveryTable(ctx context.Context) ([]*enode.Node, error)
 	BlockPeer(ctx context.Context, p peer.ID) error
 	UnblockPeer(ctx context.Context, p peer.ID) error
@@ -51,6 +54,10 @@ type API interface {
 	BlockSubnet(ctx context.Context, ipnet *net.IPNet) error
 	UnblockSubnet(ctx context.Context, ipnet *net.IPNet) error
 	ListBlockedSubnets(ctx context.Context) ([]*net.IPNet, error)
+	BanPeer(ctx context.Context, p peer.ID, duration time.Duration) error
+	BanAddr(ctx context.Context, ip net.IP, duration time.Duration) error
+	BanSubnet(ctx context.Context, ipnet *net.IPNet, duration time.Duration) error
+	ListBans(ctx context.Context) ([]*Ban, error)
 	ProtectPeer(ctx context.Context, p peer.ID) error
 	UnprotectPeer(ctx context.Context, p peer.ID) error
 	ConnectPeer(ctx context.Context, addr string) error
@@ -3,6 +3,7 @@ package p2p
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -43,6 +44,24 @@ func (c *Client) PeerStats(ctx context.Context) (*PeerStats, error) {
 	return out, err
 }
 
+func (c *Client) ResourceStats(ctx context.Context) (*ResourceStats, error) {
+	var out *ResourceStats
+	err := c.c.CallContext(ctx, &out, prefixRPC("resourceStats"))
+	return out, err
+}
+
+func (c *Client) GossipStats(ctx context.Context) (map[string]*TopicGossipStats, error) {
+	var out map[string]*TopicGossipStats
+	err := c.c.CallContext(ctx, &out, prefixRPC("gossipStats"))
+	return out, err
+}
+
+func (c *Client) Equivocations(ctx context.Context) ([]*Equivocation, error) {
+	var out []*Equivocation
+	err := c.c.CallContext(ctx, &out, prefixRPC("equivocations"))
+	return out, err
+}
+
 func (c *Client) DiscoveryTable(ctx context.Context) ([]*enode.Node, error) {
 	var out []*enode.Node
 	err := c.c.CallContext(ctx, &out, prefixRPC("discoveryTable"))
@@ -91,6 +110,24 @@ func (c *Client) ListBlockedSubnets(ctx context.Context) ([]*net.IPNet, error) {
 	return out, err
 }
 
+func (c *Client) BanPeer(ctx context.Context, p peer.ID, duration time.Duration) error {
+	return c.c.CallContext(ctx, nil, prefixRPC("banPeer"), p, duration)
+}
+
+func (c *Client) BanAddr(ctx context.Context, ip net.IP, duration time.Duration) error {
+	return c.c.CallContext(ctx, nil, prefixRPC("banAddr"), ip, duration)
+}
+
+func (c *Client) BanSubnet(ctx context.Context, ipnet *net.IPNet, duration time.Duration) error {
+	return c.c.CallContext(ctx, nil, prefixRPC("banSubnet"), ipnet, duration)
+}
+
+func (c *Client) ListBans(ctx context.Context) ([]*Ban, error) {
+	var out []*Ban
+	err := c.c.CallContext(ctx, &out, prefixRPC("listBans"))
+	return out, err
+}
+
 func (c *Client) ProtectPeer(ctx context.Context, p peer.ID) error {
 	return c.c.CallContext(ctx, nil, prefixRPC("protectPeer"), p)
 }
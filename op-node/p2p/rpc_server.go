@@ -23,13 +23,14 @@ import (
 )
 
 // TODO: dynamic peering
-// - req-resp protocol to ensure peers from a different chain learn they shouldn't be connected
 // - banning peers based on score
 
 var (
 	DisabledDiscovery   = errors.New("discovery disabled")
 	NoConnectionManager = errors.New("no connection manager")
+	NoResourceManager   = errors.New("no resource manager")
 	NoConnectionGater   = errors.New("no connection gater")
+	DisabledEvidence    = errors.New("equivocation evidence tracking disabled")
 )
 
 type Node interface {
@@ -47,6 +48,12 @@ type Node interface {
 	ConnectionGater() ConnectionGater
 	// ConnectionManager returns the connection manager, to protect peers with, may be nil
 	ConnectionManager() connmgr.ConnManager
+	// Evidence returns the evidence of sequencer equivocations, nil if p2p is disabled
+	Evidence() *EvidenceStore
+	// GossipStats returns the message stats of the gossip topics
+	GossipStats() *GossipStats
+	// PeerPolicy returns the connection policy of the peer groups, nil if there are no peer groups
+	PeerPolicy() *PeerPolicy
 }
 
 type APIBackend struct {
@@ -67,7 +74,7 @@ func (s *APIBackend) Self(ctx context.Context) (*PeerInfo, error) {
 	h := s.node.Host()
 	nw := h.Network()
 	pstore := h.Peerstore()
-	info, err := dumpPeer(h.ID(), nw, pstore, s.node.ConnectionManager())
+	info, err := dumpPeer(h.ID(), nw, pstore, s.node.ConnectionManager(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +86,7 @@ func (s *APIBackend) Self(ctx context.Context) (*PeerInfo, error) {
 	return info, nil
 }
 
-func dumpPeer(id peer.ID, nw network.Network, pstore peerstore.Peerstore, connMgr connmgr.ConnManager) (*PeerInfo, error) {
+func dumpPeer(id peer.ID, nw network.Network, pstore peerstore.Peerstore, connMgr connmgr.ConnManager, policy *PeerPolicy) (*PeerInfo, error) {
 	info := &PeerInfo{
 		PeerID: id,
 	}
@@ -137,10 +144,23 @@ func dumpPeer(id peer.ID, nw network.Network, pstore peerstore.Peerstore, connMg
 			info.ChainID = chID
 		}
 	}
+	if dat, err := pstore.Get(id, gossipScoresKey); err == nil {
+		if scores, ok := dat.(*GossipScores); ok {
+			info.GossipScores = scores
+		}
+	}
+	if dat, err := pstore.Get(id, peerStatusKey); err == nil {
+		if status, ok := dat.(*PeerStatus); ok {
+			info.Status = status
+		}
+	}
 	info.Latency = pstore.LatencyEWMA(id)
 	if connMgr != nil {
 		info.Protected = connMgr.IsProtected(id, "")
 	}
+	if policy != nil {
+		info.Group = policy.Group(id)
+	}
 
 	return info, nil
 }
@@ -159,7 +179,7 @@ func (s *APIBackend) Peers(ctx context.Context, connected bool) (*PeerDump, erro
 
 	dump := &PeerDump{Peers: make(map[string]*PeerInfo)}
 	for _, id := range peers {
-		peerInfo, err := dumpPeer(id, nw, pstore, s.node.ConnectionManager())
+		peerInfo, err := dumpPeer(id, nw, pstore, s.node.ConnectionManager(), s.node.PeerPolicy())
 		if err != nil {
 			s.log.Debug("failed to dump peer info in RPC request", "peer", id, "err", err)
 			continue
@@ -213,6 +233,25 @@ func (s *APIBackend) PeerStats(_ context.Context) (*PeerStats, error) {
 	return stats, nil
 }
 
+// ResourceStats reports the current resource usage of the p2p host, at system, protocol and peer scope.
+func (s *APIBackend) ResourceStats(_ context.Context) (*ResourceStats, error) {
+	return resourceStats(s.node.Host().Network().ResourceManager())
+}
+
+// GossipStats reports the message stats per gossip topic, in total and per connected peer.
+func (s *APIBackend) GossipStats(_ context.Context) (map[string]*TopicGossipStats, error) {
+	return s.node.GossipStats().Snapshot(), nil
+}
+
+// Equivocations returns the evidence of sequencer equivocations, ordered by block height.
+func (s *APIBackend) Equivocations(_ context.Context) ([]*Equivocation, error) {
+	if evidence := s.node.Evidence(); evidence != nil {
+		return evidence.Equivocations(), nil
+	} else {
+		return nil, DisabledEvidence
+	}
+}
+
 func (s *APIBackend) DiscoveryTable(_ context.Context) ([]*enode.Node, error) {
 	if dv5 := s.node.Dv5Udp(); dv5 != nil {
 		return dv5.AllNodes(), nil
@@ -297,6 +336,45 @@ func (s *APIBackend) ListBlockedSubnets(_ context.Context) ([]*net.IPNet, error)
 	}
 }
 
+// BanPeer blocks a peer until the ban expires, also across restarts if the connection gater is persisted.
+// Note: active connections to the peer are not automatically closed.
+func (s *APIBackend) BanPeer(_ context.Context, p peer.ID, duration time.Duration) error {
+	if gater := s.node.ConnectionGater(); gater == nil {
+		return NoConnectionGater
+	} else {
+		return gater.BanPeer(p, duration)
+	}
+}
+
+// BanAddr blocks an IP address until the ban expires.
+// Note: active connections to the IP address are not automatically closed.
+func (s *APIBackend) BanAddr(_ context.Context, ip net.IP, duration time.Duration) error {
+	if gater := s.node.ConnectionGater(); gater == nil {
+		return NoConnectionGater
+	} else {
+		return gater.BanAddr(ip, duration)
+	}
+}
+
+// BanSubnet blocks an IP subnet until the ban expires.
+// Note: active connections to the IP subnet are not automatically closed.
+func (s *APIBackend) BanSubnet(_ context.Context, ipnet *net.IPNet, duration time.Duration) error {
+	if gater := s.node.ConnectionGater(); gater == nil {
+		return NoConnectionGater
+	} else {
+		return gater.BanSubnet(ipnet, duration)
+	}
+}
+
+// ListBans lists the bans that did not expire yet, the first to expire first.
+func (s *APIBackend) ListBans(_ context.Context) ([]*Ban, error) {
+	if gater := s.node.ConnectionGater(); gater == nil {
+		return nil, NoConnectionGater
+	} else {
+		return gater.ListBans(), nil
+	}
+}
+
 func (s *APIBackend) ProtectPeer(_ context.Context, p peer.ID) error {
 	if manager := s.node.ConnectionManager(); manager == nil {
 		return NoConnectionManager
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/flags"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli"
@@ -23,13 +24,19 @@ type Signer interface {
 }
 
 func SigningHash(domain [32]byte, chainID *big.Int, payloadBytes []byte) common.Hash {
+	return signingHashFromPayloadHash(domain, chainID, crypto.Keccak256Hash(payloadBytes))
+}
+
+// signingHashFromPayloadHash computes the signing hash from the hash of the encoded payload,
+// such that remote signers do not need the full payload.
+func signingHashFromPayloadHash(domain [32]byte, chainID *big.Int, payloadHash common.Hash) common.Hash {
 	var msgInput [32 + 32 + 32]byte
 	// domain: first 32 bytes
 	copy(msgInput[:32], domain[:])
 	// chain_id: second 32 bytes
 	chainID.FillBytes(msgInput[32:64])
 	// payload_hash: third 32 bytes, hash of encoded payload
-	copy(msgInput[32:], crypto.Keccak256(payloadBytes))
+	copy(msgInput[32:], payloadHash[:])
 
 	return crypto.Keccak256Hash(msgInput[:])
 }
@@ -72,29 +79,47 @@ func (p *PreparedSigner) SetupSigner(ctx context.Context) (Signer, error) {
 	return p.Signer, nil
 }
 
-// TODO: implement remote signer setup (config to authenticated endpoint)
-// and remote signer itself (e.g. a open http client to make signing requests)
-
 type SignerSetup interface {
 	SetupSigner(ctx context.Context) (Signer, error)
 }
 
 // LoadSignerSetup loads a configuration for a Signer to be set up later
 func LoadSignerSetup(ctx *cli.Context) (SignerSetup, error) {
-	keyFile := ctx.GlobalString(flags.SequencerP2PKeyFlag.Name)
-	if keyFile != "" {
-		// Mnemonics are bad because they leak *all* keys when they leak.
+	keyCfg := &signer.KeyConfig{
+		KeystorePath:   ctx.GlobalString(flags.SequencerP2PKeystoreFlag.Name),
+		PasswordFile:   ctx.GlobalString(flags.SequencerP2PKeystorePasswordFlag.Name),
+		PrivateKeyFile: ctx.GlobalString(flags.SequencerP2PKeyFlag.Name),
+	}
+	endpoint := ctx.GlobalString(flags.SequencerP2PSignerEndpointFlag.Name)
+	if keyCfg.Enabled() && endpoint != "" {
+		return nil, errors.New("cannot use both a local p2p sequencer key and a remote signer")
+	}
+	if keyCfg.Enabled() {
 		// Unencrypted keys from file are bad because they are easy to leak (and we are not checking file permissions).
-		// TODO: load from encrypted keystore
-		priv, err := crypto.LoadECDSA(keyFile)
+		// An encrypted keystore is preferred.
+		priv, err := keyCfg.LoadKey()
 		if err != nil {
-			return nil, fmt.Errorf("failed to read batch submitter key: %v", err)
+			return nil, fmt.Errorf("failed to load p2p sequencer key: %w", err)
 		}
 
 		return &PreparedSigner{Signer: NewLocalSigner(priv)}, nil
 	}
 
-	// TODO: create remote signer
+	if endpoint != "" {
+		cfg := &RemoteSignerConfig{
+			Endpoint:    endpoint,
+			BearerToken: ctx.GlobalString(flags.SequencerP2PSignerTokenFlag.Name),
+			TLSCACert:   ctx.GlobalString(flags.SequencerP2PSignerTLSCAFlag.Name),
+			TLSCert:     ctx.GlobalString(flags.SequencerP2PSignerTLSCertFlag.Name),
+			TLSKey:      ctx.GlobalString(flags.SequencerP2PSignerTLSKeyFlag.Name),
+			Timeout:     ctx.GlobalDuration(flags.SequencerP2PSignerTimeoutFlag.Name),
+			MaxAttempts: ctx.GlobalInt(flags.SequencerP2PSignerAttemptsFlag.Name),
+		}
+		if err := cfg.Check(); err != nil {
+			return nil, fmt.Errorf("invalid remote signer config: %w", err)
+		}
+		return &RemoteSignerSetup{Config: cfg}, nil
+	}
 
 	return nil, nil
 }
@@ -0,0 +1,268 @@
+package p2p
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+)
+
+const (
+	// StatusProtocolID is the same for all chains, so peers on a different chain can be identified and disconnected.
+	StatusProtocolID = protocol.ID("/optimism/status/1.0.0")
+
+	// peerStatusKey is the peerstore key of the status of a peer, as exchanged in the status handshake.
+	peerStatusKey = "optimismStatus"
+
+	statusTimeout = 10 * time.Second
+	// maxStatusSize limits the size of a status message, a valid message is a few hundred bytes.
+	maxStatusSize = 4096
+)
+
+// Status is the chain and sync status of a rollup node, exchanged with each peer when connecting.
+type Status struct {
+	ChainID   uint64      `json:"chainID"`
+	L1Genesis eth.BlockID `json:"l1Genesis"`
+	L2Genesis eth.BlockID `json:"l2Genesis"`
+	Unsafe    eth.BlockID `json:"unsafe"`
+	Safe      eth.BlockID `json:"safe"`
+	Finalized eth.BlockID `json:"finalized"`
+	Version   string      `json:"version"`
+}
+
+// PeerStatus is the latest status of a peer, as stored in the peerstore.
+type PeerStatus struct {
+	Status
+	UpdatedAt uint64 `json:"updatedAt"` // unix timestamp
+}
+
+func init() {
+	// the peerstore may be persisted, and then uses gob to encode the metadata of peers
+	gob.Register(&PeerStatus{})
+}
+
+// StatusSource provides the local sync status to share with peers.
+type StatusSource interface {
+	// L2Heads returns the unsafe, safe and finalized L2 heads of the node.
+	L2Heads(ctx context.Context) (unsafe eth.BlockID, safe eth.BlockID, finalized eth.BlockID, err error)
+	// AppVersion returns the version of the node software.
+	AppVersion() string
+}
+
+// chainID returns the L2 chain ID to exchange in the status, 0 if the rollup config does not specify it.
+func chainID(cfg *rollup.Config) uint64 {
+	if cfg.L2ChainID == nil {
+		return 0
+	}
+	return cfg.L2ChainID.Uint64()
+}
+
+// checkStatus checks if the status of a peer matches the chain of the node.
+func checkStatus(cfg *rollup.Config, status *Status) error {
+	if status.ChainID != chainID(cfg) {
+		return fmt.Errorf("chain ID mismatch: peer is on chain %d, expected %d", status.ChainID, chainID(cfg))
+	}
+	if status.L1Genesis != cfg.Genesis.L1 {
+		return fmt.Errorf("L1 genesis mismatch: peer has %s, expected %s", status.L1Genesis, cfg.Genesis.L1)
+	}
+	if status.L2Genesis != cfg.Genesis.L2 {
+		return fmt.Errorf("L2 genesis mismatch: peer has %s, expected %s", status.L2Genesis, cfg.Genesis.L2)
+	}
+	return nil
+}
+
+// statusProtocol runs the status handshake: the dialing side sends its status, and the other side responds with its own.
+// Both sides store the status of the other in the peerstore, and disconnect if the other is on a different chain.
+// If a peer that dialed us does not start the handshake in time, we start it instead.
+type statusProtocol struct {
+	log    log.Logger
+	cfg    *rollup.Config
+	h      host.Host
+	source StatusSource // may be nil, the heads and version are then left empty
+
+	// inboundGrace is the time a peer that dialed us has to start the handshake
+	inboundGrace time.Duration
+}
+
+func newStatusProtocol(log log.Logger, cfg *rollup.Config, h host.Host, source StatusSource) *statusProtocol {
+	p := &statusProtocol{log: log, cfg: cfg, h: h, source: source, inboundGrace: statusTimeout}
+	h.SetStreamHandler(StatusProtocolID, p.handleStream)
+	h.Network().Notify(p)
+	return p
+}
+
+// localStatus returns the status of the node.
+// The heads are left empty if they cannot be retrieved, e.g. when the node is still starting.
+func (p *statusProtocol) localStatus(ctx context.Context) *Status {
+	status := &Status{
+		ChainID:   chainID(p.cfg),
+		L1Genesis: p.cfg.Genesis.L1,
+		L2Genesis: p.cfg.Genesis.L2,
+	}
+	if p.source == nil {
+		return status
+	}
+	status.Version = p.source.AppVersion()
+	unsafe, safe, finalized, err := p.source.L2Heads(ctx)
+	if err != nil {
+		p.log.Warn("failed to retrieve L2 heads for status", "err", err)
+		return status
+	}
+	status.Unsafe, status.Safe, status.Finalized = unsafe, safe, finalized
+	return status
+}
+
+func readStatus(r io.Reader) (*Status, error) {
+	var status Status
+	if err := json.NewDecoder(io.LimitReader(r, maxStatusSize)).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (p *statusProtocol) handleStream(s network.Stream) {
+	defer s.Close()
+	id := s.Conn().RemotePeer()
+	_ = s.SetDeadline(time.Now().Add(statusTimeout))
+	remote, err := readStatus(s)
+	if err != nil {
+		p.log.Debug("failed to read status request", "peer", id, "err", err)
+		_ = s.Reset()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	if err := json.NewEncoder(s).Encode(p.localStatus(ctx)); err != nil {
+		p.log.Debug("failed to write status response", "peer", id, "err", err)
+		_ = s.Reset()
+		return
+	}
+	p.onStatus(id, remote)
+}
+
+// RequestStatus exchanges the status with the given peer.
+// The peer is not dialed: a peer that disconnected before the handshake should stay disconnected.
+func (p *statusProtocol) RequestStatus(ctx context.Context, id peer.ID) (*Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	ctx = network.WithNoDial(ctx, "status handshake")
+	s, err := p.h.NewStream(ctx, id, StatusProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open status stream: %w", err)
+	}
+	defer s.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.SetDeadline(deadline)
+	}
+	if err := json.NewEncoder(s).Encode(p.localStatus(ctx)); err != nil {
+		_ = s.Reset()
+		return nil, fmt.Errorf("failed to write status: %w", err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		_ = s.Reset()
+		return nil, fmt.Errorf("failed to close status request: %w", err)
+	}
+	remote, err := readStatus(s)
+	if err != nil {
+		_ = s.Reset()
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+	p.onStatus(id, remote)
+	return remote, nil
+}
+
+// onStatus stores the status of the peer, or disconnects the peer if it is on a different chain.
+func (p *statusProtocol) onStatus(id peer.ID, status *Status) {
+	if err := checkStatus(p.cfg, status); err != nil {
+		p.log.Warn("disconnecting peer on different chain", "peer", id, "err", err)
+		if err := p.h.Network().ClosePeer(id); err != nil {
+			p.log.Warn("failed to disconnect peer", "peer", id, "err", err)
+		}
+		return
+	}
+	p.log.Debug("received peer status", "peer", id, "unsafe", status.Unsafe, "safe", status.Safe,
+		"finalized", status.Finalized, "version", status.Version)
+	ps := &PeerStatus{Status: *status, UpdatedAt: uint64(time.Now().Unix())}
+	if err := p.h.Peerstore().Put(id, peerStatusKey, ps); err != nil {
+		p.log.Warn("failed to store status of peer", "peer", id, "err", err)
+	}
+}
+
+// Connected starts the status handshake with peers that we dialed. Peers that dial us start the handshake themselves,
+// or we start it after a grace period if they did not, so peers on a different chain cannot stay connected by dialing us.
+func (p *statusProtocol) Connected(n network.Network, c network.Conn) {
+	id := c.RemotePeer()
+	if c.Stat().Direction == network.DirOutbound {
+		go p.handshake(id)
+		return
+	}
+	connectedAt := uint64(time.Now().Unix())
+	time.AfterFunc(p.inboundGrace, func() {
+		if n.Connectedness(id) != network.Connected {
+			return
+		}
+		if status := GetPeerStatus(p.h, id); status != nil && status.UpdatedAt >= connectedAt {
+			return
+		}
+		p.handshake(id)
+	})
+}
+
+func (p *statusProtocol) handshake(id peer.ID) {
+	if _, err := p.RequestStatus(context.Background(), id); err != nil {
+		// the peer may not be an optimism node, e.g. a crawler, so it is not disconnected.
+		p.log.Debug("status handshake failed", "peer", id, "err", err)
+	}
+}
+
+func (p *statusProtocol) Listen(n network.Network, a ma.Multiaddr)         {}
+func (p *statusProtocol) ListenClose(n network.Network, a ma.Multiaddr)    {}
+func (p *statusProtocol) Disconnected(n network.Network, c network.Conn)   {}
+func (p *statusProtocol) OpenedStream(n network.Network, s network.Stream) {}
+func (p *statusProtocol) ClosedStream(n network.Network, s network.Stream) {}
+
+// GetPeerStatus returns the latest status of the peer, or nil if it is not known.
+func GetPeerStatus(h host.Host, id peer.ID) *PeerStatus {
+	dat, err := h.Peerstore().Get(id, peerStatusKey)
+	if err != nil {
+		return nil
+	}
+	status, _ := dat.(*PeerStatus)
+	return status
+}
+
+// SyncPeers returns the connected peers with a known status, most advanced unsafe head first.
+// Peers on a different chain are disconnected during the status handshake, and are thus never included.
+func SyncPeers(h host.Host) []peer.ID {
+	type candidate struct {
+		id     peer.ID
+		status *PeerStatus
+	}
+	var candidates []candidate
+	for _, id := range h.Network().Peers() {
+		if status := GetPeerStatus(h, id); status != nil {
+			candidates = append(candidates, candidate{id: id, status: status})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].status.Unsafe.Number > candidates[j].status.Unsafe.Number
+	})
+	out := make([]peer.ID, 0, len(candidates))
+	for _, c := range candidates {
+		out = append(out, c.id)
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package p2p
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+type mockStatusSource struct {
+	unsafe, safe, finalized eth.BlockID
+}
+
+func (m *mockStatusSource) L2Heads(ctx context.Context) (eth.BlockID, eth.BlockID, eth.BlockID, error) {
+	return m.unsafe, m.safe, m.finalized, nil
+}
+
+func (m *mockStatusSource) AppVersion() string {
+	return "v0.0.1-test"
+}
+
+func TestStatusHandshake(t *testing.T) {
+	cfg := &rollup.Config{L2ChainID: big.NewInt(901)}
+	cfg.Genesis.L1 = eth.BlockID{Hash: common.Hash{0x1}, Number: 100}
+	cfg.Genesis.L2 = eth.BlockID{Hash: common.Hash{0x2}, Number: 0}
+	otherGenesis := *cfg
+	otherGenesis.Genesis.L2 = eth.BlockID{Hash: common.Hash{0x3}, Number: 0}
+	otherChain := *cfg
+	otherChain.L2ChainID = big.NewInt(902)
+
+	mnet, err := mocknet.WithNPeers(7)
+	require.NoError(t, err)
+	defer mnet.Close()
+	require.NoError(t, mnet.LinkAll())
+	hosts := mnet.Hosts()
+	logger := testlog.Logger(t, log.LvlError)
+
+	self := newStatusProtocol(logger, cfg, hosts[0], &mockStatusSource{unsafe: eth.BlockID{Number: 10}})
+	newStatusProtocol(logger, cfg, hosts[1], &mockStatusSource{unsafe: eth.BlockID{Number: 20}, safe: eth.BlockID{Number: 15}})
+	newStatusProtocol(logger, cfg, hosts[2], &mockStatusSource{unsafe: eth.BlockID{Number: 30}})
+	newStatusProtocol(logger, &otherGenesis, hosts[3], nil)
+	newStatusProtocol(logger, &otherChain, hosts[4], nil)
+	self.inboundGrace = 100 * time.Millisecond
+	// peers that only respond to the handshake, and never start it themselves
+	hosts[5].SetStreamHandler(StatusProtocolID, (&statusProtocol{log: logger, cfg: cfg, h: hosts[5]}).handleStream)
+	hosts[6].SetStreamHandler(StatusProtocolID, (&statusProtocol{log: logger, cfg: &otherChain, h: hosts[6]}).handleStream)
+
+	// peers dialing us start the handshake, we start it with peers we dial
+	_, err = mnet.ConnectPeers(hosts[1].ID(), hosts[0].ID())
+	require.NoError(t, err)
+	for _, h := range hosts[2:5] {
+		_, err = mnet.ConnectPeers(hosts[0].ID(), h.ID())
+		require.NoError(t, err)
+	}
+	// we start the handshake with peers that dialed us, but did not start it themselves
+	for _, h := range hosts[5:] {
+		_, err = mnet.ConnectPeers(h.ID(), hosts[0].ID())
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool {
+		return GetPeerStatus(hosts[0], hosts[1].ID()) != nil && GetPeerStatus(hosts[0], hosts[2].ID()) != nil &&
+			GetPeerStatus(hosts[1], hosts[0].ID()) != nil && GetPeerStatus(hosts[2], hosts[0].ID()) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return hosts[0].Network().Connectedness(hosts[3].ID()) != network.Connected &&
+			hosts[0].Network().Connectedness(hosts[4].ID()) != network.Connected
+	}, 5*time.Second, 10*time.Millisecond, "peers on a different chain or genesis are disconnected")
+	require.Nil(t, GetPeerStatus(hosts[0], hosts[3].ID()))
+	require.Nil(t, GetPeerStatus(hosts[0], hosts[4].ID()))
+	require.Eventually(t, func() bool {
+		return GetPeerStatus(hosts[0], hosts[5].ID()) != nil &&
+			hosts[0].Network().Connectedness(hosts[6].ID()) != network.Connected
+	}, 5*time.Second, 10*time.Millisecond, "inbound peers that do not start the handshake are checked too")
+	require.Nil(t, GetPeerStatus(hosts[0], hosts[6].ID()))
+
+	status := GetPeerStatus(hosts[0], hosts[1].ID())
+	require.Equal(t, uint64(901), status.ChainID)
+	require.Equal(t, cfg.Genesis.L2, status.L2Genesis)
+	require.Equal(t, eth.BlockID{Number: 20}, status.Unsafe)
+	require.Equal(t, eth.BlockID{Number: 15}, status.Safe)
+	require.Equal(t, "v0.0.1-test", status.Version)
+	require.Equal(t, eth.BlockID{Number: 10}, GetPeerStatus(hosts[1], hosts[0].ID()).Unsafe)
+
+	require.Equal(t, []peer.ID{hosts[2].ID(), hosts[1].ID(), hosts[5].ID()}, SyncPeers(hosts[0]))
+
+	// the status can be requested again, to update it
+	remote, err := self.RequestStatus(context.Background(), hosts[2].ID())
+	require.NoError(t, err)
+	require.Equal(t, eth.BlockID{Number: 30}, remote.Unsafe)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.19
+
+package p2p
+
+import (
+	quic "github.com/libp2p/go-libp2p-quic-transport"
+	lconf "github.com/libp2p/go-libp2p/config"
+)
+
+func newQUICTransport() (lconf.TptC, error) {
+	return lconf.TransportConstructor(quic.NewTransport)
+}
@@ -0,0 +1,14 @@
+//go:build go1.19
+
+package p2p
+
+import (
+	"errors"
+
+	lconf "github.com/libp2p/go-libp2p/config"
+)
+
+// The QUIC transport depends on quic-go v0.25, which deliberately does not build with Go 1.19 and later.
+func newQUICTransport() (lconf.TptC, error) {
+	return nil, errors.New("the QUIC transport is not supported by builds with Go 1.19 or later")
+}
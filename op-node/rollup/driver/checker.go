@@ -0,0 +1,261 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	gosync "sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// checkTimeout is the time to compare a safe block with all the references
+const checkTimeout = 10 * time.Second
+
+// divergenceChecks is the number of consecutive checks that must diverge from a reference,
+// before the divergence is reported and the driver halts.
+const divergenceChecks = 3
+
+// CheckedL2 is a L2 node that the checker retrieves blocks from, by block number.
+type CheckedL2 interface {
+	L2BlockRefByNumber(ctx context.Context, l2Num *big.Int) (eth.L2BlockRef, error)
+	// L2BlockRefByLabel returns the block the label points to, or an error if the node does not track the label.
+	L2BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L2BlockRef, error)
+	// GetBlockHeader returns the header of the given block tag, or nil if there is no such block.
+	GetBlockHeader(ctx context.Context, blockTag string) (*types.Header, error)
+}
+
+type CheckerMetrics interface {
+	// RecordReferenceCheck records the comparison of a safe block with the references.
+	RecordReferenceCheck(diverged bool)
+}
+
+// Reference is a trusted L2 node that the checker compares the safe L2 chain with.
+type Reference struct {
+	// Name identifies the reference in logs and the checker status, e.g. by its RPC address
+	Name   string
+	Client CheckedL2
+}
+
+// Divergence describes a safe L2 block that does not match the block of a reference at the same height.
+type Divergence struct {
+	Reference       string         `json:"reference"`
+	Local           eth.L2BlockRef `json:"local"`
+	LocalStateRoot  common.Hash    `json:"localStateRoot"`
+	Remote          eth.L2BlockRef `json:"remote"`
+	RemoteStateRoot common.Hash    `json:"remoteStateRoot"`
+}
+
+// CheckerStatus is the result of the latest comparisons of the safe L2 chain with the references.
+type CheckerStatus struct {
+	// Checked is the latest safe block that was compared with any of the references
+	Checked eth.L2BlockRef `json:"checked"`
+	// Divergence is the latest divergence from a reference, nil if the latest check matched all references
+	Divergence *Divergence `json:"divergence,omitempty"`
+	// Halted is true if the driver halted because of a divergence
+	Halted bool `json:"halted"`
+}
+
+// Checker compares the safe L2 chain with trusted reference nodes, to detect if the rollup node derives
+// a different chain than the references. It compares the block hash and state root of every new safe head
+// with the canonical block of each reference at the same height. The block hash commits to all previous blocks,
+// so a divergence anywhere in the chain is detected at the next safe head.
+// References that do not have the block yet, or that did not derive it from L1 yet, are skipped:
+// the unsafe blocks of a reference may still be reorged. If a reference does not serve its safe head,
+// its canonical block is compared instead.
+// A divergence is only reported, and halts the driver, if it persists across several consecutive checks,
+// so a transient reorg of a reference is not mistaken for a divergence.
+// The comparisons run in the background, and do not hold up the driver.
+type Checker struct {
+	local      CheckedL2
+	references []Reference
+	halt       bool
+	metrics    CheckerMetrics // may be nil
+	log        log.Logger
+
+	// safeHeads holds the latest safe head that is not checked yet
+	safeHeads chan eth.L2BlockRef
+	halted    chan struct{}
+	haltOnce  gosync.Once
+
+	// diverged is the number of consecutive checks that diverged from a reference
+	diverged int
+
+	mu     gosync.Mutex
+	status CheckerStatus
+
+	done chan struct{}
+	wg   gosync.WaitGroup
+}
+
+// NewChecker creates a checker that compares the safe blocks of the local engine with the references.
+// If halt is true, the driver stops following the L1 chain and the sequencer when a divergence is detected.
+func NewChecker(local CheckedL2, references []Reference, halt bool, metrics CheckerMetrics, log log.Logger) *Checker {
+	return &Checker{
+		local:      local,
+		references: references,
+		halt:       halt,
+		metrics:    metrics,
+		log:        log,
+		safeHeads:  make(chan eth.L2BlockRef, 1),
+		halted:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start starts comparing the safe heads in the background.
+func (c *Checker) Start() {
+	c.wg.Add(1)
+	go c.loop()
+}
+
+func (c *Checker) Close() {
+	close(c.done)
+	c.wg.Wait()
+}
+
+// OnSafeHead schedules the new safe head to be checked. It replaces any safe head that is not checked yet,
+// and never blocks. It must not be called concurrently.
+func (c *Checker) OnSafeHead(ref eth.L2BlockRef) {
+	for {
+		select {
+		case c.safeHeads <- ref:
+			return
+		default:
+		}
+		select {
+		case <-c.safeHeads:
+		default:
+		}
+	}
+}
+
+// Halted is closed when the driver must halt, because of a divergence.
+func (c *Checker) Halted() <-chan struct{} {
+	return c.halted
+}
+
+// Status returns the result of the latest comparisons.
+func (c *Checker) Status() *CheckerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.status
+	if out.Divergence != nil {
+		div := *out.Divergence
+		out.Divergence = &div
+	}
+	return &out
+}
+
+func (c *Checker) loop() {
+	defer c.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for {
+		select {
+		case ref := <-c.safeHeads:
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			c.check(ctx, ref)
+			cancel()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// check compares the safe block with the references, and updates the status.
+func (c *Checker) check(ctx context.Context, safe eth.L2BlockRef) {
+	tag := hexutil.EncodeUint64(safe.Number)
+	localHeader, err := c.local.GetBlockHeader(ctx, tag)
+	if err != nil || localHeader == nil {
+		c.log.Warn("Failed to retrieve safe L2 block to compare with references", "safe", safe, "err", err)
+		return
+	}
+	// The safe head may be reorged already, then the new safe head is checked instead.
+	if localHeader.Hash() != safe.Hash {
+		c.log.Debug("Safe L2 block is not canonical anymore, skipping reference check", "safe", safe, "canonical", localHeader.Hash())
+		return
+	}
+
+	var divergence *Divergence
+	checked := false
+	for _, ref := range c.references {
+		refSafe, err := ref.Client.L2BlockRefByLabel(ctx, eth.Safe)
+		if errors.Is(err, ethereum.NotFound) {
+			c.log.Debug("Reference does not have a safe L2 block yet", "reference", ref.Name, "safe", safe)
+			continue
+		} else if err != nil {
+			c.log.Debug("Reference does not serve its safe L2 block, comparing with its canonical block", "reference", ref.Name, "err", err)
+		} else if refSafe.Number < safe.Number {
+			c.log.Debug("Reference did not derive the safe L2 block yet", "reference", ref.Name, "safe", safe, "referenceSafe", refSafe)
+			continue
+		}
+		remote, err := ref.Client.L2BlockRefByNumber(ctx, new(big.Int).SetUint64(safe.Number))
+		if errors.Is(err, ethereum.NotFound) {
+			c.log.Debug("Reference does not have the safe L2 block yet", "reference", ref.Name, "safe", safe)
+			continue
+		} else if err != nil {
+			c.log.Warn("Failed to retrieve L2 block from reference", "reference", ref.Name, "number", safe.Number, "err", err)
+			continue
+		}
+		remoteHeader, err := ref.Client.GetBlockHeader(ctx, tag)
+		if err != nil || remoteHeader == nil {
+			c.log.Warn("Failed to retrieve L2 block header from reference", "reference", ref.Name, "number", safe.Number, "err", err)
+			continue
+		}
+		// The reference may reorg between the two requests
+		if remoteHeader.Hash() != remote.Hash {
+			c.log.Debug("Reference L2 block changed during check", "reference", ref.Name, "remote", remote, "header", remoteHeader.Hash())
+			continue
+		}
+		checked = true
+		if remote.Hash == safe.Hash && remoteHeader.Root == localHeader.Root {
+			continue
+		}
+		divergence = &Divergence{
+			Reference:       ref.Name,
+			Local:           safe,
+			LocalStateRoot:  localHeader.Root,
+			Remote:          remote,
+			RemoteStateRoot: remoteHeader.Root,
+		}
+	}
+	if !checked {
+		return
+	}
+	if divergence != nil {
+		c.diverged++
+	} else {
+		c.diverged = 0
+	}
+	if divergence != nil && c.diverged < divergenceChecks {
+		c.log.Warn("Safe L2 block differs from reference, checking again at the next safe head", "reference", divergence.Reference,
+			"local", safe, "remote", divergence.Remote, "checks", c.diverged)
+		divergence = nil
+	} else if divergence != nil {
+		c.log.Error("Safe L2 block diverged from reference", "reference", divergence.Reference, "local", safe, "remote", divergence.Remote,
+			"localStateRoot", divergence.LocalStateRoot, "remoteStateRoot", divergence.RemoteStateRoot, "checks", c.diverged)
+	}
+	if c.metrics != nil {
+		c.metrics.RecordReferenceCheck(divergence != nil)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if divergence == nil && c.status.Divergence != nil {
+		c.log.Info("Safe L2 chain matches the references again", "safe", safe)
+	}
+	c.status.Checked = safe
+	c.status.Divergence = divergence
+	if divergence != nil && c.halt {
+		c.status.Halted = true
+		c.haltOnce.Do(func() { close(c.halted) })
+	}
+}
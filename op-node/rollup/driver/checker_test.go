@@ -0,0 +1,184 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/testlog"
+)
+
+// fakeCheckedL2 serves the canonical headers by block number
+type fakeCheckedL2 struct {
+	headers map[uint64]*types.Header
+	// safe is the number of the safe block, the safe label is not tracked if nil
+	safe *uint64
+}
+
+func (f *fakeCheckedL2) ref(num uint64) (eth.L2BlockRef, error) {
+	header, ok := f.headers[num]
+	if !ok {
+		return eth.L2BlockRef{}, ethereum.NotFound
+	}
+	return eth.L2BlockRef{Hash: header.Hash(), Number: header.Number.Uint64(), ParentHash: header.ParentHash}, nil
+}
+
+func (f *fakeCheckedL2) L2BlockRefByNumber(ctx context.Context, l2Num *big.Int) (eth.L2BlockRef, error) {
+	return f.ref(l2Num.Uint64())
+}
+
+func (f *fakeCheckedL2) L2BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L2BlockRef, error) {
+	if label != eth.Safe || f.safe == nil {
+		return eth.L2BlockRef{}, errors.New("unsupported block label")
+	}
+	return f.ref(*f.safe)
+}
+
+func (f *fakeCheckedL2) GetBlockHeader(ctx context.Context, blockTag string) (*types.Header, error) {
+	num, err := hexutil.DecodeUint64(blockTag)
+	if err != nil {
+		return nil, err
+	}
+	return f.headers[num], nil
+}
+
+type fakeCheckerMetrics struct {
+	checks, divergences int
+}
+
+func (m *fakeCheckerMetrics) RecordReferenceCheck(diverged bool) {
+	m.checks++
+	if diverged {
+		m.divergences++
+	}
+}
+
+func checkerHeader(num uint64, root byte) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(num), Root: common.Hash{root}}
+}
+
+func checkerRef(h *types.Header) eth.L2BlockRef {
+	return eth.L2BlockRef{Hash: h.Hash(), Number: h.Number.Uint64(), ParentHash: h.ParentHash}
+}
+
+func TestChecker(t *testing.T) {
+	header, ref := checkerHeader, checkerRef
+	local := &fakeCheckedL2{headers: map[uint64]*types.Header{1: header(1, 0xa1), 2: header(2, 0xa2), 3: header(3, 0xa3)}}
+	// the first reference does not have block 3 yet, the second reference differs at block 3.
+	// Neither reference serves its safe head, so their canonical blocks are compared.
+	refA := &fakeCheckedL2{headers: map[uint64]*types.Header{1: local.headers[1], 2: local.headers[2]}}
+	refB := &fakeCheckedL2{headers: map[uint64]*types.Header{1: local.headers[1], 2: local.headers[2], 3: header(3, 0xb3)}}
+
+	logger := testlog.Logger(t, log.LvlCrit)
+	m := &fakeCheckerMetrics{}
+	c := NewChecker(local, []Reference{{Name: "a", Client: refA}, {Name: "b", Client: refB}}, false, m, logger)
+
+	c.check(context.Background(), ref(local.headers[2]))
+	require.Equal(t, &CheckerStatus{Checked: ref(local.headers[2])}, c.Status())
+	require.Equal(t, 1, m.checks)
+
+	// a single differing check is not reported, the reference may reorg its unsafe blocks
+	c.check(context.Background(), ref(local.headers[3]))
+	require.Equal(t, &CheckerStatus{Checked: ref(local.headers[3])}, c.Status())
+	require.Equal(t, 0, m.divergences)
+
+	// the reference reorged to the same chain
+	refB.headers[3] = local.headers[3]
+	c.check(context.Background(), ref(local.headers[3]))
+	require.Nil(t, c.Status().Divergence)
+	require.Equal(t, 3, m.checks)
+
+	// a divergence that persists across several checks is reported
+	refB.headers[3] = header(3, 0xb3)
+	for i := 0; i < divergenceChecks; i++ {
+		c.check(context.Background(), ref(local.headers[3]))
+	}
+	status := c.Status()
+	require.Equal(t, ref(local.headers[3]), status.Checked)
+	require.Equal(t, &Divergence{
+		Reference:       "b",
+		Local:           ref(local.headers[3]),
+		LocalStateRoot:  common.Hash{0xa3},
+		Remote:          ref(refB.headers[3]),
+		RemoteStateRoot: common.Hash{0xb3},
+	}, status.Divergence)
+	require.False(t, status.Halted)
+	require.Equal(t, 1, m.divergences)
+
+	// the reference reorged to the same chain
+	refB.headers[3] = local.headers[3]
+	c.check(context.Background(), ref(local.headers[3]))
+	require.Nil(t, c.Status().Divergence)
+
+	// the safe head is not checked if no reference has the block
+	checks := m.checks
+	c.check(context.Background(), eth.L2BlockRef{Number: 4})
+	require.Equal(t, ref(local.headers[3]), c.Status().Checked)
+	require.Equal(t, checks, m.checks)
+
+	// the driver is told to halt at a persistent divergence, if enabled
+	refC := &fakeCheckedL2{headers: map[uint64]*types.Header{2: header(2, 0xc2)}}
+	c = NewChecker(local, []Reference{{Name: "c", Client: refC}}, true, nil, logger)
+	for i := 0; i < divergenceChecks; i++ {
+		select {
+		case <-c.Halted():
+			t.Fatal("unexpected halt before the divergence persisted")
+		default:
+		}
+		c.check(context.Background(), ref(local.headers[2]))
+	}
+	require.True(t, c.Status().Halted)
+	select {
+	case <-c.Halted():
+	default:
+		t.Fatal("expected checker to halt the driver")
+	}
+}
+
+func TestCheckerReferenceSafeHead(t *testing.T) {
+	header, ref := checkerHeader, checkerRef
+	local := &fakeCheckedL2{headers: map[uint64]*types.Header{1: header(1, 0xa1), 2: header(2, 0xa2), 3: header(3, 0xa3)}}
+	// the reference has a different unsafe block 3, which it may still reorg
+	safe := uint64(2)
+	reference := &fakeCheckedL2{headers: map[uint64]*types.Header{1: local.headers[1], 2: local.headers[2], 3: header(3, 0xb3)}, safe: &safe}
+
+	m := &fakeCheckerMetrics{}
+	c := NewChecker(local, []Reference{{Name: "ref", Client: reference}}, true, m, testlog.Logger(t, log.LvlCrit))
+
+	// blocks above the safe head of the reference are not compared
+	for i := 0; i < divergenceChecks; i++ {
+		c.check(context.Background(), ref(local.headers[3]))
+	}
+	require.Equal(t, &CheckerStatus{}, c.Status())
+	require.Equal(t, 0, m.checks)
+
+	c.check(context.Background(), ref(local.headers[2]))
+	require.Equal(t, &CheckerStatus{Checked: ref(local.headers[2])}, c.Status())
+
+	// the reference derived block 3 from L1
+	safe = 3
+	for i := 0; i < divergenceChecks; i++ {
+		c.check(context.Background(), ref(local.headers[3]))
+	}
+	status := c.Status()
+	require.NotNil(t, status.Divergence)
+	require.True(t, status.Halted)
+	require.Equal(t, 1, m.divergences)
+}
+
+func TestCheckerOnSafeHead(t *testing.T) {
+	c := NewChecker(&fakeCheckedL2{}, nil, false, nil, testlog.Logger(t, log.LvlCrit))
+	// only the latest safe head is checked, if the checker falls behind
+	c.OnSafeHead(eth.L2BlockRef{Number: 1})
+	c.OnSafeHead(eth.L2BlockRef{Number: 2})
+	require.Equal(t, eth.L2BlockRef{Number: 2}, <-c.safeHeads)
+}
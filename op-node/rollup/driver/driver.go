@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -47,6 +48,7 @@ type L2Chain interface {
 	ForkchoiceUpdate(ctx context.Context, state *l2.ForkchoiceState, attr *l2.PayloadAttributes) (*l2.ForkchoiceUpdatedResult, error)
 	L2BlockRefByNumber(ctx context.Context, l2Num *big.Int) (eth.L2BlockRef, error)
 	L2BlockRefByHash(ctx context.Context, l2Hash common.Hash) (eth.L2BlockRef, error)
+	PayloadByNumber(ctx context.Context, number *big.Int) (*l2.ExecutionPayload, error)
 }
 
 type outputInterface interface {
@@ -54,8 +56,13 @@ type outputInterface interface {
 	// It returns the new L2 head and L2 Safe head and if there was a reorg. This function must return if there was a reorg otherwise the L2 chain must be traversed.
 	insertEpoch(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.L2BlockRef, l2Finalized eth.BlockID, l1Input []eth.BlockID) (eth.L2BlockRef, eth.L2BlockRef, bool, error)
 
-	// createNewBlock builds a new block based on the L2 Head, L1 Origin, and the current mempool.
-	createNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *l2.ExecutionPayload, error)
+	// startBuildingBlock makes the engine start building a new block based on the L2 Head, L1 Origin, and the current mempool.
+	// If noTxPool is true, the block does not include transactions from the mempool.
+	// The forced transactions are included right after the deposits, if the block may include transactions.
+	startBuildingBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef, noTxPool bool, forced []*types.Transaction) (*blockBuild, error)
+
+	// sealBlock retrieves the block that the engine built, and inserts it as the new L2 Head.
+	sealBlock(ctx context.Context, build *blockBuild, l2SafeHead eth.BlockID, l2Finalized eth.BlockID) (eth.L2BlockRef, *l2.ExecutionPayload, error)
 
 	// processBlock simply tries to add the block to the chain, reorging if necessary, and updates the forkchoice of the engine.
 	processBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, payload *l2.ExecutionPayload) error
@@ -66,7 +73,7 @@ type Network interface {
 	PublishL2Payload(ctx context.Context, payload *l2.ExecutionPayload) error
 }
 
-func NewDriver(cfg rollup.Config, l2 *l2.Source, l1 *l1.Source, network Network, log log.Logger, snapshotLog log.Logger, sequencer bool) *Driver {
+func NewDriver(cfg rollup.Config, l2 *l2.Source, l1 *l1.Source, network Network, metrics SequencerMetrics, log log.Logger, snapshotLog log.Logger, sequencer bool, throttle ThrottleConfig, inclusion InclusionConfig, checker *Checker) *Driver {
 	output := &outputImpl{
 		Config: cfg,
 		dl:     l1,
@@ -74,7 +81,7 @@ func NewDriver(cfg rollup.Config, l2 *l2.Source, l1 *l1.Source, network Network,
 		log:    log,
 	}
 	return &Driver{
-		s: NewState(log, snapshotLog, cfg, l1, l2, output, network, sequencer),
+		s: NewState(log, snapshotLog, cfg, l1, l2, output, network, metrics, sequencer, throttle, inclusion, checker),
 	}
 }
 
@@ -86,6 +93,36 @@ func (d *Driver) OnUnsafeL2Payload(ctx context.Context, payload *l2.ExecutionPay
 	return d.s.OnUnsafeL2Payload(ctx, payload)
 }
 
+// QueueTransaction queues a signed L2 transaction, for the sequencer to force into its next blocks.
+func (d *Driver) QueueTransaction(tx *types.Transaction) error {
+	return d.s.QueueTransaction(tx)
+}
+
+// InclusionStatus returns the status of a queued transaction, or nil if the transaction is unknown.
+func (d *Driver) InclusionStatus(hash common.Hash) *InclusionStatus {
+	return d.s.inclusion.Status(hash)
+}
+
+// CheckerStatus returns the result of the latest comparisons of the safe L2 chain with the references,
+// or nil if the driver does not check against references.
+func (d *Driver) CheckerStatus() *CheckerStatus {
+	if d.s.checker == nil {
+		return nil
+	}
+	return d.s.checker.Status()
+}
+
+// SyncStatus returns the L1 and L2 heads currently tracked by the driver.
+func (d *Driver) SyncStatus(ctx context.Context) (*SyncStatus, error) {
+	return d.s.SyncStatus(ctx)
+}
+
+// SubscribeHeadChanges subscribes to changes of the L1 and L2 heads tracked by the driver.
+// Changes are dropped while the channel is full, the driver never waits for the subscriber.
+func (d *Driver) SubscribeHeadChanges(ch chan<- HeadChange) event.Subscription {
+	return d.s.SubscribeHeadChanges(ch)
+}
+
 func (d *Driver) Start(ctx context.Context) error {
 	return d.s.Start(ctx)
 }
@@ -0,0 +1,245 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+)
+
+const (
+	// maxQueuedTxs is the maximum number of forced transactions waiting for inclusion
+	maxQueuedTxs = 256
+	// maxInclusionStatuses is the number of forced transactions to remember the final status of
+	maxInclusionStatuses = 1024
+)
+
+var ErrInclusionQueueFull = errors.New("inclusion queue is full")
+
+// InclusionConfig limits the forced transactions that the sequencer includes per block.
+type InclusionConfig struct {
+	// MaxTxsPerBlock is the maximum number of forced transactions per block, 0 disables forced inclusion.
+	MaxTxsPerBlock uint64
+	// MaxGasPerBlock is the maximum sum of the gas limits of the forced transactions per block.
+	MaxGasPerBlock uint64
+}
+
+type InclusionState string
+
+const (
+	// InclusionQueued is the state of a transaction that waits to be included in a block
+	InclusionQueued InclusionState = "queued"
+	// InclusionIncluded is the state of a transaction that was included in a block
+	InclusionIncluded InclusionState = "included"
+	// InclusionDropped is the state of a transaction that was put in the payload attributes of a block,
+	// but that the engine did not include, e.g. because of an invalid nonce or insufficient balance.
+	InclusionDropped InclusionState = "dropped"
+)
+
+// InclusionStatus is the status of a forced transaction.
+type InclusionStatus struct {
+	State InclusionState `json:"state"`
+	// Block is the block the transaction was included in, or dropped from. Nil if the transaction is queued.
+	// Until that block is safe, the transaction is queued again if the block is reorged out.
+	Block *eth.BlockID `json:"block,omitempty"`
+}
+
+// forcedTx is a transaction of the inclusion queue.
+type forcedTx struct {
+	tx *types.Transaction
+	// block is the unsafe block the transaction was sealed in, nil if the transaction waits to be included
+	block *eth.BlockID
+}
+
+// inclusionQueue holds transactions that the sequencer forces into its blocks, right after the deposits,
+// in the order they were queued. The transactions stay in the queue until the block they were sealed in is safe,
+// to force them again if that block is reorged out. The queue is safe for concurrent use.
+type inclusionQueue struct {
+	cfg    InclusionConfig
+	signer types.Signer
+
+	mu     sync.Mutex
+	queued []*forcedTx
+	// statuses of the queued transactions, and of the most recently included and dropped transactions
+	statuses map[common.Hash]*InclusionStatus
+	// done lists the transactions in statuses that were included or dropped in a safe block, the oldest first
+	done []common.Hash
+}
+
+func newInclusionQueue(cfg InclusionConfig, chainID *big.Int) *inclusionQueue {
+	return &inclusionQueue{
+		cfg:      cfg,
+		signer:   types.LatestSignerForChainID(chainID),
+		statuses: make(map[common.Hash]*InclusionStatus),
+	}
+}
+
+// Enqueue adds a signed L2 transaction to the end of the queue.
+func (q *inclusionQueue) Enqueue(tx *types.Transaction) error {
+	if q.cfg.MaxTxsPerBlock == 0 {
+		return errors.New("forced inclusion of transactions is disabled")
+	}
+	if tx.Type() == types.DepositTxType {
+		return errors.New("deposit transactions cannot be forced")
+	}
+	if tx.Gas() > q.cfg.MaxGasPerBlock {
+		return fmt.Errorf("transaction gas limit %d exceeds the forced transactions gas limit per block %d", tx.Gas(), q.cfg.MaxGasPerBlock)
+	}
+	if _, err := types.Sender(q.signer, tx); err != nil {
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if status, ok := q.statuses[tx.Hash()]; ok {
+		return fmt.Errorf("transaction %s is known already, with state %s", tx.Hash(), status.State)
+	}
+	if len(q.queued) >= maxQueuedTxs {
+		return ErrInclusionQueueFull
+	}
+	q.queued = append(q.queued, &forcedTx{tx: tx})
+	q.statuses[tx.Hash()] = &InclusionStatus{State: InclusionQueued}
+	return nil
+}
+
+// Status returns the status of a forced transaction, or nil if the transaction is unknown.
+func (q *inclusionQueue) Status(hash common.Hash) *InclusionStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if status, ok := q.statuses[hash]; ok {
+		out := *status
+		return &out
+	}
+	return nil
+}
+
+// next returns the transactions to include in the next block, from the start of the queue, within the per-block limits.
+// The transactions that were sealed in an unsafe block already are skipped.
+func (q *inclusionQueue) next() []*types.Transaction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []*types.Transaction
+	var gas uint64
+	for _, f := range q.queued {
+		if f.block != nil {
+			continue
+		}
+		if uint64(len(out)) >= q.cfg.MaxTxsPerBlock || gas+f.tx.Gas() > q.cfg.MaxGasPerBlock {
+			break
+		}
+		out = append(out, f.tx)
+		gas += f.tx.Gas()
+	}
+	return out
+}
+
+// onSealed updates the status of the given forced transactions, that were in the attributes of the sealed payload.
+// They stay in the queue until the block is safe, whether the engine included them or not.
+// Other queued transactions may be in the payload too, if the engine took them from its transaction pool,
+// e.g. after a reorg: they are included as well.
+func (q *inclusionQueue) onSealed(forced []*types.Transaction, payload *l2.ExecutionPayload) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queued) == 0 {
+		return
+	}
+	included := make(map[common.Hash]struct{}, len(payload.Transactions))
+	for _, data := range payload.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(data); err == nil {
+			included[tx.Hash()] = struct{}{}
+		}
+	}
+	block := payload.ID()
+
+	sealed := make(map[common.Hash]struct{}, len(forced))
+	for _, tx := range forced {
+		sealed[tx.Hash()] = struct{}{}
+	}
+	for _, f := range q.queued {
+		hash := f.tx.Hash()
+		if f.block != nil {
+			continue
+		}
+		_, isForced := sealed[hash]
+		_, isIncluded := included[hash]
+		if !isForced && !isIncluded {
+			continue
+		}
+		state := InclusionDropped
+		if isIncluded {
+			state = InclusionIncluded
+		}
+		f.block = &block
+		q.statuses[hash] = &InclusionStatus{State: state, Block: &block}
+	}
+}
+
+// sealedBlocks returns the unsafe blocks that hold queued transactions.
+func (q *inclusionQueue) sealedBlocks() []eth.BlockID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []eth.BlockID
+	seen := make(map[eth.BlockID]struct{})
+	for _, f := range q.queued {
+		if f.block == nil {
+			continue
+		}
+		if _, ok := seen[*f.block]; !ok {
+			seen[*f.block] = struct{}{}
+			out = append(out, *f.block)
+		}
+	}
+	return out
+}
+
+// onReorged queues the transactions of the given blocks again, since the blocks are not canonical anymore.
+// They keep their place in the queue.
+func (q *inclusionQueue) onReorged(blocks []eth.BlockID) {
+	if len(blocks) == 0 {
+		return
+	}
+	reorged := make(map[eth.BlockID]struct{}, len(blocks))
+	for _, id := range blocks {
+		reorged[id] = struct{}{}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, f := range q.queued {
+		if f.block == nil {
+			continue
+		}
+		if _, ok := reorged[*f.block]; ok {
+			f.block = nil
+			q.statuses[f.tx.Hash()] = &InclusionStatus{State: InclusionQueued}
+		}
+	}
+}
+
+// onSafe removes the transactions of the blocks up to the given safe block number from the queue.
+// Their blocks must be canonical, their status is final.
+func (q *inclusionQueue) onSafe(safe uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	remaining := q.queued[:0]
+	for _, f := range q.queued {
+		if f.block != nil && f.block.Number <= safe {
+			q.done = append(q.done, f.tx.Hash())
+		} else {
+			remaining = append(remaining, f)
+		}
+	}
+	q.queued = remaining
+	// forget the oldest final statuses
+	for len(q.done) > maxInclusionStatuses {
+		delete(q.statuses, q.done[0])
+		q.done = q.done[1:]
+	}
+}
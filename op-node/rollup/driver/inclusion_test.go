@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+	"github.com/ethereum-optimism/optimism/op-node/l2"
+)
+
+func TestInclusionQueue(t *testing.T) {
+	chainID := big.NewInt(901)
+	q := newInclusionQueue(InclusionConfig{MaxTxsPerBlock: 2, MaxGasPerBlock: 100_000}, chainID)
+
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(chainID)
+	newTx := func(nonce uint64, gas uint64) *types.Transaction {
+		return types.MustSignNewTx(priv, signer, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			Gas:       gas,
+			GasFeeCap: big.NewInt(1),
+			To:        &common.Address{0x42},
+		})
+	}
+
+	hashes := func(txs ...*types.Transaction) (out []common.Hash) {
+		for _, tx := range txs {
+			out = append(out, tx.Hash())
+		}
+		return out
+	}
+
+	txA, txB, txC, txD := newTx(0, 21_000), newTx(1, 21_000), newTx(2, 21_000), newTx(3, 80_000)
+	for _, tx := range []*types.Transaction{txA, txB, txC, txD} {
+		require.NoError(t, q.Enqueue(tx))
+	}
+	require.Equal(t, &InclusionStatus{State: InclusionQueued}, q.Status(txA.Hash()))
+	require.Nil(t, q.Status(common.Hash{0x1}))
+
+	// invalid transactions are rejected
+	require.Error(t, q.Enqueue(txA), "duplicate")
+	require.Error(t, q.Enqueue(newTx(4, 100_001)), "exceeds the gas limit per block")
+	require.Error(t, q.Enqueue(types.NewTx(&types.DepositTx{Gas: 21_000})), "deposit")
+	otherChain := types.MustSignNewTx(priv, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21_000})
+	require.Error(t, q.Enqueue(otherChain), "signed for another chain")
+
+	// limited by the count per block
+	require.Equal(t, hashes(txA, txB), hashes(q.next()...))
+	// still queued if the block is not sealed
+	require.Equal(t, hashes(txA, txB), hashes(q.next()...))
+
+	// the engine dropped txB
+	dataA, err := txA.MarshalBinary()
+	require.NoError(t, err)
+	payload := &l2.ExecutionPayload{BlockHash: common.Hash{0xaa}, BlockNumber: 10, Transactions: []l2.Data{dataA}}
+	q.onSealed([]*types.Transaction{txA, txB}, payload)
+	require.Equal(t, InclusionIncluded, q.Status(txA.Hash()).State)
+	require.Equal(t, payload.ID(), *q.Status(txA.Hash()).Block)
+	require.Equal(t, InclusionDropped, q.Status(txB.Hash()).State)
+
+	// limited by the gas per block
+	require.Equal(t, hashes(txC), hashes(q.next()...))
+	q.onSealed([]*types.Transaction{txC}, payload)
+	require.Equal(t, hashes(txD), hashes(q.next()...))
+
+	// the block is reorged out before it is safe: its transactions are queued again, in their place
+	require.Equal(t, []eth.BlockID{payload.ID()}, q.sealedBlocks())
+	q.onReorged([]eth.BlockID{payload.ID()})
+	require.Equal(t, &InclusionStatus{State: InclusionQueued}, q.Status(txA.Hash()))
+	require.Equal(t, hashes(txA, txB), hashes(q.next()...))
+
+	// the engine took txC from its transaction pool, where the reorg put it back
+	dataB, err := txB.MarshalBinary()
+	require.NoError(t, err)
+	dataC, err := txC.MarshalBinary()
+	require.NoError(t, err)
+	canonical := &l2.ExecutionPayload{BlockHash: common.Hash{0xbb}, BlockNumber: 10, Transactions: []l2.Data{dataA, dataB, dataC}}
+	q.onSealed([]*types.Transaction{txA, txB}, canonical)
+	require.Equal(t, &InclusionStatus{State: InclusionIncluded, Block: &eth.BlockID{Hash: common.Hash{0xbb}, Number: 10}}, q.Status(txB.Hash()))
+	require.Equal(t, InclusionIncluded, q.Status(txC.Hash()).State)
+
+	// the transactions stay queued until their block is safe
+	q.onSafe(9)
+	require.Equal(t, []eth.BlockID{canonical.ID()}, q.sealedBlocks())
+	q.onSafe(10)
+	require.Empty(t, q.sealedBlocks())
+	require.Equal(t, InclusionIncluded, q.Status(txA.Hash()).State, "final status")
+	require.Equal(t, hashes(txD), hashes(q.next()...))
+
+	disabled := newInclusionQueue(InclusionConfig{}, chainID)
+	require.Error(t, disabled.Enqueue(txA))
+	require.Empty(t, disabled.next())
+}
@@ -0,0 +1,120 @@
+package driver
+
+import (
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// buildRetryDelay is how long the sequencer waits before trying again, when it failed to extend the L2 chain.
+const buildRetryDelay = time.Second
+
+type SequencerMetrics interface {
+	// RecordSequencerLag records how far the sequencer is behind the L2 timestamp of the block it seals.
+	RecordSequencerLag(lag time.Duration)
+}
+
+// blockScheduler plans when the sequencer starts building the next L2 block, and when it seals it.
+// A block is built during the block time before its L2 timestamp, according to the local clock:
+// the engine starts building it when the slot starts, and it is sealed at its L2 timestamp.
+// If that time passed already, e.g. after a stall, the block is built and sealed right away,
+// until the sequencer caught up.
+//
+// The schedule does not wait for L1: the block is built on the L1 origin known when the build starts.
+// If the next L1 block is not seen yet, e.g. during an L1 stall, the block extends the epoch of the current L1 origin.
+// Once that reaches the max sequencer drift, the blocks do not include transactions of the mempool anymore.
+type blockScheduler struct {
+	blockTime uint64
+	now       func() time.Time
+	metrics   SequencerMetrics // may be nil
+
+	timer *time.Timer
+	// planned is the time the timer is set for, zero if nothing is planned
+	planned time.Time
+	// retryAt is the earliest time of the next build, after the previous build failed to extend the chain
+	retryAt time.Time
+}
+
+func newBlockScheduler(blockTime uint64, now func() time.Time, metrics SequencerMetrics) *blockScheduler {
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	return &blockScheduler{blockTime: blockTime, now: now, metrics: metrics, timer: timer}
+}
+
+// C fires when the next block is to be started or sealed.
+func (b *blockScheduler) C() <-chan time.Time {
+	return b.timer.C
+}
+
+// startTime returns the time to start building the block on top of the given L2 head.
+func (b *blockScheduler) startTime(l2Head eth.L2BlockRef) time.Time {
+	at := time.Unix(int64(l2Head.Time), 0)
+	if at.Before(b.retryAt) {
+		return b.retryAt
+	}
+	return at
+}
+
+// sealTime returns the L2 timestamp of the block on top of the given L2 head.
+func (b *blockScheduler) sealTime(parent eth.L2BlockRef) time.Time {
+	return time.Unix(int64(parent.Time+b.blockTime), 0)
+}
+
+// Plan sets the timer to start building the block on top of the given L2 head.
+// The timer is only reset if the build time changed, e.g. when the L2 head changed.
+func (b *blockScheduler) Plan(l2Head eth.L2BlockRef) {
+	b.reset(b.startTime(l2Head))
+}
+
+// PlanSeal sets the timer to seal the block that is being built on top of the given parent.
+func (b *blockScheduler) PlanSeal(parent eth.L2BlockRef) {
+	b.reset(b.sealTime(parent))
+}
+
+func (b *blockScheduler) reset(at time.Time) {
+	if at.Equal(b.planned) {
+		return
+	}
+	if !b.planned.IsZero() && !b.timer.Stop() {
+		// drain the timer if it fired already, so it can be reset
+		select {
+		case <-b.timer.C:
+		default:
+		}
+	}
+	b.planned = at
+	if at.IsZero() { // nothing to plan
+		return
+	}
+	// a negative duration fires right away
+	b.timer.Reset(at.Sub(b.now()))
+}
+
+// OnBuildStart is called when the timer fired, and the engine starts building the next block.
+func (b *blockScheduler) OnBuildStart() {
+	b.planned = time.Time{}
+}
+
+// OnSeal is called when the timer fired, and the block on top of the given parent is sealed.
+func (b *blockScheduler) OnSeal(parent eth.L2BlockRef) {
+	b.planned = time.Time{}
+	if b.metrics != nil {
+		b.metrics.RecordSequencerLag(b.now().Sub(b.sealTime(parent)))
+	}
+}
+
+// OnBuildEnd is called after a block was sealed, or could not be built. If the build did not extend the L2 chain,
+// the next build is postponed, to not retry in a busy loop.
+func (b *blockScheduler) OnBuildEnd(extended bool) {
+	if extended {
+		b.retryAt = time.Time{}
+	} else {
+		b.retryAt = b.now().Add(buildRetryDelay)
+	}
+}
+
+func (b *blockScheduler) Stop() {
+	b.timer.Stop()
+}
@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+type lagMetrics struct {
+	lag time.Duration
+}
+
+func (m *lagMetrics) RecordSequencerLag(lag time.Duration) {
+	m.lag = lag
+}
+
+func TestBlockScheduler(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := &lagMetrics{}
+	b := newBlockScheduler(2, func() time.Time { return now }, m)
+	defer b.Stop()
+
+	// the timer runs on the real clock, a build is expected within the retry delay
+	requireBuild := func(build bool) {
+		t.Helper()
+		wait := 50 * time.Millisecond
+		if build {
+			wait = 2 * buildRetryDelay
+		}
+		select {
+		case <-b.C():
+			require.True(t, build, "unexpected build")
+		case <-time.After(wait):
+			require.False(t, build, "expected build")
+		}
+	}
+
+	// ahead of the schedule: wait for the slot of the next block to start
+	b.Plan(eth.L2BlockRef{Time: 1001})
+	require.Equal(t, time.Unix(1001, 0), b.planned)
+	requireBuild(false)
+
+	// the head changed, and the sequencer is behind: start building right away
+	head := eth.L2BlockRef{Time: 990}
+	b.Plan(head)
+	require.Equal(t, time.Unix(990, 0), b.planned)
+	requireBuild(true)
+	b.OnBuildStart()
+
+	// the block is sealed at its L2 timestamp, which passed already
+	b.PlanSeal(head)
+	require.Equal(t, time.Unix(992, 0), b.planned)
+	requireBuild(true)
+	b.OnSeal(head)
+	require.Equal(t, 8*time.Second, m.lag)
+
+	// the build failed: retry later, instead of right away
+	b.OnBuildEnd(false)
+	b.Plan(head)
+	require.Equal(t, now.Add(buildRetryDelay), b.planned)
+	requireBuild(false)
+
+	// planning the same head again does not reset the timer
+	b.Plan(head)
+	require.Equal(t, now.Add(buildRetryDelay), b.planned)
+
+	// the retry extended the chain: the next block is built right away, since it is still behind
+	now = now.Add(buildRetryDelay)
+	requireBuild(true)
+	b.OnBuildStart()
+	b.PlanSeal(head)
+	requireBuild(true)
+	b.OnSeal(head)
+	b.OnBuildEnd(true)
+	head = eth.L2BlockRef{Time: 992}
+	b.Plan(head)
+	require.Equal(t, time.Unix(992, 0), b.planned)
+	requireBuild(true)
+	b.OnBuildStart()
+
+	// on time: the block is sealed at its L2 timestamp
+	now = time.Unix(993, 0)
+	b.PlanSeal(head)
+	require.Equal(t, time.Unix(994, 0), b.planned)
+	requireBuild(false)
+}
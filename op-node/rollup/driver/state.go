@@ -3,7 +3,9 @@ package driver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	gosync "sync"
 	"time"
 
@@ -11,9 +13,31 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/l2"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/sync"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// SyncStatus is a snapshot of the L1 and L2 heads tracked by the driver.
+type SyncStatus struct {
+	// HeadL1 is the latest L1 block the driver has seen
+	HeadL1 eth.L1BlockRef `json:"headL1"`
+	// UnsafeL2 is the head of the L2 chain, including blocks that are not derived from L1 yet
+	UnsafeL2 eth.L2BlockRef `json:"unsafeL2"`
+	// SafeL2 is the head of the L2 chain as derived from L1
+	SafeL2 eth.L2BlockRef `json:"safeL2"`
+	// FinalizedL2 is the L2 block that will never be reversed, the L2 genesis if nothing is finalized yet
+	FinalizedL2 eth.BlockID `json:"finalizedL2"`
+}
+
+// HeadChange is emitted by the driver whenever any of the heads it tracks changes.
+type HeadChange struct {
+	Old SyncStatus
+	New SyncStatus
+	// Reorg is true if the unsafe or safe L2 head changed to a block that does not extend the previous head.
+	Reorg bool
+}
+
 type state struct {
 	// Chain State
 	l1Head      eth.L1BlockRef // Latest recorded head of the L1 Chain
@@ -21,18 +45,27 @@ type state struct {
 	l2SafeHead  eth.L2BlockRef // L2 Safe Head - this is the head of the L2 chain as derived from L1 (thus it is Sequencer window blocks behind)
 	l2Finalized eth.BlockID    // L2 Block that will never be reversed
 	l1WindowBuf []eth.BlockID  // l1WindowBuf buffers the next L1 block IDs to derive new L2 blocks from, with increasing block height.
+	building    *blockBuild    // Block that the engine is building as sequencer, nil if none
+	throttled   throttleLevel  // Throttling of the sequencer at the last block production
 
 	// Rollup config
 	Config    rollup.Config
 	sequencer bool
+	throttle  ThrottleConfig
+	inclusion *inclusionQueue
 
 	// Connections (in/out)
 	l1Heads          chan eth.L1BlockRef
 	unsafeL2Payloads chan *l2.ExecutionPayload
+	syncStatusReq    chan chan SyncStatus
+	headSubsLock     gosync.Mutex
+	headSubs         map[*headSubscriber]struct{}
 	l1               L1Chain
 	l2               L2Chain
 	output           outputInterface
-	network          Network // may be nil, network for is optional
+	network          Network          // may be nil, network for is optional
+	metrics          SequencerMetrics // may be nil
+	checker          *Checker         // may be nil, checking against references is optional
 
 	log         log.Logger
 	snapshotLog log.Logger
@@ -42,8 +75,12 @@ type state struct {
 }
 
 // NewState creates a new driver state. State changes take effect though the given output.
-// Optionally a network can be provided to publish things to other nodes than the engine of the driver.
-func NewState(log log.Logger, snapshotLog log.Logger, config rollup.Config, l1Chain L1Chain, l2Chain L2Chain, output outputInterface, network Network, sequencer bool) *state {
+// Optionally a network can be provided to publish things to other nodes than the engine of the driver,
+// and metrics to record the block production of the sequencer.
+// The sequencer throttles block production according to the throttle config, if the batcher falls behind,
+// and forces queued transactions into its blocks within the limits of the inclusion config.
+// Optionally a checker can be provided to compare the safe L2 chain with trusted reference nodes.
+func NewState(log log.Logger, snapshotLog log.Logger, config rollup.Config, l1Chain L1Chain, l2Chain L2Chain, output outputInterface, network Network, metrics SequencerMetrics, sequencer bool, throttle ThrottleConfig, inclusion InclusionConfig, checker *Checker) *state {
 	return &state{
 		Config:           config,
 		done:             make(chan struct{}),
@@ -53,9 +90,15 @@ func NewState(log log.Logger, snapshotLog log.Logger, config rollup.Config, l1Ch
 		l2:               l2Chain,
 		output:           output,
 		network:          network,
+		metrics:          metrics,
+		checker:          checker,
 		sequencer:        sequencer,
+		throttle:         throttle,
+		inclusion:        newInclusionQueue(inclusion, config.L2ChainID),
 		l1Heads:          make(chan eth.L1BlockRef, 10),
 		unsafeL2Payloads: make(chan *l2.ExecutionPayload, 10),
+		syncStatusReq:    make(chan chan SyncStatus),
+		headSubs:         make(map[*headSubscriber]struct{}),
 	}
 }
 
@@ -100,6 +143,9 @@ func (s *state) Start(ctx context.Context) error {
 
 	s.l1Head = l1Head
 
+	if s.checker != nil {
+		s.checker.Start()
+	}
 	s.wg.Add(1)
 	go s.loop()
 	return nil
@@ -108,6 +154,9 @@ func (s *state) Start(ctx context.Context) error {
 func (s *state) Close() error {
 	close(s.done)
 	s.wg.Wait()
+	if s.checker != nil {
+		s.checker.Close()
+	}
 	return nil
 }
 
@@ -129,6 +178,95 @@ func (s *state) OnUnsafeL2Payload(ctx context.Context, payload *l2.ExecutionPayl
 	}
 }
 
+// QueueTransaction queues a signed L2 transaction, to force into the next blocks of the sequencer.
+func (s *state) QueueTransaction(tx *types.Transaction) error {
+	if !s.sequencer {
+		return errors.New("cannot queue transaction, rollup node is not sequencing")
+	}
+	return s.inclusion.Enqueue(tx)
+}
+
+// SyncStatus returns the heads currently tracked by the state loop.
+func (s *state) SyncStatus(ctx context.Context) (*SyncStatus, error) {
+	respCh := make(chan SyncStatus, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, fmt.Errorf("driver is closed")
+	case s.syncStatusReq <- respCh:
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-respCh:
+		return &resp, nil
+	}
+}
+
+// headSubscriber is a subscriber to the head changes of the state loop.
+type headSubscriber struct {
+	ch chan<- HeadChange
+	// dropping is true while changes are dropped, because the channel is full
+	dropping bool
+}
+
+// SubscribeHeadChanges subscribes to changes of the heads tracked by the state loop.
+// The state loop never waits for the subscriber: changes are dropped while the channel is full,
+// so the subscriber should use a buffered channel and consume changes quickly.
+func (s *state) SubscribeHeadChanges(ch chan<- HeadChange) event.Subscription {
+	sub := &headSubscriber{ch: ch}
+	s.headSubsLock.Lock()
+	s.headSubs[sub] = struct{}{}
+	s.headSubsLock.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		s.headSubsLock.Lock()
+		delete(s.headSubs, sub)
+		s.headSubsLock.Unlock()
+		return nil
+	})
+}
+
+func (s *state) syncStatus() SyncStatus {
+	finalized := s.l2Finalized
+	if finalized == (eth.BlockID{}) {
+		finalized = s.Config.Genesis.L2
+	}
+	return SyncStatus{
+		HeadL1:      s.l1Head,
+		UnsafeL2:    s.l2Head,
+		SafeL2:      s.l2SafeHead,
+		FinalizedL2: finalized,
+	}
+}
+
+// emitHeadChange notifies subscribers if any of the heads changed since the previous sync status.
+func (s *state) emitHeadChange(prev SyncStatus, reorg bool) {
+	cur := s.syncStatus()
+	if cur == prev {
+		return
+	}
+	// A L2 head that does not move forward is replaced by another block, and thus reorged.
+	reorg = reorg ||
+		(cur.UnsafeL2 != prev.UnsafeL2 && cur.UnsafeL2.Number <= prev.UnsafeL2.Number) ||
+		(cur.SafeL2 != prev.SafeL2 && cur.SafeL2.Number <= prev.SafeL2.Number)
+	change := HeadChange{Old: prev, New: cur, Reorg: reorg}
+	s.headSubsLock.Lock()
+	defer s.headSubsLock.Unlock()
+	for sub := range s.headSubs {
+		select {
+		case sub.ch <- change:
+			sub.dropping = false
+		default:
+			if !sub.dropping {
+				s.log.Warn("Dropping head changes, subscriber is too slow", "head", cur.UnsafeL2)
+				sub.dropping = true
+			}
+		}
+	}
+}
+
 // l1WindowBufEnd returns the last block that should be used as `base` to L1ChainWindow.
 // This is either the last block of the window, or the L1 base block if the window is not populated.
 func (s *state) l1WindowBufEnd() eth.BlockID {
@@ -155,6 +293,7 @@ func (s *state) handleNewL1Block(ctx context.Context, newL1Head eth.L1BlockRef)
 		if s.l1WindowBufEnd().Hash == newL1Head.ParentHash {
 			s.l1WindowBuf = append(s.l1WindowBuf, newL1Head.ID())
 		}
+		s.verifyBuilding(ctx)
 		return nil
 	}
 
@@ -185,6 +324,8 @@ func (s *state) handleNewL1Block(ctx context.Context, newL1Head eth.L1BlockRef)
 	if s.l2SafeHead.Number >= safeL2Head.Number {
 		s.l2SafeHead = safeL2Head
 	}
+	s.cancelStaleBuilding()
+	s.verifyBuilding(ctx)
 
 	return nil
 }
@@ -225,9 +366,10 @@ func (s *state) findL1Origin(ctx context.Context) (eth.L1BlockRef, error) {
 	return currentOrigin, nil
 }
 
-// createNewL2Block builds a L2 block on top of the L2 Head (unsafe). Used by Sequencer nodes to
-// construct new L2 blocks. Verifier nodes will use handleEpoch instead.
-func (s *state) createNewL2Block(ctx context.Context) error {
+// startBuildingL2Block makes the engine start building a L2 block on top of the L2 Head (unsafe).
+// Used by Sequencer nodes to construct new L2 blocks. Verifier nodes will use handleEpoch instead.
+// The block is inserted into the chain when it is sealed with sealL2Block.
+func (s *state) startBuildingL2Block(ctx context.Context) error {
 	// Figure out which L1 origin block we're going to be building on top of.
 	l1Origin, err := s.findL1Origin(ctx)
 	if err != nil {
@@ -251,15 +393,49 @@ func (s *state) createNewL2Block(ctx context.Context) error {
 			s.l2Head, nextL2Time, l1Origin, l1Origin.Time)
 	}
 
-	// Actually create the new block.
-	newUnsafeL2Head, payload, err := s.output.createNewBlock(ctx, s.l2Head, s.l2SafeHead.ID(), s.l2Finalized, l1Origin)
+	// Throttle block production if the safe head does not keep up, since the unsafe blocks
+	// are reorged away if they are not submitted to L1 within the sequencing window.
+	throttled := s.throttle.level(s.l1Head, s.l2Head, s.l2SafeHead, s.Config.SeqWindowSize)
+	if throttled != s.throttled {
+		if throttled == throttleNone {
+			s.log.Info("Sequencer resumed regular block production, safe head caught up", "l2Head", s.l2Head, "l2SafeHead", s.l2SafeHead, "l1Head", s.l1Head)
+		} else {
+			s.log.Warn("Sequencer throttled block production, safe head falls behind", "throttle", throttled, "l2Head", s.l2Head, "l2SafeHead", s.l2SafeHead, "l1Head", s.l1Head)
+		}
+		s.throttled = throttled
+	}
+	if throttled == throttleStop {
+		s.log.Debug("Skipping block production because the safe head falls behind", "l2Head", s.l2Head, "l2SafeHead", s.l2SafeHead)
+		return nil
+	}
+
+	// Start building the new block, the engine keeps filling it until it is sealed.
+	build, err := s.output.startBuildingBlock(ctx, s.l2Head, s.l2SafeHead.ID(), s.l2Finalized, l1Origin, throttled == throttleNoTxPool, s.inclusion.next())
+	if err != nil {
+		s.log.Error("Could not start building new block as sequencer", "err", err, "l2UnsafeHead", s.l2Head, "l1Origin", l1Origin)
+		return err
+	}
+	s.building = build
+	return nil
+}
+
+// sealL2Block retrieves the block that the engine is building, and inserts it as the new L2 Head.
+func (s *state) sealL2Block(ctx context.Context) error {
+	build := s.building
+	if build == nil {
+		return errors.New("no block is being built")
+	}
+	s.building = nil
+
+	newUnsafeL2Head, payload, err := s.output.sealBlock(ctx, build, s.l2SafeHead.ID(), s.l2Finalized)
 	if err != nil {
-		s.log.Error("Could not extend chain as sequencer", "err", err, "l2UnsafeHead", s.l2Head, "l1Origin", l1Origin)
+		s.log.Error("Could not extend chain as sequencer", "err", err, "l2UnsafeHead", s.l2Head, "l1Origin", build.l1Origin)
 		return err
 	}
 
 	// Update our L2 head block based on the new unsafe block we just generated.
 	s.l2Head = newUnsafeL2Head
+	s.inclusion.onSealed(build.forced, payload)
 	s.log.Info("Sequenced new l2 block", "l2Head", s.l2Head, "l1Origin", s.l2Head.L1Origin, "txs", len(payload.Transactions), "time", s.l2Head.Time)
 
 	if s.network != nil {
@@ -272,6 +448,101 @@ func (s *state) createNewL2Block(ctx context.Context) error {
 	return nil
 }
 
+// cancelBuilding drops the block that the engine is building, if any.
+// The engine discards the payload when it is not retrieved.
+func (s *state) cancelBuilding(reason string) {
+	if s.building == nil {
+		return
+	}
+	s.log.Info("Cancelled building new block", "reason", reason, "parent", s.building.parent, "l1Origin", s.building.l1Origin, "id", s.building.id)
+	s.building = nil
+}
+
+// cancelStaleBuilding drops the block that is being built, if it does not extend the L2 head anymore.
+func (s *state) cancelStaleBuilding() {
+	if s.building != nil && s.building.parent != s.l2Head {
+		s.cancelBuilding("L2 head changed")
+	}
+}
+
+// updateInclusion queues the forced transactions of reorged out unsafe blocks again, unless the canonical chain holds them,
+// and removes the transactions of safe blocks from the inclusion queue.
+func (s *state) updateInclusion(ctx context.Context) {
+	var reorged []eth.BlockID
+	for _, id := range s.inclusion.sealedBlocks() {
+		if id.Number > s.l2Head.Number {
+			reorged = append(reorged, id)
+			continue
+		}
+		if id == s.l2Head.ID() {
+			continue
+		}
+		ref, err := s.l2.L2BlockRefByNumber(ctx, new(big.Int).SetUint64(id.Number))
+		if err != nil {
+			// retried on the next head change
+			s.log.Warn("Could not verify the block of forced transactions", "block", id, "err", err)
+			return
+		}
+		if ref.Hash != id.Hash {
+			reorged = append(reorged, id)
+		}
+	}
+	if len(reorged) > 0 {
+		// The canonical blocks may hold the transactions as well, e.g. if they were derived from the batches
+		// of the reorged blocks, or if the engine took them from its transaction pool.
+		from := s.l2Head.Number + 1
+		for _, id := range reorged {
+			if id.Number < from {
+				from = id.Number
+			}
+		}
+		var canonical []*l2.ExecutionPayload
+		for n := from; n <= s.l2Head.Number; n++ {
+			payload, err := s.l2.PayloadByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				s.log.Warn("Could not find forced transactions in the canonical L2 chain", "number", n, "err", err)
+				return
+			}
+			canonical = append(canonical, payload)
+		}
+		for _, id := range reorged {
+			s.log.Warn("Block of forced transactions was reorged out", "block", id, "l2Head", s.l2Head)
+		}
+		s.inclusion.onReorged(reorged)
+		for _, payload := range canonical {
+			s.inclusion.onSealed(nil, payload)
+		}
+	}
+	s.inclusion.onSafe(s.l2SafeHead.Number)
+}
+
+// verifyBuilding drops the block that is being built after an L1 head change, if its L1 origin is not canonical anymore,
+// or if it can adopt a newer L1 origin. The next block is then built on top of the new L1 head.
+func (s *state) verifyBuilding(ctx context.Context) {
+	if s.building == nil {
+		return
+	}
+	l1Origin := s.building.l1Origin
+	canonical, err := s.l1.L1BlockRefByNumber(ctx, l1Origin.Number)
+	if err != nil {
+		s.log.Warn("Could not verify the L1 origin of the block that is being built", "l1Origin", l1Origin, "err", err)
+		s.cancelBuilding("L1 origin not verified")
+		return
+	}
+	if canonical.Hash != l1Origin.Hash {
+		s.cancelBuilding("L1 origin reorged")
+		return
+	}
+	next, err := s.findL1Origin(ctx)
+	if err != nil {
+		s.log.Warn("Could not find the next L1 origin of the block that is being built", "l1Origin", l1Origin, "err", err)
+		return
+	}
+	if next.Hash != l1Origin.Hash {
+		s.cancelBuilding("newer L1 origin")
+	}
+}
+
 // handleEpoch attempts to insert a full L2 epoch on top of the L2 Safe Head.
 // It ensures that a full sequencing window is available and updates the state as needed.
 func (s *state) handleEpoch(ctx context.Context) (bool, error) {
@@ -349,32 +620,20 @@ func (s *state) loop() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Start a ticker to produce L2 blocks at a constant rate. Ticker will only run if we're
-	// running in Sequencer mode.
-	var l2BlockCreationTickerCh <-chan time.Time
+	// Schedule L2 blocks to be built during the block time before their L2 timestamps. The schedule is only used if
+	// we're running in Sequencer mode, because otherwise we'll be deriving our blocks via the stepping process.
+	var scheduler *blockScheduler
+	var l2BlockCreationCh <-chan time.Time
 	if s.sequencer {
-		l2BlockCreationTicker := time.NewTicker(time.Duration(s.Config.BlockTime) * time.Second)
-		defer l2BlockCreationTicker.Stop()
-		l2BlockCreationTickerCh = l2BlockCreationTicker.C
+		scheduler = newBlockScheduler(s.Config.BlockTime, time.Now, s.metrics)
+		defer scheduler.Stop()
+		scheduler.Plan(s.l2Head)
+		l2BlockCreationCh = scheduler.C()
 	}
 
 	// stepReqCh is used to request that the driver attempts to step forward by one L1 block.
 	stepReqCh := make(chan struct{}, 1)
 
-	// l2BlockCreationReqCh is used to request that the driver create a new L2 block. Only used if
-	// we're running in Sequencer mode, because otherwise we'll be deriving our blocks via the
-	// stepping process.
-	l2BlockCreationReqCh := make(chan struct{}, 1)
-
-	// reqL2BlockCreation requests that a block be created. Won't deadlock if the channel is full.
-	reqL2BlockCreation := func() {
-		select {
-		case l2BlockCreationReqCh <- struct{}{}:
-		// Don't deadlock if the channel is already full
-		default:
-		}
-	}
-
 	// reqStep requests that a driver stpe be taken. Won't deadlock if the channel is full.
 	// TODO: Rename step request
 	reqStep := func() {
@@ -385,34 +644,50 @@ func (s *state) loop() {
 		}
 	}
 
+	// halted is closed by the checker if the safe L2 chain diverged from a reference, and the driver must halt.
+	var halted <-chan struct{}
+	if s.checker != nil {
+		halted = s.checker.Halted()
+	}
+
 	// We call reqStep right away to finish syncing to the tip of the chain if we're behind.
 	// reqStep will also be triggered when the L1 head moves forward or if there was a reorg on the
 	// L1 chain that we need to handle.
 	reqStep()
 
 	for {
-		select {
-		case <-l2BlockCreationTickerCh:
-			s.log.Trace("L2 Creation Ticker")
-			s.snapshot("L2 Creation Ticker")
-			reqL2BlockCreation()
+		// Heads are compared before and after handling each event, to notify subscribers of any changes.
+		prevStatus := s.syncStatus()
+		reorged := false
 
-		case <-l2BlockCreationReqCh:
-			s.snapshot("L2 Block Creation Request")
-			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-			err := s.createNewL2Block(ctx)
-			cancel()
-			if err != nil {
-				s.log.Error("Error creating new L2 block", "err", err)
-			}
-
-			// We need to catch up to the next origin as quickly as possible. We can do this by
-			// requesting a new block ASAP instead of waiting for the next tick.
-			// TODO: If we want to consider confirmations, need to consider here too.
-			if s.l1Head.Number > s.l2Head.L1Origin.Number {
-				s.log.Trace("Asking for a second L2 block asap", "l2Head", s.l2Head)
-				// But not too quickly to minimize busy-waiting for new blocks
-				time.AfterFunc(time.Millisecond*10, reqL2BlockCreation)
+		select {
+		case <-l2BlockCreationCh:
+			if s.building == nil {
+				s.snapshot("L2 Block Creation")
+				scheduler.OnBuildStart()
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+				err := s.startBuildingL2Block(ctx)
+				cancel()
+				if err != nil {
+					s.log.Error("Error starting to build new L2 block", "err", err)
+				}
+				// Retry later if no block is being built, e.g. because block production is throttled.
+				if s.building == nil {
+					scheduler.OnBuildEnd(false)
+				}
+			} else {
+				s.snapshot("L2 Block Seal")
+				prevHead := s.l2Head
+				scheduler.OnSeal(prevHead)
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+				err := s.sealL2Block(ctx)
+				cancel()
+				if err != nil {
+					s.log.Error("Error sealing new L2 block", "err", err)
+				}
+				// If the sequencer is behind, e.g. when catching up to the L1 origin after a stall,
+				// the next block is planned right away, since its L2 timestamp has passed already.
+				scheduler.OnBuildEnd(s.l2Head != prevHead)
 			}
 
 		case payload := <-s.unsafeL2Payloads:
@@ -429,6 +704,8 @@ func (s *state) loop() {
 			cancel()
 			if err != nil {
 				s.log.Error("Error in handling new L1 Head", "err", err)
+				// The L1 origin of the block that is being built could not be verified
+				s.cancelBuilding("L1 head not handled")
 			}
 
 			// The block number of the L1 origin for the L2 safe head is at least SeqWindowSize
@@ -452,13 +729,7 @@ func (s *state) loop() {
 
 			if reorg {
 				s.log.Warn("Got reorg")
-
-				// If we're in sequencer mode and experiencing a reorg, we should request a new
-				// block ASAP. Not strictly necessary but means we'll recover from the reorg much
-				// faster than if we waited for the next tick.
-				if s.sequencer {
-					reqL2BlockCreation()
-				}
+				reorged = true
 			}
 
 			// The block number of the L1 origin for the L2 safe head is at least SeqWindowSize
@@ -471,6 +742,51 @@ func (s *state) loop() {
 				reqStep()
 			}
 
+		case respCh := <-s.syncStatusReq:
+			respCh <- s.syncStatus()
+
+		case <-halted:
+			s.log.Error("Halting the driver, the safe L2 chain diverged from a reference", "l2Head", s.l2Head, "l2SafeHead", s.l2SafeHead)
+			s.cancelBuilding("halted")
+			s.haltedLoop()
+			return
+
+		case <-s.done:
+			return
+		}
+
+		s.emitHeadChange(prevStatus, reorged)
+		if s.l2Head != prevStatus.UnsafeL2 || s.l2SafeHead != prevStatus.SafeL2 {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			s.updateInclusion(ctx)
+			cancel()
+		}
+		if s.checker != nil && s.l2SafeHead != prevStatus.SafeL2 {
+			s.checker.OnSafeHead(s.l2SafeHead)
+		}
+
+		// The L2 head may have changed, by a new block, a reorg, or a block from another node,
+		// so the block that is being built may not extend it anymore, and the next block is planned on top of it.
+		s.cancelStaleBuilding()
+		if scheduler != nil {
+			if s.building != nil {
+				scheduler.PlanSeal(s.building.parent)
+			} else {
+				scheduler.Plan(s.l2Head)
+			}
+		}
+	}
+}
+
+// haltedLoop keeps serving the sync status after the driver halted, until the state is closed.
+// New L1 heads and unsafe L2 payloads are dropped, the L2 chain is not extended anymore.
+func (s *state) haltedLoop() {
+	for {
+		select {
+		case <-s.l1Heads:
+		case <-s.unsafeL2Payloads:
+		case respCh := <-s.syncStatusReq:
+			respCh <- s.syncStatus()
 		case <-s.done:
 			return
 		}
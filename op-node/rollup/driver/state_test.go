@@ -2,6 +2,7 @@ package driver
 
 import (
 	"context"
+	"math/big"
 	"strconv"
 	"strings"
 	"testing"
@@ -13,6 +14,8 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/testlog"
 	"github.com/ethereum-optimism/optimism/op-node/testutils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,7 +57,11 @@ func (fn outputHandlerFn) insertEpoch(ctx context.Context, l2Head eth.L2BlockRef
 	return fn(ctx, l2Head, l2SafeHead, l2Finalized, l1Input)
 }
 
-func (fn outputHandlerFn) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *l2.ExecutionPayload, error) {
+func (fn outputHandlerFn) startBuildingBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef, noTxPool bool, forced []*types.Transaction) (*blockBuild, error) {
+	panic("Unimplemented")
+}
+
+func (fn outputHandlerFn) sealBlock(ctx context.Context, build *blockBuild, l2SafeHead eth.BlockID, l2Finalized eth.BlockID) (eth.L2BlockRef, *l2.ExecutionPayload, error) {
 	panic("Unimplemented")
 }
 
@@ -107,8 +114,19 @@ func stutterL2(t *testing.T, expectedWindow []testID, s *state, src *testutils.F
 	}
 }
 
+// awaitStep waits for the state loop to step, and fails the test if the loop is stuck.
+func awaitStep(t *testing.T, outputIn chan outputArgs) outputArgs {
+	select {
+	case args := <-outputIn:
+		return args
+	case <-time.After(5 * time.Second):
+		t.Fatal("state loop did not step")
+		return outputArgs{}
+	}
+}
+
 func advanceL2(t *testing.T, expectedWindow []testID, s *state, src *testutils.FakeChainSource, outputIn chan outputArgs, outputReturn chan outputReturnArgs) {
-	args := <-outputIn
+	args := awaitStep(t, outputIn)
 	assert.Equal(t, int(s.Config.SeqWindowSize), len(args.l1Window), "Invalid L1 window size")
 	assert.Equal(t, len(expectedWindow), len(args.l1Window), "L1 Window size does not match expectedWindow")
 	for i := range expectedWindow {
@@ -118,7 +136,7 @@ func advanceL2(t *testing.T, expectedWindow []testID, s *state, src *testutils.F
 }
 
 func reorg__L2(t *testing.T, expectedWindow []testID, s *state, src *testutils.FakeChainSource, outputIn chan outputArgs, outputReturn chan outputReturnArgs) {
-	args := <-outputIn
+	args := awaitStep(t, outputIn)
 	assert.Equal(t, int(s.Config.SeqWindowSize), len(args.l1Window), "Invalid L1 window size")
 	assert.Equal(t, len(expectedWindow), len(args.l1Window), "L1 Window size does not match expectedWindow")
 	for i := range expectedWindow {
@@ -135,6 +153,8 @@ type stateTestCase struct {
 	steps     []stateTestCaseStep
 	seqWindow int
 	genesis   rollup.Genesis
+	// stalledSubscriber subscribes to head changes, without ever consuming them
+	stalledSubscriber bool
 }
 
 func (tc *stateTestCase) Run(t *testing.T) {
@@ -151,11 +171,16 @@ func (tc *stateTestCase) Run(t *testing.T) {
 		return r.l2Head, r.l2Head, false, r.err
 	}
 	config := rollup.Config{SeqWindowSize: uint64(tc.seqWindow), Genesis: tc.genesis, BlockTime: 2}
-	state := NewState(log, log, config, chainSource, chainSource, outputHandlerFn(outputHandler), nil, false)
+	state := NewState(log, log, config, chainSource, chainSource, outputHandlerFn(outputHandler), nil, nil, false, ThrottleConfig{}, InclusionConfig{}, nil)
 	defer func() {
 		assert.NoError(t, state.Close(), "Error closing state")
 	}()
 
+	if tc.stalledSubscriber {
+		sub := state.SubscribeHeadChanges(make(chan HeadChange))
+		defer sub.Unsubscribe()
+	}
+
 	err := state.Start(context.Background())
 	assert.NoError(t, err, "Error starting the state object")
 
@@ -191,6 +216,21 @@ func TestDriver(t *testing.T) {
 				{l1act: advanceL1, l2act: advanceL2, l1head: "g:6", l2head: "F:5", window: []testID{"f:5", "g:6"}},
 			},
 		},
+		{
+			name:              "Stalled head subscriber",
+			l1Chains:          []string{"abcdefgh"},
+			l2Chains:          []string{"ABCDEF"},
+			seqWindow:         2,
+			genesis:           testutils.FakeGenesis('a', 'A', 0),
+			stalledSubscriber: true,
+			steps: []stateTestCaseStep{
+				{l1act: stutterL1, l2act: stutterL2, l1head: "a:0", l2head: "A:0"},
+				{l1act: advanceL1, l2act: stutterL2, l1head: "b:1", l2head: "A:0", window: []testID{"a:0", "b:1"}},
+				{l1act: advanceL1, l2act: advanceL2, l1head: "c:2", l2head: "B:1", window: []testID{"b:1", "c:2"}},
+				{l1act: advanceL1, l2act: advanceL2, l1head: "d:3", l2head: "C:2", window: []testID{"c:2", "d:3"}},
+				{l1act: advanceL1, l2act: advanceL2, l1head: "e:4", l2head: "D:3", window: []testID{"d:3", "e:4"}},
+			},
+		},
 		{
 			name:      "Reorg",
 			l1Chains:  []string{"abcdefg", "abcwxyz"},
@@ -235,3 +275,229 @@ func TestDriver(t *testing.T) {
 	}
 
 }
+
+func TestEmitHeadChange(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	genesis := testutils.FakeGenesis('a', 'A', 0)
+	s := NewState(log, log, rollup.Config{Genesis: genesis}, nil, nil, nil, nil, nil, false, ThrottleConfig{}, InclusionConfig{}, nil)
+	changes := make(chan HeadChange, 10)
+	sub := s.SubscribeHeadChanges(changes)
+	defer sub.Unsubscribe()
+
+	a := eth.L2BlockRef{Hash: testID("A:1").ID().Hash, Number: 1}
+	b := eth.L2BlockRef{Hash: testID("B:2").ID().Hash, Number: 2, ParentHash: a.Hash}
+	c := eth.L2BlockRef{Hash: testID("C:2").ID().Hash, Number: 2, ParentHash: a.Hash}
+
+	s.l2Head = a
+	prev := s.syncStatus()
+	require.Equal(t, genesis.L2, prev.FinalizedL2, "genesis is finalized by default")
+	s.emitHeadChange(prev, false)
+	require.Len(t, changes, 0, "no change, no event")
+
+	s.l2Head = b
+	s.emitHeadChange(prev, false)
+	change := <-changes
+	require.Equal(t, b, change.New.UnsafeL2)
+	require.False(t, change.Reorg, "extending the chain is not a reorg")
+
+	prev = s.syncStatus()
+	s.l2Head = c
+	s.emitHeadChange(prev, false)
+	change = <-changes
+	require.Equal(t, b, change.Old.UnsafeL2)
+	require.Equal(t, c, change.New.UnsafeL2)
+	require.True(t, change.Reorg, "replacing the head is a reorg")
+
+	// changes are dropped if the subscriber does not keep up, and delivered again once it does
+	full := make(chan HeadChange, 1)
+	fullSub := s.SubscribeHeadChanges(full)
+	defer fullSub.Unsubscribe()
+	for _, head := range []eth.L2BlockRef{b, c, a} {
+		prev = s.syncStatus()
+		s.l2Head = head
+		s.emitHeadChange(prev, false)
+	}
+	require.Equal(t, b, (<-full).New.UnsafeL2)
+	require.Len(t, changes, 3, "other subscribers still receive all changes")
+
+	// no changes are sent after unsubscribing
+	sub.Unsubscribe()
+	for len(changes) > 0 {
+		<-changes
+	}
+	prev = s.syncStatus()
+	s.l2Head = b
+	s.emitHeadChange(prev, false)
+	require.Len(t, changes, 0)
+	require.Equal(t, b, (<-full).New.UnsafeL2)
+}
+
+// fakeBuildOutput builds blocks as sequencer, sealing each block as the next block on top of its parent.
+type fakeBuildOutput struct {
+	outputHandlerFn
+	started []*blockBuild
+	sealed  []*blockBuild
+}
+
+func (o *fakeBuildOutput) startBuildingBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef, noTxPool bool, forced []*types.Transaction) (*blockBuild, error) {
+	build := &blockBuild{id: l2.PayloadID{byte(len(o.started) + 1)}, parent: l2Head, l1Origin: l1Origin, forced: forced}
+	o.started = append(o.started, build)
+	return build, nil
+}
+
+func (o *fakeBuildOutput) sealBlock(ctx context.Context, build *blockBuild, l2SafeHead eth.BlockID, l2Finalized eth.BlockID) (eth.L2BlockRef, *l2.ExecutionPayload, error) {
+	o.sealed = append(o.sealed, build)
+	ref := eth.L2BlockRef{
+		Hash:       common.Hash{build.id[0]},
+		Number:     build.parent.Number + 1,
+		ParentHash: build.parent.Hash,
+		Time:       build.parent.Time + 2,
+		L1Origin:   build.l1Origin.ID(),
+	}
+	return ref, &l2.ExecutionPayload{BlockHash: ref.Hash, BlockNumber: l2.Uint64Quantity(ref.Number)}, nil
+}
+
+func TestSequencerBuilding(t *testing.T) {
+	log := testlog.Logger(t, log.LvlError)
+	newState := func() (*state, *fakeBuildOutput, *testutils.FakeChainSource) {
+		src := testutils.NewFakeChainSource([]string{"abcd", "abxy"}, []string{"ABCD", "ABXY"}, 0, log)
+		output := &fakeBuildOutput{}
+		config := rollup.Config{SeqWindowSize: 2, Genesis: testutils.FakeGenesis('a', 'A', 0), BlockTime: 2}
+		s := NewState(log, log, config, src, src, output, nil, nil, true, ThrottleConfig{}, InclusionConfig{}, nil)
+		src.AdvanceL1()
+		s.l1Head = src.AdvanceL1()
+		s.l2Head = src.SetL2Head(2)
+		s.l2SafeHead = s.l2Head
+		return s, output, src
+	}
+
+	t.Run("seal after start", func(t *testing.T) {
+		s, output, _ := newState()
+		parent := s.l2Head
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.NotNil(t, s.building)
+		require.Equal(t, parent, s.building.parent)
+		require.Equal(t, s.l1Head, s.building.l1Origin)
+		require.Empty(t, output.sealed, "not sealed until the seal time")
+
+		require.NoError(t, s.sealL2Block(context.Background()))
+		require.Nil(t, s.building)
+		require.Equal(t, output.started, output.sealed)
+		require.Equal(t, parent.Hash, s.l2Head.ParentHash, "sealed block is the new L2 head")
+	})
+
+	t.Run("keep building during an L1 stall", func(t *testing.T) {
+		s, output, _ := newState()
+		// no new L1 head arrives: the blocks are built on schedule, and extend the epoch of the L1 head
+		for i := 0; i < 4; i++ {
+			require.NoError(t, s.startBuildingL2Block(context.Background()))
+			require.NoError(t, s.sealL2Block(context.Background()))
+			require.Equal(t, s.l1Head.ID(), s.l2Head.L1Origin)
+		}
+		require.Len(t, output.sealed, 4)
+	})
+
+	t.Run("cancel when the L2 head changes", func(t *testing.T) {
+		s, output, _ := newState()
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		s.cancelStaleBuilding()
+		require.NotNil(t, s.building, "the build still extends the L2 head")
+
+		// e.g. a block of another node, received over p2p
+		s.l2Head = eth.L2BlockRef{Hash: common.Hash{0xff}, Number: s.l2Head.Number + 1, ParentHash: s.l2Head.Hash}
+		s.cancelStaleBuilding()
+		require.Nil(t, s.building)
+		require.Empty(t, output.sealed)
+	})
+
+	t.Run("cancel on L1 reorg", func(t *testing.T) {
+		s, output, src := newState()
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, testID("c:2").ID(), s.building.l1Origin.ID())
+
+		src.ReorgL1()
+		require.NoError(t, s.handleNewL1Block(context.Background(), src.AdvanceL1()))
+		require.Nil(t, s.building, "L1 origin of the build is not canonical anymore")
+		require.Equal(t, testID("B:1").ID(), s.l2Head.ID())
+		require.Empty(t, output.sealed)
+	})
+
+	t.Run("cancel on L1 reorg of the build origin", func(t *testing.T) {
+		s, output, src := newState()
+		// the L2 head is not affected by the L1 reorg, only the L1 origin of the block that is being built
+		s.l2Head = src.SetL2Head(1)
+		s.l2SafeHead = s.l2Head
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, testID("c:2").ID(), s.building.l1Origin.ID())
+
+		src.ReorgL1()
+		require.NoError(t, s.handleNewL1Block(context.Background(), src.AdvanceL1()))
+		require.Equal(t, testID("B:1").ID(), s.l2Head.ID(), "L2 head is still canonical")
+		require.Nil(t, s.building, "L1 origin of the build is not canonical anymore")
+		require.Empty(t, output.sealed)
+
+		// the next build is on top of the new L1 chain
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, testID("x:2").ID(), s.building.l1Origin.ID())
+	})
+
+	t.Run("rebuild on a newer L1 origin", func(t *testing.T) {
+		s, output, src := newState()
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, testID("c:2").ID(), s.building.l1Origin.ID())
+
+		require.NoError(t, s.handleNewL1Block(context.Background(), src.AdvanceL1()))
+		require.Nil(t, s.building, "the block can adopt the new L1 head as origin")
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, testID("d:3").ID(), s.building.l1Origin.ID())
+		require.NoError(t, s.sealL2Block(context.Background()))
+		require.Len(t, output.sealed, 1)
+	})
+
+	t.Run("force transactions again after an L2 reorg", func(t *testing.T) {
+		s, _, _ := newState()
+		chainID := big.NewInt(901)
+		s.inclusion = newInclusionQueue(InclusionConfig{MaxTxsPerBlock: 1, MaxGasPerBlock: 21_000}, chainID)
+		priv, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		tx := types.MustSignNewTx(priv, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{ChainID: chainID, Gas: 21_000, To: &common.Address{0x42}})
+		require.NoError(t, s.inclusion.Enqueue(tx))
+
+		parent := s.l2Head
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.NoError(t, s.sealL2Block(context.Background()))
+		require.Equal(t, s.l2Head.ID(), *s.inclusion.Status(tx.Hash()).Block)
+		s.updateInclusion(context.Background())
+		require.Empty(t, s.inclusion.next(), "the block is canonical")
+
+		// e.g. the unsafe head is reset below the block by derivation
+		s.l2Head = parent
+		s.updateInclusion(context.Background())
+		require.Equal(t, &InclusionStatus{State: InclusionQueued}, s.inclusion.Status(tx.Hash()))
+
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.Equal(t, []*types.Transaction{tx}, s.building.forced)
+		require.NoError(t, s.sealL2Block(context.Background()))
+		s.l2SafeHead = s.l2Head
+		s.updateInclusion(context.Background())
+		require.Empty(t, s.inclusion.sealedBlocks(), "the block is safe")
+	})
+
+	t.Run("no stale seal after cancel", func(t *testing.T) {
+		s, output, _ := newState()
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		stale := s.building
+		s.l2Head = eth.L2BlockRef{Hash: common.Hash{0xff}, Number: s.l2Head.Number + 1, ParentHash: s.l2Head.Hash, L1Origin: s.l1Head.ID()}
+		s.cancelStaleBuilding()
+		require.Error(t, s.sealL2Block(context.Background()), "nothing to seal")
+
+		// the next build extends the new L2 head, and only that block is sealed
+		head := s.l2Head
+		require.NoError(t, s.startBuildingL2Block(context.Background()))
+		require.NoError(t, s.sealL2Block(context.Background()))
+		require.Len(t, output.sealed, 1)
+		require.NotEqual(t, stale, output.sealed[0])
+		require.Equal(t, head, output.sealed[0].parent)
+		require.Equal(t, head.Hash, s.l2Head.ParentHash)
+	})
+}
@@ -75,8 +75,19 @@ func (d *outputImpl) processBlock(ctx context.Context, l2Head eth.L2BlockRef, l2
 	return nil
 }
 
-func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef) (eth.L2BlockRef, *l2.ExecutionPayload, error) {
-	d.log.Info("creating new block", "parent", l2Head, "l1Origin", l1Origin)
+// blockBuild is a block that the engine is building for the sequencer, but that is not sealed yet.
+type blockBuild struct {
+	id       l2.PayloadID
+	parent   eth.L2BlockRef
+	l1Origin eth.L1BlockRef
+	// deposits is the number of deposits in the payload attributes, the engine must include all of them
+	deposits int
+	// forced are the transactions that were forced into the payload attributes, after the deposits
+	forced []*types.Transaction
+}
+
+func (d *outputImpl) startBuildingBlock(ctx context.Context, l2Head eth.L2BlockRef, l2SafeHead eth.BlockID, l2Finalized eth.BlockID, l1Origin eth.L1BlockRef, noTxPool bool, forced []*types.Transaction) (*blockBuild, error) {
+	d.log.Info("start building new block", "parent", l2Head, "l1Origin", l1Origin)
 
 	fetchCtx, cancel := context.WithTimeout(ctx, time.Second*20)
 	defer cancel()
@@ -97,7 +108,7 @@ func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef,
 		l1Info, err = d.dl.InfoByHash(fetchCtx, l1Origin.Hash)
 	}
 	if err != nil {
-		return l2Head, nil, fmt.Errorf("failed to fetch L1 block info of %s: %v", l1Origin, err)
+		return nil, fmt.Errorf("failed to fetch L1 block info of %s: %v", l1Origin, err)
 	}
 
 	// Start building the list of transactions to include in the new block.
@@ -106,7 +117,7 @@ func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef,
 	// First transaction in every block is always the L1 info transaction.
 	l1InfoTx, err := derive.L1InfoDepositBytes(seqNumber, l1Info)
 	if err != nil {
-		return l2Head, nil, err
+		return nil, err
 	}
 	txns = append(txns, l1InfoTx)
 
@@ -119,13 +130,26 @@ func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef,
 	}
 	// TODO: Should we halt if len(errs) > 0? Opens up a denial of service attack, but prevents lockup of funds.
 	txns = append(txns, deposits...)
+	depositCount := len(txns)
 
 	// If our next L2 block timestamp is beyond the Sequencer drift threshold, then we must produce
 	// empty blocks (other than the L1 info deposit and any user deposits). We handle this by
 	// setting NoTxPool to true, which will cause the Sequencer to not include any transactions
-	// from the transaction pool.
+	// from the transaction pool. The Sequencer may also be throttled to produce empty blocks.
 	nextL2Time := l2Head.Time + d.Config.BlockTime
-	shouldProduceEmptyBlock := nextL2Time >= l1Origin.Time+d.Config.MaxSequencerDrift
+	shouldProduceEmptyBlock := noTxPool || nextL2Time >= l1Origin.Time+d.Config.MaxSequencerDrift
+
+	// Forced transactions are included right after the deposits, unless the block must be empty.
+	if shouldProduceEmptyBlock {
+		forced = nil
+	}
+	for i, tx := range forced {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode forced transaction %d: %v", i, err)
+		}
+		txns = append(txns, data)
+	}
 
 	// Put together our payload attributes.
 	attrs := &l2.PayloadAttributes{
@@ -144,10 +168,26 @@ func (d *outputImpl) createNewBlock(ctx context.Context, l2Head eth.L2BlockRef,
 		FinalizedBlockHash: l2Finalized.Hash,
 	}
 
-	// Actually execute the block and add it to the head of the chain.
-	payload, err := d.insertHeadBlock(ctx, fc, attrs, false)
+	// Start building the block. The engine fills it with transactions from the mempool until it is sealed.
+	id, err := d.startPayload(ctx, fc, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start building L2 block: %v", err)
+	}
+	return &blockBuild{id: id, parent: l2Head, l1Origin: l1Origin, deposits: depositCount, forced: forced}, nil
+}
+
+// sealBlock retrieves the block that the engine built, and inserts it as the new head of the chain.
+func (d *outputImpl) sealBlock(ctx context.Context, build *blockBuild, l2SafeHead eth.BlockID, l2Finalized eth.BlockID) (eth.L2BlockRef, *l2.ExecutionPayload, error) {
+	d.log.Info("sealing new block", "parent", build.parent, "l1Origin", build.l1Origin, "id", build.id)
+
+	fc := l2.ForkchoiceState{
+		HeadBlockHash:      build.parent.Hash,
+		SafeBlockHash:      l2SafeHead.Hash,
+		FinalizedBlockHash: l2Finalized.Hash,
+	}
+	payload, err := d.confirmPayload(ctx, fc, build.id, build.deposits, false)
 	if err != nil {
-		return l2Head, nil, fmt.Errorf("failed to extend L2 chain: %v", err)
+		return build.parent, nil, fmt.Errorf("failed to extend L2 chain: %v", err)
 	}
 
 	// Generate an L2 block ref from the payload.
@@ -340,18 +380,35 @@ func (d *outputImpl) verifySafeBlock(ctx context.Context, fc l2.ForkchoiceState,
 // If updateSafe is true, the head block is considered to be the safe head as well as the head.
 // It returns the payload, the count of deposits, and an error.
 func (d *outputImpl) insertHeadBlock(ctx context.Context, fc l2.ForkchoiceState, attrs *l2.PayloadAttributes, updateSafe bool) (*l2.ExecutionPayload, error) {
+	id, err := d.startPayload(ctx, fc, attrs)
+	if err != nil {
+		return nil, err
+	}
+	return d.confirmPayload(ctx, fc, id, len(attrs.Transactions), updateSafe)
+}
+
+// startPayload uses the given FC to make the engine start building a block with the given attributes.
+// It returns the ID of the payload, to retrieve the block with later.
+func (d *outputImpl) startPayload(ctx context.Context, fc l2.ForkchoiceState, attrs *l2.PayloadAttributes) (l2.PayloadID, error) {
 	fcRes, err := d.l2.ForkchoiceUpdate(ctx, &fc, attrs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new block via forkchoice: %w", err)
+		return l2.PayloadID{}, fmt.Errorf("failed to create new block via forkchoice: %w", err)
 	}
 	if fcRes.PayloadStatus.Status != l2.ExecutionValid {
-		return nil, fmt.Errorf("engine not ready, forkchoice pre-state is not valid: %s", fcRes.PayloadStatus.Status)
+		return l2.PayloadID{}, fmt.Errorf("engine not ready, forkchoice pre-state is not valid: %s", fcRes.PayloadStatus.Status)
 	}
 	id := fcRes.PayloadID
 	if id == nil {
-		return nil, errors.New("nil id in forkchoice result when expecting a valid ID")
+		return l2.PayloadID{}, errors.New("nil id in forkchoice result when expecting a valid ID")
 	}
-	payload, err := d.l2.GetPayload(ctx, *id)
+	return *id, nil
+}
+
+// confirmPayload retrieves the payload that the engine built, executes it, and sets the FC to the same safe and
+// finalized hashes, but updates the head hash to the new block. The FC must be the one the payload was started with.
+// The payload attributes had the given count of deposits, which the engine must include.
+func (d *outputImpl) confirmPayload(ctx context.Context, fc l2.ForkchoiceState, id l2.PayloadID, deposits int, updateSafe bool) (*l2.ExecutionPayload, error) {
+	payload, err := d.l2.GetPayload(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get execution payload: %w", err)
 	}
@@ -382,7 +439,7 @@ func (d *outputImpl) insertHeadBlock(ctx context.Context, fc l2.ForkchoiceState,
 	// If this is an unsafe block, it has deposits & transactions included from L2.
 	// Record if the execution engine dropped deposits. The verification process would see a mismatch
 	// between attributes and the block, but then execute the correct block.
-	if !updateSafe && lastDeposit+1 != len(attrs.Transactions) {
+	if !updateSafe && lastDeposit+1 != deposits {
 		d.log.Error("Dropped deposits when executing L2 block")
 	}
 
@@ -395,7 +452,7 @@ func (d *outputImpl) insertHeadBlock(ctx context.Context, fc l2.ForkchoiceState,
 		fc.SafeBlockHash = payload.BlockHash
 	}
 	d.log.Debug("Inserted L2 head block", "number", uint64(payload.BlockNumber), "hash", payload.BlockHash, "update_safe", updateSafe)
-	fcRes, err = d.l2.ForkchoiceUpdate(ctx, &fc, nil)
+	fcRes, err := d.l2.ForkchoiceUpdate(ctx, &fc, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make the new L2 block canonical via forkchoice: %w", err)
 	}
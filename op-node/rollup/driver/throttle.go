@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+// ThrottleConfig configures when the sequencer throttles block production, because the batcher falls behind.
+// Unsafe blocks that are not submitted to L1 within the sequencing window are reorged away,
+// together with the transactions they include.
+// Zero values disable the respective throttling.
+type ThrottleConfig struct {
+	// NoTxPoolUnsafeBlocks is the number of unsafe blocks ahead of the safe head,
+	// at which the sequencer stops including transactions from the tx pool.
+	NoTxPoolUnsafeBlocks uint64
+	// StopUnsafeBlocks is the number of unsafe blocks ahead of the safe head,
+	// at which the sequencer stops producing blocks.
+	StopUnsafeBlocks uint64
+	// MaxSafeL1Lag is the number of L1 blocks that the L1 origin of the safe head may fall behind the sequencing window,
+	// before the sequencer stops including transactions from the tx pool.
+	MaxSafeL1Lag uint64
+}
+
+// Check verifies that the given throttle configuration makes sense
+func (cfg *ThrottleConfig) Check() error {
+	if cfg.NoTxPoolUnsafeBlocks != 0 && cfg.StopUnsafeBlocks != 0 && cfg.StopUnsafeBlocks < cfg.NoTxPoolUnsafeBlocks {
+		return fmt.Errorf("sequencer stops producing blocks at %d unsafe blocks, before it stops including tx pool transactions at %d",
+			cfg.StopUnsafeBlocks, cfg.NoTxPoolUnsafeBlocks)
+	}
+	return nil
+}
+
+type throttleLevel uint
+
+const (
+	// throttleNone produces blocks as usual
+	throttleNone throttleLevel = iota
+	// throttleNoTxPool produces blocks with only the deposits, and no transactions from the tx pool
+	throttleNoTxPool
+	// throttleStop does not produce blocks
+	throttleStop
+)
+
+func (l throttleLevel) String() string {
+	switch l {
+	case throttleNone:
+		return "none"
+	case throttleNoTxPool:
+		return "no_tx_pool"
+	case throttleStop:
+		return "stop"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint(l))
+	}
+}
+
+// level determines how much to throttle block production, given the current heads.
+// The safe head normally follows the L1 head by a sequencing window.
+func (cfg *ThrottleConfig) level(l1Head eth.L1BlockRef, l2Head eth.L2BlockRef, l2SafeHead eth.L2BlockRef, seqWindowSize uint64) throttleLevel {
+	var unsafeBlocks uint64
+	if l2Head.Number > l2SafeHead.Number {
+		unsafeBlocks = l2Head.Number - l2SafeHead.Number
+	}
+	if cfg.StopUnsafeBlocks != 0 && unsafeBlocks >= cfg.StopUnsafeBlocks {
+		return throttleStop
+	}
+	if cfg.NoTxPoolUnsafeBlocks != 0 && unsafeBlocks >= cfg.NoTxPoolUnsafeBlocks {
+		return throttleNoTxPool
+	}
+	if cfg.MaxSafeL1Lag != 0 && l1Head.Number > l2SafeHead.L1Origin.Number+seqWindowSize+cfg.MaxSafeL1Lag {
+		return throttleNoTxPool
+	}
+	return throttleNone
+}
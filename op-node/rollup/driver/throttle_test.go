@@ -0,0 +1,41 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-node/eth"
+)
+
+func TestThrottleLevel(t *testing.T) {
+	cfg := ThrottleConfig{NoTxPoolUnsafeBlocks: 10, StopUnsafeBlocks: 20, MaxSafeL1Lag: 5}
+	require.NoError(t, cfg.Check())
+
+	safe := eth.L2BlockRef{Number: 100, L1Origin: eth.BlockID{Number: 50}}
+	l1Head := eth.L1BlockRef{Number: 53}
+	level := func(l2Head uint64) throttleLevel {
+		return cfg.level(l1Head, eth.L2BlockRef{Number: l2Head}, safe, 3)
+	}
+	require.Equal(t, throttleNone, level(100))
+	require.Equal(t, throttleNone, level(109))
+	require.Equal(t, throttleNoTxPool, level(110))
+	require.Equal(t, throttleNoTxPool, level(119))
+	require.Equal(t, throttleStop, level(120))
+	// a reorg may put the unsafe head behind the safe head
+	require.Equal(t, throttleNone, level(90))
+
+	// the safe head falls behind the L1 head by more than the sequencing window and lag
+	l1Head.Number = 58
+	require.Equal(t, throttleNone, level(100))
+	l1Head.Number = 59
+	require.Equal(t, throttleNoTxPool, level(100))
+	require.Equal(t, throttleStop, level(120))
+
+	// disabled
+	cfg = ThrottleConfig{}
+	require.Equal(t, throttleNone, level(1000))
+
+	cfg = ThrottleConfig{NoTxPoolUnsafeBlocks: 10, StopUnsafeBlocks: 5}
+	require.Error(t, cfg.Check())
+}
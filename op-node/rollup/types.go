@@ -19,6 +19,28 @@ type Genesis struct {
 	L2Time uint64 `json:"l2_time"`
 }
 
+// P2PSequencerKey is a sequencer P2P signing key, authorized for blocks within a L2 time range.
+type P2PSequencerKey struct {
+	Address common.Address `json:"address"`
+	// L2 timestamp of the first block the key is authorized for
+	ActivationTime uint64 `json:"activation_time"`
+	// L2 timestamp of the first block the key is no longer authorized for, or 0 if it does not expire
+	ExpiryTime uint64 `json:"expiry_time,omitempty"`
+}
+
+// ActiveAt returns true if the key is authorized for blocks with the given L2 timestamp.
+func (k *P2PSequencerKey) ActiveAt(timestamp uint64) bool {
+	return timestamp >= k.ActivationTime && (k.ExpiryTime == 0 || timestamp < k.ExpiryTime)
+}
+
+// P2PBlocksTopic schedules a version of the P2P blocks topic.
+// Blocks are published on the topic of the latest version that activated at or before the block time.
+type P2PBlocksTopic struct {
+	Version uint64 `json:"version"`
+	// L2 timestamp of the first block published on this version of the topic
+	ActivationTime uint64 `json:"activation_time"`
+}
+
 type Config struct {
 	// Genesis anchor point of the rollup
 	Genesis Genesis `json:"genesis"`
@@ -39,6 +61,11 @@ type Config struct {
 
 	// Address of the key the sequencer uses to sign blocks on the P2P layer
 	P2PSequencerAddress common.Address `json:"p2p_sequencer_address"`
+	// Additional keys the sequencer may sign blocks with on the P2P layer, each within a L2 time range.
+	// Keys can be rotated by scheduling a new key ahead of time, without a coordinated restart of all verifiers.
+	P2PSequencerKeys []P2PSequencerKey `json:"p2p_sequencer_keys,omitempty"`
+	// Upgrades of the P2P blocks topic, ordered by activation time. Version 0 is used from genesis until the first upgrade.
+	P2PBlocksTopics []P2PBlocksTopic `json:"p2p_blocks_topics,omitempty"`
 
 	// Note: below addresses are part of the block-derivation process,
 	// and required to be the same network-wide to stay in consensus.
@@ -73,9 +100,64 @@ func (cfg *Config) Check() error {
 	if cfg.DepositContractAddress == (common.Address{}) {
 		return errors.New("did not provide deposit contract address ")
 	}
+	for i, k := range cfg.P2PSequencerKeys {
+		if k.Address == (common.Address{}) {
+			return fmt.Errorf("p2p sequencer key %d has no address", i)
+		}
+		if k.ExpiryTime != 0 && k.ExpiryTime <= k.ActivationTime {
+			return fmt.Errorf("p2p sequencer key %d (%s) expires at %d, before activation at %d", i, k.Address, k.ExpiryTime, k.ActivationTime)
+		}
+	}
+	prev := P2PBlocksTopic{}
+	for i, v := range cfg.P2PBlocksTopics {
+		if v.Version <= prev.Version {
+			return fmt.Errorf("p2p blocks topic upgrade %d has version %d, expected higher than %d", i, v.Version, prev.Version)
+		}
+		if v.ActivationTime <= prev.ActivationTime {
+			return fmt.Errorf("p2p blocks topic version %d activates at %d, expected after %d", v.Version, v.ActivationTime, prev.ActivationTime)
+		}
+		prev = v
+	}
 	return nil
 }
 
+// P2PBlocksTopicVersion returns the version of the P2P blocks topic that blocks with the given L2 timestamp are published on.
+func (c *Config) P2PBlocksTopicVersion(timestamp uint64) uint64 {
+	version := uint64(0)
+	for _, v := range c.P2PBlocksTopics {
+		if timestamp < v.ActivationTime {
+			break
+		}
+		version = v.Version
+	}
+	return version
+}
+
+// P2PSequencerAddresses returns the addresses that are authorized to sign blocks with the given L2 timestamp.
+// The P2PSequencerAddress, if set, is always authorized.
+func (c *Config) P2PSequencerAddresses(timestamp uint64) []common.Address {
+	var out []common.Address
+	if c.P2PSequencerAddress != (common.Address{}) {
+		out = append(out, c.P2PSequencerAddress)
+	}
+	for _, k := range c.P2PSequencerKeys {
+		if k.ActiveAt(timestamp) {
+			out = append(out, k.Address)
+		}
+	}
+	return out
+}
+
+// IsP2PSequencerAddress returns true if the address is authorized to sign blocks with the given L2 timestamp.
+func (c *Config) IsP2PSequencerAddress(addr common.Address, timestamp uint64) bool {
+	for _, a := range c.P2PSequencerAddresses(timestamp) {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Config) L1Signer() types.Signer {
 	return types.NewLondonSigner(c.L1ChainID)
 }
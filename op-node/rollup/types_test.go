@@ -45,3 +45,62 @@ func TestConfigJSON(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(data, &roundTripped))
 	assert.Equal(t, &roundTripped, config)
 }
+
+func TestP2PSequencerAddresses(t *testing.T) {
+	legacy := common.Address{0x01}
+	old := common.Address{0x02}
+	next := common.Address{0x03}
+	cfg := &Config{
+		P2PSequencerAddress: legacy,
+		P2PSequencerKeys: []P2PSequencerKey{
+			{Address: old, ActivationTime: 0, ExpiryTime: 1000},
+			{Address: next, ActivationTime: 900},
+		},
+	}
+	assert.Equal(t, []common.Address{legacy, old}, cfg.P2PSequencerAddresses(899))
+	assert.Equal(t, []common.Address{legacy, old, next}, cfg.P2PSequencerAddresses(900))
+	assert.Equal(t, []common.Address{legacy, next}, cfg.P2PSequencerAddresses(1000))
+
+	assert.True(t, cfg.IsP2PSequencerAddress(old, 999))
+	assert.False(t, cfg.IsP2PSequencerAddress(old, 1000))
+	assert.False(t, cfg.IsP2PSequencerAddress(next, 899))
+	assert.False(t, cfg.IsP2PSequencerAddress(common.Address{0x04}, 900))
+
+	cfg.P2PSequencerAddress = common.Address{}
+	assert.Equal(t, []common.Address{next}, cfg.P2PSequencerAddresses(1000))
+}
+
+func TestCheckP2PSequencerKeys(t *testing.T) {
+	config := randConfig()
+	config.DepositContractAddress = common.Address{0xaa}
+	config.P2PSequencerKeys = []P2PSequencerKey{{Address: common.Address{0x01}, ActivationTime: 10, ExpiryTime: 20}}
+	assert.NoError(t, config.Check())
+
+	config.P2PSequencerKeys[0].ExpiryTime = 10
+	assert.Error(t, config.Check(), "expiry must be after activation")
+
+	config.P2PSequencerKeys[0] = P2PSequencerKey{ActivationTime: 10}
+	assert.Error(t, config.Check(), "address must be set")
+}
+
+func TestP2PBlocksTopicVersion(t *testing.T) {
+	config := randConfig()
+	config.DepositContractAddress = common.Address{0xaa}
+	assert.Equal(t, uint64(0), config.P2PBlocksTopicVersion(1000))
+
+	config.P2PBlocksTopics = []P2PBlocksTopic{{Version: 1, ActivationTime: 100}, {Version: 3, ActivationTime: 200}}
+	assert.NoError(t, config.Check())
+	assert.Equal(t, uint64(0), config.P2PBlocksTopicVersion(99))
+	assert.Equal(t, uint64(1), config.P2PBlocksTopicVersion(100))
+	assert.Equal(t, uint64(1), config.P2PBlocksTopicVersion(199))
+	assert.Equal(t, uint64(3), config.P2PBlocksTopicVersion(200))
+
+	config.P2PBlocksTopics[1].Version = 1
+	assert.Error(t, config.Check(), "versions must increase")
+
+	config.P2PBlocksTopics[1] = P2PBlocksTopic{Version: 2, ActivationTime: 100}
+	assert.Error(t, config.Check(), "activation times must increase")
+
+	config.P2PBlocksTopics = []P2PBlocksTopic{{Version: 0, ActivationTime: 100}}
+	assert.Error(t, config.Check(), "version 0 is used from genesis")
+}
@@ -1,9 +1,11 @@
 package opnode
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/log"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/ethereum-optimism/optimism/op-node/node"
 	"github.com/ethereum-optimism/optimism/op-node/p2p"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver"
 	"github.com/urfave/cli"
 )
 
@@ -33,16 +36,48 @@ func NewConfig(ctx *cli.Context) (*node.Config, error) {
 		return nil, fmt.Errorf("failed to load p2p config: %v", err)
 	}
 
+	adminJWTSecret, err := loadJWTSecret(ctx.GlobalString(flags.RPCAdminJWTSecret.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin RPC JWT secret: %v", err)
+	}
+
 	cfg := &node.Config{
-		L1NodeAddr:    ctx.GlobalString(flags.L1NodeAddr.Name),
-		L2EngineAddrs: ctx.GlobalStringSlice(flags.L2EngineAddrs.Name),
-		L2NodeAddr:    ctx.GlobalString(flags.L2EthNodeAddr.Name),
-		L1TrustRPC:    ctx.GlobalBool(flags.L1TrustRPC.Name),
-		Rollup:        *rollupConfig,
-		Sequencer:     enableSequencing,
+		L1NodeAddr:       ctx.GlobalString(flags.L1NodeAddr.Name),
+		L2EngineAddrs:    ctx.GlobalStringSlice(flags.L2EngineAddrs.Name),
+		L2NodeAddr:       ctx.GlobalString(flags.L2EthNodeAddr.Name),
+		L2ReferenceAddrs: ctx.GlobalStringSlice(flags.L2ReferenceAddrs.Name),
+		L2ReferenceHalt:  ctx.GlobalBool(flags.L2ReferenceHalt.Name),
+		L1TrustRPC:       ctx.GlobalBool(flags.L1TrustRPC.Name),
+		Rollup:           *rollupConfig,
+		Sequencer:        enableSequencing,
+		SequencerThrottle: driver.ThrottleConfig{
+			NoTxPoolUnsafeBlocks: ctx.GlobalUint64(flags.SequencingThrottleNoTxPoolFlag.Name),
+			StopUnsafeBlocks:     ctx.GlobalUint64(flags.SequencingThrottleStopFlag.Name),
+			MaxSafeL1Lag:         ctx.GlobalUint64(flags.SequencingThrottleL1LagFlag.Name),
+		},
+		SequencerInclusion: driver.InclusionConfig{
+			MaxTxsPerBlock: ctx.GlobalUint64(flags.SequencingInclusionMaxTxsFlag.Name),
+			MaxGasPerBlock: ctx.GlobalUint64(flags.SequencingInclusionMaxGasFlag.Name),
+		},
 		RPC: node.RPCConfig{
-			ListenAddr: ctx.GlobalString(flags.RPCListenAddr.Name),
-			ListenPort: ctx.GlobalInt(flags.RPCListenPort.Name),
+			ListenAddr:   ctx.GlobalString(flags.RPCListenAddr.Name),
+			ListenPort:   ctx.GlobalInt(flags.RPCListenPort.Name),
+			CORS:         splitAndTrim(ctx.GlobalString(flags.RPCCORSDomains.Name)),
+			VHosts:       splitAndTrim(ctx.GlobalString(flags.RPCVHosts.Name)),
+			HTTPModules:  splitAndTrim(ctx.GlobalString(flags.RPCHTTPModules.Name)),
+			WSEnabled:    ctx.GlobalBool(flags.RPCWSEnabled.Name),
+			WSListenPort: ctx.GlobalInt(flags.RPCWSListenPort.Name),
+			WSModules:    splitAndTrim(ctx.GlobalString(flags.RPCWSModules.Name)),
+			IPCPath:      ctx.GlobalString(flags.RPCIPCPath.Name),
+			IPCModules:   splitAndTrim(ctx.GlobalString(flags.RPCIPCModules.Name)),
+
+			AdminJWTSecret:  adminJWTSecret,
+			AdminListenPort: ctx.GlobalInt(flags.RPCAdminListenPort.Name),
+		},
+		Metrics: node.MetricsConfig{
+			Enabled:    ctx.GlobalBool(flags.MetricsEnabledFlag.Name),
+			ListenAddr: ctx.GlobalString(flags.MetricsAddrFlag.Name),
+			ListenPort: ctx.GlobalInt(flags.MetricsPortFlag.Name),
 		},
 		P2P:       p2pConfig,
 		P2PSigner: p2pSignerSetup,
@@ -53,6 +88,32 @@ func NewConfig(ctx *cli.Context) (*node.Config, error) {
 	return cfg, nil
 }
 
+// loadJWTSecret reads the hex encoded JWT secret from the file at the given path. It returns nil if the path is empty.
+func loadJWTSecret(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex encoding in %s: %w", path, err)
+	}
+	return secret, nil
+}
+
+// splitAndTrim splits a comma separated list, and omits empty entries.
+func splitAndTrim(input string) (out []string) {
+	for _, v := range strings.Split(input, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func NewRollupConfig(ctx *cli.Context) (*rollup.Config, error) {
 	rollupConfigPath := ctx.GlobalString(flags.RollupConfig.Name)
 	file, err := os.Open(rollupConfigPath)
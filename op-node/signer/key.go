@@ -0,0 +1,128 @@
+// Package signer loads the private keys that op-node, op-batcher and op-proposer sign with.
+//
+// Keys are preferably loaded from an encrypted go-ethereum keystore file, unlocked with a password file.
+// A raw hex-encoded key file is supported as well. Mnemonics are only intended for development:
+// they leak *all* keys derived from them when they leak.
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+)
+
+// KeyConfig configures where a private key is loaded from. Exactly one key source must be configured.
+type KeyConfig struct {
+	// KeystorePath is the path of an encrypted keystore file, in the go-ethereum keystore format.
+	KeystorePath string
+	// PasswordFile is the path of the file with the password to decrypt the keystore file with.
+	PasswordFile string
+
+	// PrivateKeyFile is the path of a file with an unencrypted hex-encoded private key.
+	PrivateKeyFile string
+
+	// Mnemonic and HDPath derive the key from a HD wallet. For development only.
+	Mnemonic string
+	HDPath   string
+}
+
+// Enabled returns true if any key source is configured.
+func (c *KeyConfig) Enabled() bool {
+	return c.KeystorePath != "" || c.PrivateKeyFile != "" || c.Mnemonic != ""
+}
+
+func (c *KeyConfig) Check() error {
+	sources := 0
+	if c.KeystorePath != "" {
+		sources++
+		if c.PasswordFile == "" {
+			return errors.New("keystore requires a password file")
+		}
+	} else if c.PasswordFile != "" {
+		return errors.New("password file is only used with a keystore")
+	}
+	if c.PrivateKeyFile != "" {
+		sources++
+	}
+	if c.Mnemonic != "" {
+		sources++
+		if c.HDPath == "" {
+			return errors.New("mnemonic requires a HD path")
+		}
+	} else if c.HDPath != "" {
+		return errors.New("HD path is only used with a mnemonic")
+	}
+	if sources == 0 {
+		return errors.New("no key configured, expected a keystore, private key file or mnemonic")
+	}
+	if sources > 1 {
+		return errors.New("expected a single key source, but multiple are configured")
+	}
+	return nil
+}
+
+// LoadKey loads the configured private key.
+func (c *KeyConfig) LoadKey() (*ecdsa.PrivateKey, error) {
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+	switch {
+	case c.KeystorePath != "":
+		return LoadKeystore(c.KeystorePath, c.PasswordFile)
+	case c.PrivateKeyFile != "":
+		return LoadKeyFile(c.PrivateKeyFile)
+	default:
+		return DeriveKey(c.Mnemonic, c.HDPath)
+	}
+}
+
+// LoadKeystore decrypts the key of a go-ethereum keystore file with the password in the given password file.
+// Trailing newlines of the password file are ignored.
+func LoadKeystore(keystorePath string, passwordFile string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file: %w", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// LoadKeyFile reads an unencrypted hex-encoded private key from a file.
+func LoadKeyFile(path string) (*ecdsa.PrivateKey, error) {
+	priv, err := crypto.LoadECDSA(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+	return priv, nil
+}
+
+// DeriveKey derives a private key from a mnemonic at the given HD path.
+func DeriveKey(mnemonic string, hdPath string) (*ecdsa.PrivateKey, error) {
+	wallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mnemonic: %w", err)
+	}
+	priv, err := wallet.PrivateKey(accounts.Account{
+		URL: accounts.URL{
+			Path: hdPath,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key at %q: %w", hdPath, err)
+	}
+	return priv, nil
+}
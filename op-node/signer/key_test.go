@@ -0,0 +1,74 @@
+package signer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(dir, "key.txt")
+	require.NoError(t, crypto.SaveECDSA(keyFile, priv))
+
+	id, err := uuid.NewRandom()
+	require.NoError(t, err)
+	key := &keystore.Key{Id: id, Address: crypto.PubkeyToAddress(priv.PublicKey), PrivateKey: priv}
+	keyJSON, err := keystore.EncryptKey(key, "hunter2", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	keystoreFile := filepath.Join(dir, "keystore.json")
+	require.NoError(t, os.WriteFile(keystoreFile, keyJSON, 0600))
+	passwordFile := filepath.Join(dir, "password.txt")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0600))
+	wrongPasswordFile := filepath.Join(dir, "wrong.txt")
+	require.NoError(t, os.WriteFile(wrongPasswordFile, []byte("hunter3"), 0600))
+
+	t.Run("keystore", func(t *testing.T) {
+		got, err := (&KeyConfig{KeystorePath: keystoreFile, PasswordFile: passwordFile}).LoadKey()
+		require.NoError(t, err)
+		require.Equal(t, priv.D, got.D)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := (&KeyConfig{KeystorePath: keystoreFile, PasswordFile: wrongPasswordFile}).LoadKey()
+		require.Error(t, err)
+	})
+	t.Run("key file", func(t *testing.T) {
+		got, err := (&KeyConfig{PrivateKeyFile: keyFile}).LoadKey()
+		require.NoError(t, err)
+		require.Equal(t, priv.D, got.D)
+	})
+	t.Run("mnemonic", func(t *testing.T) {
+		cfg := &KeyConfig{
+			Mnemonic: "test test test test test test test test test test test junk",
+			HDPath:   "m/44'/60'/0'/0/0",
+		}
+		got, err := cfg.LoadKey()
+		require.NoError(t, err)
+		require.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", crypto.PubkeyToAddress(got.PublicKey).Hex())
+	})
+}
+
+func TestKeyConfigCheck(t *testing.T) {
+	cases := map[string]KeyConfig{
+		"none":                 {},
+		"keystore no password": {KeystorePath: "a"},
+		"password no keystore": {PasswordFile: "a", PrivateKeyFile: "b"},
+		"mnemonic no path":     {Mnemonic: "a"},
+		"path no mnemonic":     {HDPath: "a", PrivateKeyFile: "b"},
+		"multiple":             {KeystorePath: "a", PasswordFile: "b", PrivateKeyFile: "c"},
+	}
+	for name, cfg := range cases {
+		cfg := cfg
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, cfg.Check())
+		})
+	}
+}
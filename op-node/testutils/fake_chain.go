@@ -167,6 +167,20 @@ func (m *FakeChainSource) L2BlockRefByHash(ctx context.Context, l2Hash common.Ha
 	return eth.L2BlockRef{}, ethereum.NotFound
 }
 
+func (m *FakeChainSource) PayloadByNumber(ctx context.Context, l2Num *big.Int) (*l2.ExecutionPayload, error) {
+	m.log.Trace("PayloadByNumber", "l2Num", l2Num, "l2Head", m.l2head, "reorg", m.l2reorg)
+	ref, err := m.L2BlockRefByNumber(ctx, l2Num)
+	if err != nil {
+		return nil, err
+	}
+	return &l2.ExecutionPayload{
+		ParentHash:  ref.ParentHash,
+		BlockNumber: l2.Uint64Quantity(ref.Number),
+		Timestamp:   l2.Uint64Quantity(ref.Time),
+		BlockHash:   ref.Hash,
+	}, nil
+}
+
 func (m *FakeChainSource) ForkchoiceUpdate(ctx context.Context, state *l2.ForkchoiceState, attr *l2.PayloadAttributes) (*l2.ForkchoiceUpdatedResult, error) {
 	m.log.Trace("ForkchoiceUpdate", "newHead", state.HeadBlockHash, "l2Head", m.l2head, "reorg", m.l2reorg)
 	m.l2reorg++
@@ -40,17 +40,28 @@ type Config struct {
 	// transaction.
 	ResubmissionTimeout time.Duration
 
-	// Mnemonic is the HD seed used to derive the wallet private keys for both
-	// the sequence and proposer. Must be used in conjunction with
-	// SequencerHDPath and ProposerHDPath.
+	/* Optional Params */
+
+	// KeystorePath is the path of the encrypted keystore with the private key
+	// to sign transactions with. Must be used in conjunction with
+	// PasswordFile.
+	KeystorePath string
+
+	// PasswordFile is the path of the password to decrypt the keystore with.
+	PasswordFile string
+
+	// PrivateKeyFile is the path of the unencrypted hex-encoded private key to
+	// sign transactions with.
+	PrivateKeyFile string
+
+	// Mnemonic is the HD seed used to derive the wallet private key, for
+	// development only. Must be used in conjunction with L2OutputHDPath.
 	Mnemonic string
 
 	// L2OutputHDPath is the derivation path used to obtain the private key for
 	// the l2output transactions.
 	L2OutputHDPath string
 
-	/* Optional Params */
-
 	// LogLevel is the lowest log level that will be output.
 	LogLevel string
 
@@ -71,10 +82,13 @@ func NewConfig(ctx *cli.Context) Config {
 		NumConfirmations:          ctx.GlobalUint64(flags.NumConfirmationsFlag.Name),
 		SafeAbortNonceTooLowCount: ctx.GlobalUint64(flags.SafeAbortNonceTooLowCountFlag.Name),
 		ResubmissionTimeout:       ctx.GlobalDuration(flags.ResubmissionTimeoutFlag.Name),
-		Mnemonic:                  ctx.GlobalString(flags.MnemonicFlag.Name),
-		L2OutputHDPath:            ctx.GlobalString(flags.L2OutputHDPathFlag.Name),
 		/* Optional Flags */
-		LogLevel:    ctx.GlobalString(flags.LogLevelFlag.Name),
-		LogTerminal: ctx.GlobalBool(flags.LogTerminalFlag.Name),
+		KeystorePath:   ctx.GlobalString(flags.KeystoreFlag.Name),
+		PasswordFile:   ctx.GlobalString(flags.PasswordFileFlag.Name),
+		PrivateKeyFile: ctx.GlobalString(flags.PrivateKeyFileFlag.Name),
+		Mnemonic:       ctx.GlobalString(flags.MnemonicFlag.Name),
+		L2OutputHDPath: ctx.GlobalString(flags.L2OutputHDPathFlag.Name),
+		LogLevel:       ctx.GlobalString(flags.LogLevelFlag.Name),
+		LogTerminal:    ctx.GlobalBool(flags.LogTerminalFlag.Name),
 	}
 }
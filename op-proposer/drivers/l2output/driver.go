@@ -20,7 +20,7 @@ import (
 )
 
 var bigOne = big.NewInt(1)
-var supportedL2OutputVersion = l2.Bytes32{}
+var supportedL2OutputVersion = l2.OutputV0
 
 type Config struct {
 	Log          log.Logger
@@ -262,15 +262,19 @@ func (d *Driver) SendTransaction(
 }
 
 func (d *Driver) outputRootAtBlock(ctx context.Context, blockNum *big.Int) (l2.Bytes32, error) {
-	output, err := d.cfg.RollupClient.OutputAtBlock(ctx, blockNum)
+	output, err := d.cfg.RollupClient.OutputWithProof(ctx, blockNum)
 	if err != nil {
 		return l2.Bytes32{}, err
 	}
-	if len(output) != 2 {
-		return l2.Bytes32{}, fmt.Errorf("invalid outputAtBlock response")
+	if output == nil || output.BlockRef.Number != blockNum.Uint64() {
+		return l2.Bytes32{}, fmt.Errorf("invalid outputWithProof response")
 	}
-	if version := output[0]; version != supportedL2OutputVersion {
+	if output.Version != supportedL2OutputVersion {
 		return l2.Bytes32{}, fmt.Errorf("unsupported l2 output version")
 	}
-	return output[1], nil
+	// Don't trust the rollup node, recompute the output root and check the block against the L2 node.
+	if err := l2.VerifyOutput(ctx, d.cfg.L2Client, output); err != nil {
+		return l2.Bytes32{}, fmt.Errorf("invalid output root at block %d: %w", blockNum, err)
+	}
+	return output.OutputRoot, nil
 }
@@ -66,23 +66,39 @@ var (
 		Required: true,
 		EnvVar:   prefixEnvVar("RESUBMISSION_TIMEOUT"),
 	}
+
+	/* Optional Flags */
+
+	KeystoreFlag = cli.StringFlag{
+		Name: "keystore",
+		Usage: "File path of the encrypted keystore with the l2output " +
+			"private key. The password-file flag must also be set.",
+		EnvVar: prefixEnvVar("KEYSTORE"),
+	}
+	PasswordFileFlag = cli.StringFlag{
+		Name:   "password-file",
+		Usage:  "File path of the password to decrypt the keystore with",
+		EnvVar: prefixEnvVar("PASSWORD_FILE"),
+	}
+	PrivateKeyFileFlag = cli.StringFlag{
+		Name: "private-key-file",
+		Usage: "File path of the unencrypted hex-encoded l2output private " +
+			"key. Prefer an encrypted keystore.",
+		EnvVar: prefixEnvVar("PRIVATE_KEY_FILE"),
+	}
 	MnemonicFlag = cli.StringFlag{
 		Name: "mnemonic",
 		Usage: "The mnemonic used to derive the wallets for either the " +
-			"sequencer or the l2output",
-		Required: true,
-		EnvVar:   prefixEnvVar("MNEMONIC"),
+			"sequencer or the l2output. For development only, prefer an " +
+			"encrypted keystore.",
+		EnvVar: prefixEnvVar("MNEMONIC"),
 	}
 	L2OutputHDPathFlag = cli.StringFlag{
 		Name: "l2-output-hd-path",
 		Usage: "The HD path used to derive the l2output wallet from the " +
 			"mnemonic. The mnemonic flag must also be set.",
-		Required: true,
-		EnvVar:   prefixEnvVar("L2_OUTPUT_HD_PATH"),
+		EnvVar: prefixEnvVar("L2_OUTPUT_HD_PATH"),
 	}
-
-	/* Optional Flags */
-
 	LogLevelFlag = cli.StringFlag{
 		Name:   "log-level",
 		Usage:  "The lowest log level that will be output",
@@ -106,11 +122,14 @@ var requiredFlags = []cli.Flag{
 	NumConfirmationsFlag,
 	SafeAbortNonceTooLowCountFlag,
 	ResubmissionTimeoutFlag,
-	MnemonicFlag,
-	L2OutputHDPathFlag,
 }
 
 var optionalFlags = []cli.Flag{
+	KeystoreFlag,
+	PasswordFileFlag,
+	PrivateKeyFileFlag,
+	MnemonicFlag,
+	L2OutputHDPathFlag,
 	LogLevelFlag,
 	LogTerminalFlag,
 }
@@ -8,15 +8,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/signer"
 	"github.com/ethereum-optimism/optimism/op-proposer/drivers/l2output"
 	"github.com/ethereum-optimism/optimism/op-proposer/rollupclient"
 	"github.com/ethereum-optimism/optimism/op-proposer/txmgr"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
-	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	"github.com/urfave/cli"
 )
 
@@ -99,16 +98,14 @@ func NewL2OutputSubmitter(
 	ctx := context.Background()
 
 	// Parse l2output wallet private key and L2OO contract address.
-	wallet, err := hdwallet.NewFromMnemonic(cfg.Mnemonic)
-	if err != nil {
-		return nil, err
+	keyCfg := &signer.KeyConfig{
+		KeystorePath:   cfg.KeystorePath,
+		PasswordFile:   cfg.PasswordFile,
+		PrivateKeyFile: cfg.PrivateKeyFile,
+		Mnemonic:       cfg.Mnemonic,
+		HDPath:         cfg.L2OutputHDPath,
 	}
-
-	l2OutputPrivKey, err := wallet.PrivateKey(accounts.Account{
-		URL: accounts.URL{
-			Path: cfg.L2OutputHDPath,
-		},
-	})
+	l2OutputPrivKey, err := keyCfg.LoadKey()
 	if err != nil {
 		return nil, err
 	}
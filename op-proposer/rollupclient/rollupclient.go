@@ -28,8 +28,14 @@ func (r *RollupClient) GetBatchBundle(
 	return batchResponse, err
 }
 
-func (r *RollupClient) OutputAtBlock(ctx context.Context, blockNum *big.Int) ([]l2.Bytes32, error) {
-	var output []l2.Bytes32
+func (r *RollupClient) OutputAtBlock(ctx context.Context, blockNum *big.Int) (*node.OutputResponse, error) {
+	var output *node.OutputResponse
 	err := r.rpc.CallContext(ctx, &output, "optimism_outputAtBlock", hexutil.EncodeBig(blockNum))
 	return output, err
 }
+
+func (r *RollupClient) OutputWithProof(ctx context.Context, blockNum *big.Int) (*l2.OutputWithProof, error) {
+	var output *l2.OutputWithProof
+	err := r.rpc.CallContext(ctx, &output, "optimism_outputWithProof", hexutil.EncodeBig(blockNum))
+	return output, err
+}